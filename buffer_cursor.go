@@ -3,6 +3,8 @@ package parco
 type BufferCursor struct {
 	cursor int
 	data   []byte
+	// zeroCopy lets values parsed from data alias it.
+	zeroCopy bool
 }
 
 func (b *BufferCursor) Read(box []byte) (int, error) {
@@ -15,9 +17,54 @@ func (b *BufferCursor) Read(box []byte) (int, error) {
 	return len(box), nil
 }
 
+func (b *BufferCursor) ReadByte() (byte, error) {
+	if b.cursor >= len(b.data) {
+		return 0, ErrCannotRead
+	}
+	c := b.data[b.cursor]
+	b.cursor++
+	return c, nil
+}
+
+// Next returns the next n bytes of the buffer, without copying them, and
+// moves past them.
+func (b *BufferCursor) Next(n int) ([]byte, error) {
+	to := b.cursor + n
+	if n < 0 || to > len(b.data) {
+		return nil, ErrCannotRead
+	}
+	data := b.data[b.cursor:to:to]
+	b.cursor = to
+	return data, nil
+}
+
+// Offset returns the position of the cursor within the buffer.
+func (b *BufferCursor) Offset() int {
+	return b.cursor
+}
+
+// ZeroCopy reports whether values parsed from the buffer alias it.
+func (b *BufferCursor) ZeroCopy() bool {
+	return b.zeroCopy
+}
+
 func NewBufferCursor(data []byte, cursor int) BufferCursor {
 	return BufferCursor{
 		cursor: cursor,
 		data:   data,
 	}
 }
+
+// NewZeroCopyBufferCursor returns a cursor over data in zero-copy mode:
+// strings and blobs parsed from it alias data instead of copying it, so
+// that parsing large read-only buffers, e.g. memory-mapped files, barely
+// allocates. Such values are valid as long as data is, and data must not
+// be modified while they are in use: strings would change under their
+// holders. Blobs are capped at their length, so appending to them copies.
+func NewZeroCopyBufferCursor(data []byte, cursor int) BufferCursor {
+	return BufferCursor{
+		cursor:   cursor,
+		data:     data,
+		zeroCopy: true,
+	}
+}
@@ -0,0 +1,214 @@
+package parco
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash/adler32"
+	"hash/crc32"
+	"io"
+	"sync"
+)
+
+type (
+	// Checksum computes the 32-bit checksums guarding encoded bytes against
+	// corruption. They are written little endian after the bytes they cover.
+	// Schemas record checksums by name.
+	Checksum interface {
+		Name() string
+		Sum(data []byte) uint32
+	}
+
+	checksumFunc struct {
+		name string
+		sum  func(data []byte) uint32
+	}
+)
+
+const checksumByteLength = 4
+
+var castagnoli = crc32.MakeTable(crc32.Castagnoli)
+
+// NewChecksum names sum, a custom checksum. Schema-driven tools, such as
+// Dynamic and ToJSON, only know the checksums of this package.
+func NewChecksum(name string, sum func(data []byte) uint32) Checksum {
+	return checksumFunc{name: name, sum: sum}
+}
+
+func (c checksumFunc) Name() string {
+	return c.name
+}
+
+func (c checksumFunc) Sum(data []byte) uint32 {
+	return c.sum(data)
+}
+
+// CRC32 is the IEEE CRC-32 checksum, as used by zip and Ethernet.
+func CRC32() Checksum {
+	return NewChecksum("crc32", crc32.ChecksumIEEE)
+}
+
+// CRC32C is the Castagnoli CRC-32 checksum, hardware accelerated on most
+// CPUs.
+func CRC32C() Checksum {
+	return NewChecksum("crc32c", func(data []byte) uint32 {
+		return crc32.Checksum(data, castagnoli)
+	})
+}
+
+// Adler32 is the Adler-32 checksum of zlib: faster than CRC-32 in software,
+// but weaker on short messages.
+func Adler32() Checksum {
+	return NewChecksum("adler32", adler32.Checksum)
+}
+
+// checksumNamed returns the checksum of this package named name.
+func checksumNamed(name string) (Checksum, error) {
+	for _, sum := range []Checksum{CRC32(), CRC32C(), Adler32()} {
+		if sum.Name() == name {
+			return sum, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: unknown checksum %q", ErrInvalidSchema, name)
+}
+
+// compileChecked compiles the message in place, then appends the checksum
+// of its bytes.
+func (c Compiler[T]) compileChecked(value T, w io.Writer) error {
+	cw, ok := w.(*compileWriter)
+	if !ok {
+		cw = getCompileWriter(w)
+		defer putCompileWriter(cw)
+		if err := c.compileChecked(value, cw); err != nil {
+			return err
+		}
+		return cw.flush()
+	}
+
+	start := cw.pin()
+	defer cw.unpin()
+	if err := c.compileBody(value, cw); err != nil {
+		return err
+	}
+	cw.buf = binary.LittleEndian.AppendUint32(cw.buf, c.checksum.Sum(cw.buf[start:]))
+	return nil
+}
+
+// parseChecked parses the message and verifies it against the checksum
+// following it. Extensible messages are verified before their fields are
+// parsed, so that corrupted ones fail with ErrChecksumMismatch. Other
+// messages are verified once parsed, their length being unknown until then:
+// corrupted or truncated ones may fail to parse first.
+func (p *Parser[T]) parseChecked(r *messageReader) (model T, err error) {
+	span := startSpan(r)
+	defer span.release()
+
+	if p.envelope == nil {
+		model, err = p.parseBody(r)
+		data := span.stop(r)
+		if err == nil {
+			err = p.verify(r, data)
+		}
+		return
+	}
+
+	body, offset, box, err := p.readEnvelope(r)
+	if box != nil {
+		defer SinglePool.Put(box)
+	}
+	data := span.stop(r)
+	if err == nil {
+		err = p.verify(r, data)
+	}
+	if err != nil {
+		return
+	}
+	return p.parseEnvelopeBody(r, &body, offset)
+}
+
+// verify reads the checksum following data, the bytes of the message.
+func (p *Parser[T]) verify(r *messageReader, data []byte) error {
+	var sum [checksumByteLength]byte
+	if err := readFull(r, sum[:]); err != nil {
+		return err
+	}
+	if p.checksum.Sum(data) != binary.LittleEndian.Uint32(sum[:]) {
+		return ErrChecksumMismatch
+	}
+	return nil
+}
+
+// messageSpan holds the bytes a messageReader reads between startSpan and
+// stop. Those of a BufferCursor are taken in place, others are recorded as
+// they are read.
+type messageSpan struct {
+	cursor *BufferCursor
+	start  int
+	rec    *recordingReader
+}
+
+func startSpan(r *messageReader) messageSpan {
+	if cursor, ok := r.r.(*BufferCursor); ok {
+		return messageSpan{cursor: cursor, start: cursor.cursor}
+	}
+	rec := getRecordingReader(r.r)
+	r.r = rec
+	return messageSpan{rec: rec}
+}
+
+// stop returns the bytes read since startSpan. They are valid until
+// release.
+func (s messageSpan) stop(r *messageReader) []byte {
+	if s.rec == nil {
+		return s.cursor.data[s.start:s.cursor.cursor]
+	}
+	r.r = s.rec.r
+	return s.rec.buf
+}
+
+func (s messageSpan) release() {
+	if s.rec != nil {
+		putRecordingReader(s.rec)
+	}
+}
+
+// recordingReader keeps a copy of the bytes read through it.
+type recordingReader struct {
+	r   io.Reader
+	buf []byte
+}
+
+func (rec *recordingReader) Read(p []byte) (int, error) {
+	n, err := rec.r.Read(p)
+	rec.buf = append(rec.buf, p[:n]...)
+	return n, err
+}
+
+func (rec *recordingReader) ReadByte() (byte, error) {
+	var b [1]byte
+	if br, ok := rec.r.(io.ByteReader); ok {
+		c, err := br.ReadByte()
+		if err != nil {
+			return 0, err
+		}
+		b[0] = c
+	} else if err := readFull(rec.r, b[:]); err != nil {
+		return 0, err
+	}
+	rec.buf = append(rec.buf, b[0])
+	return b[0], nil
+}
+
+var recordingReaderPool = sync.Pool{New: func() any { return &recordingReader{} }}
+
+func getRecordingReader(r io.Reader) *recordingReader {
+	//nolint:errcheck // Type assertion is safe - we control pool contents
+	rec := recordingReaderPool.Get().(*recordingReader)
+	rec.r = r
+	return rec
+}
+
+func putRecordingReader(rec *recordingReader) {
+	rec.r = nil
+	rec.buf = rec.buf[:0]
+	recordingReaderPool.Put(rec)
+}
@@ -0,0 +1,192 @@
+package parco
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChecksum_RoundTrip(t *testing.T) {
+	for name, sum := range map[string]Checksum{"crc32": CRC32(), "crc32c": CRC32C(), "adler32": Adler32()} {
+		t.Run(name, func(t *testing.T) {
+			builder := parseItemBuilder().Checksum(sum)
+			item := parseItem{"ab", 7}
+
+			buf := bytes.NewBuffer(nil)
+			require.NoError(t, builder.Compile(item, buf))
+			require.NoError(t, builder.Compile(parseItem{"cd", 8}, buf))
+
+			// SKU header and bytes, price, checksum.
+			assert.Equal(t, []byte{2, 'a', 'b', 7, 0, 0, 0}, buf.Bytes()[:7])
+			assert.Equal(t, 22, buf.Len())
+
+			size, err := builder.Size(item)
+			require.NoError(t, err)
+			assert.Equal(t, 11, size)
+
+			data, err := builder.Append(nil, item)
+			require.NoError(t, err)
+			assert.Equal(t, buf.Bytes()[:11], data)
+
+			parsed, err := builder.ParseBytes(data)
+			require.NoError(t, err)
+			assert.Equal(t, item, parsed)
+
+			// Streams are checked as they are read.
+			r := bytes.NewReader(buf.Bytes())
+			parsed, err = builder.Parse(r)
+			require.NoError(t, err)
+			assert.Equal(t, item, parsed)
+			parsed, err = builder.Parse(r)
+			require.NoError(t, err)
+			assert.Equal(t, parseItem{"cd", 8}, parsed)
+		})
+	}
+}
+
+func TestChecksum_Envelopes(t *testing.T) {
+	device := deviceV2{ID: 1, Name: "a", Firmware: 9, Tags: []string{"x"}}
+	devices := deviceV2Builder(ObjectFactory[deviceV2]()).Checksum(CRC32C())
+
+	buf := bytes.NewBuffer(nil)
+	require.NoError(t, devices.Compile(device, buf))
+	size, err := devices.Size(device)
+	require.NoError(t, err)
+	assert.Equal(t, buf.Len(), size)
+
+	parsedDevice, err := devices.Parse(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	assert.Equal(t, device, parsedDevice)
+
+	setting := settings{Name: "db", Hosts: []string{"a", "b"}, Seq: -9}
+	tagged := settingsBuilder().Checksum(CRC32C())
+
+	buf.Reset()
+	require.NoError(t, tagged.Compile(setting, buf))
+	size, err = tagged.Size(setting)
+	require.NoError(t, err)
+	assert.Equal(t, buf.Len(), size)
+
+	parsedSetting, err := tagged.Parse(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	assert.Equal(t, setting, parsedSetting)
+}
+
+func TestChecksum_Mismatch(t *testing.T) {
+	builder := parseItemBuilder().Checksum(CRC32C())
+	data, err := builder.Append(nil, parseItem{"ab", 7})
+	require.NoError(t, err)
+
+	data[3] = 9
+
+	_, err = builder.ParseBytes(data)
+	assert.ErrorIs(t, err, ErrChecksumMismatch)
+	_, err = builder.Parse(bytes.NewReader(data))
+	assert.ErrorIs(t, err, ErrChecksumMismatch)
+
+	// A missing checksum fails like any other missing bytes.
+	_, err = builder.ParseBytes(data[:len(data)-1])
+	assert.ErrorIs(t, err, ErrCannotRead)
+
+	// Peers not checking ignore the checksum.
+	data[3] = 7
+	parsed, err := parseItemBuilder().ParseBytes(data)
+	require.NoError(t, err)
+	assert.Equal(t, parseItem{"ab", 7}, parsed)
+}
+
+func TestChecksum_Schema(t *testing.T) {
+	builder := deviceV2Builder(ObjectFactory[deviceV2]()).Checksum(CRC32C())
+	assert.Equal(t, "crc32c", builder.Schema().Checksum)
+	assert.Equal(t, "struct<varuint> deviceV2 checksum crc32c", builder.Schema().TypeString())
+
+	data, err := builder.Append(nil, deviceV2{ID: 1, Name: "a", Firmware: 9, Tags: []string{"x"}})
+	require.NoError(t, err)
+
+	// Schema-driven tools follow the checksum too.
+	model, err := Dynamic(builder.Schema())
+	require.NoError(t, err)
+	value, err := model.ParseBytes(data)
+	require.NoError(t, err)
+	out := bytes.NewBuffer(nil)
+	require.NoError(t, model.Compile(value, out))
+	assert.Equal(t, data, out.Bytes())
+
+	doc, err := ToJSON(builder, data)
+	require.NoError(t, err)
+	back, err := FromJSON(builder, doc)
+	require.NoError(t, err)
+	assert.Equal(t, data, back)
+
+	lines, err := Explain(builder, data)
+	require.NoError(t, err)
+	last := lines[len(lines)-1]
+	assert.Equal(t, "checksum crc32c", last.Header)
+	assert.Equal(t, data[len(data)-4:], last.Bytes)
+
+	data[len(data)-1]++
+	_, err = Explain(builder, data)
+	assert.ErrorIs(t, err, ErrChecksumMismatch)
+	_, err = ToJSON(builder, data)
+	assert.ErrorIs(t, err, ErrChecksumMismatch)
+}
+
+func TestChecksum_Truncated(t *testing.T) {
+	// Extensible messages are verified before their fields are parsed, so
+	// that their failures never point at a field.
+	devices := deviceV2Builder(ObjectFactory[deviceV2]()).Checksum(CRC32C())
+	data, err := devices.Append(nil, deviceV2{ID: 1, Name: "abc", Firmware: 9, Tags: []string{"x"}})
+	require.NoError(t, err)
+
+	for i := range data {
+		for name, parse := range map[string]func([]byte) (deviceV2, error){
+			"bytes":  devices.ParseBytes,
+			"stream": func(data []byte) (deviceV2, error) { return devices.Parse(bytes.NewReader(data)) },
+		} {
+			_, err := parse(data[:i])
+			var pe *ParseError
+			require.ErrorAs(t, err, &pe, "%s: %d bytes", name, i)
+			assert.Equal(t, "deviceV2", pe.Path, "%s: %d bytes", name, i)
+			assert.ErrorIs(t, err, ErrCannotRead, "%s: %d bytes", name, i)
+		}
+	}
+
+	// The length of Name, past the end of the body.
+	corrupted := bytes.Clone(data)
+	corrupted[3] = 200
+	_, err = devices.ParseBytes(corrupted)
+	assert.ErrorIs(t, err, ErrChecksumMismatch)
+
+	// Other messages are only verified once parsed: truncated ones fail to
+	// parse first.
+	items := parseItemBuilder().Checksum(CRC32C())
+	data, err = items.Append(nil, parseItem{"ab", 7})
+	require.NoError(t, err)
+
+	_, err = items.ParseBytes(data[:4])
+	var pe *ParseError
+	require.ErrorAs(t, err, &pe)
+	assert.Equal(t, "parseItem.Price", pe.Path)
+	assert.ErrorIs(t, err, ErrCannotRead)
+}
+
+func TestChecksum_DynamicBuilder(t *testing.T) {
+	rt := DynamicBuilder("parseItem").
+		Checksum(CRC32C()).
+		SmallVarchar("SKU").
+		UInt32("Price", binary.LittleEndian).
+		MustBuild()
+
+	data, err := parseItemBuilder().Checksum(CRC32C()).Append(nil, parseItem{"ab", 7})
+	require.NoError(t, err)
+	record, err := rt.ParseBytes(data)
+	require.NoError(t, err)
+	assert.Equal(t, Record{{"SKU", "ab"}, {"Price", uint32(7)}}, record)
+
+	// Custom checksums are not known to schemas.
+	_, err = DynamicBuilder("a").Checksum(NewChecksum("xor", func([]byte) uint32 { return 0 })).Bool("x").Build()
+	assert.ErrorIs(t, err, ErrInvalidSchema)
+}
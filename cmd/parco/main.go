@@ -0,0 +1,257 @@
+// Command parco decodes, encodes and inspects parco payloads from a schema
+// file, without the Go types of the models.
+//
+//	parco decode   -schema order.json [-model name] [-in raw|hex|base64] [-pretty] [file]
+//	parco encode   -schema order.json [-model name] [-out raw|hex|base64] [file]
+//	parco dump     -schema order.json [-model name] [-in raw|hex|base64] [file]
+//	parco validate -schema order.json [-model name] [-in raw|hex|base64] [file]
+//
+// decode prints a binary message as JSON, encode compiles a JSON document to
+// a binary message, dump prints an annotated hex dump and validate checks
+// that a message parses and takes all of the input. Input is read from file,
+// or from stdin when not given.
+//
+// A schema file holds the JSON form of a parco.Schema, as written by
+// json.Marshal(builder.Schema()). Multi-model streams, as written by a
+// ModelMultiBuilder, are described by their type id header, optional frame
+// header and models keyed by type id:
+//
+//	{
+//	  "header": {"kind": "fixed", "type": "uint8"},
+//	  "frame": {"kind": "varint", "type": "uint"},
+//	  "models": {"1": {...}, "2": {...}}
+//	}
+//
+// Schema files ending in .parco are written in the parco schema language,
+// see parco.ParseSchemaFile. -model picks one of the models they declare;
+// without it, the stream they declare is used, or their only model.
+//
+// Streams are decoded to one JSON document per line, {"type":1,"value":...},
+// and encoded from a sequence of such documents.
+package main
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"unicode"
+
+	"github.com/sonirico/parco"
+)
+
+const usage = `Usage: parco <command> -schema file [flags] [file]
+
+Commands:
+  decode    print a binary message as JSON
+  encode    compile a JSON document to a binary message
+  dump      print an annotated hex dump of a binary message
+  validate  check that a binary message parses
+`
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+// run executes the command line args and returns the exit code.
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		fmt.Fprint(stderr, usage)
+		return 2
+	}
+
+	cmd := args[0]
+	flags := flag.NewFlagSet("parco "+cmd, flag.ContinueOnError)
+	flags.SetOutput(stderr)
+
+	var (
+		schemaPath = flags.String("schema", "", "schema file, JSON or .parco; required")
+		model      = flags.String("model", "", "model of a .parco schema file")
+		in         = flags.String("in", "raw", "binary input format: raw, hex or base64")
+		out        = flags.String("out", "raw", "binary output format: raw, hex or base64")
+		pretty     = flags.Bool("pretty", false, "indent JSON output")
+	)
+
+	switch cmd {
+	case "decode", "encode", "dump", "validate":
+	case "-h", "-help", "--help", "help":
+		fmt.Fprint(stdout, usage)
+		return 0
+	default:
+		fmt.Fprintf(stderr, "parco: unknown command %q\n\n%s", cmd, usage)
+		return 2
+	}
+
+	if err := flags.Parse(args[1:]); err != nil {
+		return 2
+	}
+	if *schemaPath == "" || flags.NArg() > 1 {
+		fmt.Fprint(stderr, usage)
+		flags.PrintDefaults()
+		return 2
+	}
+
+	err := execute(cmd, *schemaPath, flags.Arg(0), stdin, stdout, options{model: *model, in: *in, out: *out, pretty: *pretty})
+	if err != nil {
+		fmt.Fprintf(stderr, "parco: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+type options struct {
+	model   string
+	in, out string
+	pretty  bool
+}
+
+func execute(cmd, schemaPath, inputPath string, stdin io.Reader, stdout io.Writer, opts options) error {
+	spec, err := loadSpec(schemaPath, opts.model)
+	if err != nil {
+		return err
+	}
+
+	input, err := readInput(inputPath, stdin)
+	if err != nil {
+		return err
+	}
+
+	if cmd == "encode" {
+		return encode(spec, input, stdout, opts)
+	}
+
+	data, err := decodeBinary(input, opts.in)
+	if err != nil {
+		return err
+	}
+
+	switch cmd {
+	case "decode":
+		return decode(spec, data, stdout, opts)
+	case "dump":
+		if spec.stream != nil {
+			return spec.stream.Dump(stdout, data)
+		}
+		return parco.Dump(stdout, spec.model, data)
+	}
+	return validate(spec, data, stdout)
+}
+
+func decode(spec spec, data []byte, w io.Writer, opts options) error {
+	out := bytes.NewBuffer(nil)
+	if spec.stream != nil {
+		if err := spec.stream.StreamToJSON(out, data); err != nil {
+			return err
+		}
+	} else {
+		doc, err := parco.ToJSON(spec.model, data)
+		if err != nil {
+			return err
+		}
+		out.Write(doc)
+		out.WriteByte('\n')
+	}
+
+	if !opts.pretty {
+		_, err := w.Write(out.Bytes())
+		return err
+	}
+
+	for _, line := range bytes.Split(bytes.TrimSuffix(out.Bytes(), []byte("\n")), []byte("\n")) {
+		indented := bytes.NewBuffer(nil)
+		if err := json.Indent(indented, line, "", "  "); err != nil {
+			return err
+		}
+		indented.WriteByte('\n')
+		if _, err := w.Write(indented.Bytes()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func encode(spec spec, input []byte, w io.Writer, opts options) error {
+	var data []byte
+	if spec.stream != nil {
+		buf := bytes.NewBuffer(nil)
+		if err := spec.stream.StreamFromJSON(buf, bytes.NewReader(input)); err != nil {
+			return err
+		}
+		data = buf.Bytes()
+	} else {
+		var err error
+		if data, err = parco.FromJSON(spec.model, input); err != nil {
+			return err
+		}
+	}
+
+	return writeBinary(w, data, opts.out)
+}
+
+func validate(spec spec, data []byte, w io.Writer) error {
+	if spec.stream == nil {
+		if _, err := parco.ToJSON(spec.model, data); err != nil {
+			return err
+		}
+		_, err := fmt.Fprintf(w, "ok: %d bytes\n", len(data))
+		return err
+	}
+
+	out := bytes.NewBuffer(nil)
+	if err := spec.stream.StreamToJSON(out, data); err != nil {
+		return err
+	}
+	_, err := fmt.Fprintf(w, "ok: %d messages, %d bytes\n", bytes.Count(out.Bytes(), []byte("\n")), len(data))
+	return err
+}
+
+func readInput(path string, stdin io.Reader) ([]byte, error) {
+	if path == "" || path == "-" {
+		return io.ReadAll(stdin)
+	}
+	return os.ReadFile(path)
+}
+
+// decodeBinary decodes input written in format. Whitespace is ignored in hex
+// and base64 input, which may be wrapped.
+func decodeBinary(input []byte, format string) ([]byte, error) {
+	switch format {
+	case "raw":
+		return input, nil
+	case "hex":
+		text := strings.TrimPrefix(stripSpaces(input), "0x")
+		return hex.DecodeString(text)
+	case "base64":
+		return base64.StdEncoding.DecodeString(stripSpaces(input))
+	}
+	return nil, fmt.Errorf("unknown input format %q", format)
+}
+
+func writeBinary(w io.Writer, data []byte, format string) error {
+	var err error
+	switch format {
+	case "raw":
+		_, err = w.Write(data)
+	case "hex":
+		_, err = fmt.Fprintln(w, hex.EncodeToString(data))
+	case "base64":
+		_, err = fmt.Fprintln(w, base64.StdEncoding.EncodeToString(data))
+	default:
+		err = fmt.Errorf("unknown output format %q", format)
+	}
+	return err
+}
+
+func stripSpaces(input []byte) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) {
+			return -1
+		}
+		return r
+	}, string(input))
+}
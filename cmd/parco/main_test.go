@@ -0,0 +1,177 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sonirico/parco"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type item struct {
+	SKU   string
+	Price uint32
+}
+
+func itemBuilder() parco.ModelBuilder[item] {
+	return parco.Builder[item](parco.ObjectFactory[item]()).
+		SmallVarchar(
+			func(i *item) string { return i.SKU },
+			func(i *item, v string) { i.SKU = v },
+		).Named("SKU").
+		UInt32(binary.LittleEndian,
+			func(i *item) uint32 { return i.Price },
+			func(i *item, v uint32) { i.Price = v },
+		).Named("Price")
+}
+
+func compileItem(t *testing.T, it item) []byte {
+	t.Helper()
+	buf := bytes.NewBuffer(nil)
+	require.NoError(t, itemBuilder().Compile(it, buf))
+	return buf.Bytes()
+}
+
+func parcoRun(t *testing.T, stdin []byte, args ...string) (string, string, int) {
+	t.Helper()
+	stdout, stderr := bytes.NewBuffer(nil), bytes.NewBuffer(nil)
+	code := run(args, bytes.NewReader(stdin), stdout, stderr)
+	return stdout.String(), stderr.String(), code
+}
+
+func TestDecode(t *testing.T) {
+	data := compileItem(t, item{"ab", 7})
+
+	out, _, code := parcoRun(t, data, "decode", "-schema", "testdata/item.json")
+	require.Equal(t, 0, code)
+	assert.Equal(t, `{"SKU":"ab","Price":7}`+"\n", out)
+
+	hexInput := []byte("0x02 6162\n07000000\n")
+	out, _, code = parcoRun(t, hexInput, "decode", "-schema", "testdata/item.json", "-in", "hex", "-pretty")
+	require.Equal(t, 0, code)
+	assert.Equal(t, "{\n  \"SKU\": \"ab\",\n  \"Price\": 7\n}\n", out)
+}
+
+func TestDecode_SchemaFromBuilder(t *testing.T) {
+	schema, err := json.Marshal(itemBuilder().Schema())
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	schemaPath := filepath.Join(dir, "item.json")
+	require.NoError(t, os.WriteFile(schemaPath, schema, 0o644))
+	inputPath := filepath.Join(dir, "item.bin")
+	require.NoError(t, os.WriteFile(inputPath, compileItem(t, item{"x", 1}), 0o644))
+
+	out, _, code := parcoRun(t, nil, "decode", "-schema", schemaPath, inputPath)
+	require.Equal(t, 0, code)
+	assert.Equal(t, `{"SKU":"x","Price":1}`+"\n", out)
+}
+
+func TestEncode(t *testing.T) {
+	out, _, code := parcoRun(t, []byte(`{"SKU":"ab","Price":7}`), "encode", "-schema", "testdata/item.json")
+	require.Equal(t, 0, code)
+	assert.Equal(t, string(compileItem(t, item{"ab", 7})), out)
+
+	out, _, code = parcoRun(t, []byte(`{"SKU":"ab","Price":7}`), "encode", "-schema", "testdata/item.json", "-out", "base64")
+	require.Equal(t, 0, code)
+	assert.Equal(t, "AmFiBwAAAA==\n", out)
+}
+
+func TestDumpAndValidate(t *testing.T) {
+	data := compileItem(t, item{"ab", 7})
+
+	out, _, code := parcoRun(t, data, "dump", "-schema", "testdata/item.json")
+	require.Equal(t, 0, code)
+	assert.Contains(t, out, `Item.SKU    string<uint8>  len 2 "ab"`)
+
+	out, _, code = parcoRun(t, data, "validate", "-schema", "testdata/item.json")
+	require.Equal(t, 0, code)
+	assert.Equal(t, "ok: 7 bytes\n", out)
+
+	_, errOut, code := parcoRun(t, data[:5], "validate", "-schema", "testdata/item.json")
+	assert.Equal(t, 1, code)
+	assert.Contains(t, errOut, "parse Item.Price")
+
+	_, errOut, code = parcoRun(t, append(data, 0), "validate", "-schema", "testdata/item.json")
+	assert.Equal(t, 1, code)
+	assert.Contains(t, errOut, "1 trailing bytes")
+}
+
+func TestStream(t *testing.T) {
+	input := `{"type":1,"value":{"SKU":"ab","Price":7}}
+{"type":2,"value":{"Seq":300}}
+`
+	encoded, _, code := parcoRun(t, []byte(input), "encode", "-schema", "testdata/stream.json", "-out", "hex")
+	require.Equal(t, 0, code)
+	// Type id, frame length, message.
+	assert.Equal(t, "0107026162070000000202ac02\n", encoded)
+
+	out, _, code := parcoRun(t, []byte(encoded), "decode", "-schema", "testdata/stream.json", "-in", "hex")
+	require.Equal(t, 0, code)
+	assert.Equal(t, input, out)
+
+	out, _, code = parcoRun(t, []byte(encoded), "validate", "-schema", "testdata/stream.json", "-in", "hex")
+	require.Equal(t, 0, code)
+	assert.Equal(t, "ok: 2 messages, 13 bytes\n", out)
+
+	out, _, code = parcoRun(t, []byte(encoded), "dump", "-schema", "testdata/stream.json", "-in", "hex")
+	require.Equal(t, 0, code)
+	assert.Contains(t, out, "frame varuint")
+	assert.Contains(t, out, "Ping.Seq")
+}
+
+func TestSchemaLanguage(t *testing.T) {
+	input := `{"type":1,"value":{"SKU":"ab","Price":7}}
+{"type":2,"value":{"Seq":300}}
+`
+	encoded, _, code := parcoRun(t, []byte(input), "encode", "-schema", "testdata/stream.parco", "-out", "hex")
+	require.Equal(t, 0, code)
+	assert.Equal(t, "0107026162070000000202ac02\n", encoded)
+
+	out, _, code := parcoRun(t, []byte(encoded), "decode", "-schema", "testdata/stream.parco", "-in", "hex")
+	require.Equal(t, 0, code)
+	assert.Equal(t, input, out)
+
+	data := compileItem(t, item{"ab", 7})
+	out, _, code = parcoRun(t, data, "decode", "-schema", "testdata/stream.parco", "-model", "Item")
+	require.Equal(t, 0, code)
+	assert.Equal(t, `{"SKU":"ab","Price":7}`+"\n", out)
+
+	_, errOut, code := parcoRun(t, data, "decode", "-schema", "testdata/stream.parco", "-model", "Order")
+	assert.Equal(t, 1, code)
+	assert.Contains(t, errOut, "model Order: unknown type")
+
+	_, errOut, code = parcoRun(t, data, "decode", "-schema", "testdata/item.json", "-model", "Item")
+	assert.Equal(t, 1, code)
+	assert.Contains(t, errOut, "-model only applies to .parco files")
+
+	dir := t.TempDir()
+	schemaPath := filepath.Join(dir, "models.parco")
+	require.NoError(t, os.WriteFile(schemaPath, []byte("message A { x bool }\nmessage B { y bool }\n"), 0o644))
+	_, errOut, code = parcoRun(t, nil, "decode", "-schema", schemaPath)
+	assert.Equal(t, 1, code)
+	assert.Contains(t, errOut, "2 models and no stream declared")
+}
+
+func TestUsage(t *testing.T) {
+	_, errOut, code := parcoRun(t, nil)
+	assert.Equal(t, 2, code)
+	assert.True(t, strings.HasPrefix(errOut, "Usage: parco"))
+
+	_, errOut, code = parcoRun(t, nil, "frobnicate")
+	assert.Equal(t, 2, code)
+	assert.Contains(t, errOut, `unknown command "frobnicate"`)
+
+	_, _, code = parcoRun(t, nil, "decode")
+	assert.Equal(t, 2, code)
+
+	_, errOut, code = parcoRun(t, nil, "decode", "-schema", "testdata/missing.json")
+	assert.Equal(t, 1, code)
+	assert.Contains(t, errOut, "missing.json")
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/sonirico/parco"
+)
+
+// spec is a loaded schema file: either a single model or a stream.
+type spec struct {
+	model  parco.Schema
+	stream *parco.ModelMultiBuilder[int]
+}
+
+// loadSpec loads the schema file at path. Files ending in .parco are read
+// as parco schema language, where model selects one of the models; without
+// it, the stream is used if declared, else the only model. Other files hold
+// JSON schemas.
+func loadSpec(path, model string) (spec, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return spec{}, err
+	}
+
+	var s spec
+	if filepath.Ext(path) == ".parco" {
+		s, err = parcoSpec(data, model)
+	} else if model != "" {
+		err = fmt.Errorf("-model only applies to .parco files")
+	} else {
+		s, err = jsonSpec(data)
+	}
+	if err != nil {
+		return spec{}, fmt.Errorf("%s: %w", path, err)
+	}
+	return s, nil
+}
+
+func parcoSpec(data []byte, model string) (spec, error) {
+	file, err := parco.ParseSchemaFile(data)
+	if err != nil {
+		return spec{}, err
+	}
+
+	switch {
+	case model != "":
+		m, ok := file.Model(model)
+		if !ok {
+			return spec{}, fmt.Errorf("model %s: %w", model, parco.ErrUnknownType)
+		}
+		return spec{model: m}, nil
+	case file.Stream != nil:
+		return streamSpec(*file.Stream)
+	case len(file.Models) == 1:
+		return spec{model: file.Models[0]}, nil
+	}
+	return spec{}, fmt.Errorf("%d models and no stream declared, pick one with -model", len(file.Models))
+}
+
+func jsonSpec(data []byte) (spec, error) {
+	var probe map[string]json.RawMessage
+	if err := json.Unmarshal(data, &probe); err != nil {
+		return spec{}, err
+	}
+
+	if _, ok := probe["models"]; !ok {
+		var model parco.Schema
+		if err := json.Unmarshal(data, &model); err != nil {
+			return spec{}, err
+		}
+		return spec{model: model}, nil
+	}
+
+	var stream parco.StreamSchema
+	if err := json.Unmarshal(data, &stream); err != nil {
+		return spec{}, err
+	}
+	return streamSpec(stream)
+}
+
+func streamSpec(s parco.StreamSchema) (spec, error) {
+	b, err := s.Builder()
+	if err != nil {
+		return spec{}, err
+	}
+	return spec{stream: b}, nil
+}
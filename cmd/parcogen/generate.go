@@ -0,0 +1,258 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// generator renders the builder functions of one output file.
+type generator struct {
+	pkg string
+	// fileImports maps the package names imported by the source file to their
+	// paths, so that types referenced by the models can be imported back.
+	fileImports map[string]string
+	imports     map[string]bool
+	body        bytes.Buffer
+}
+
+func newGenerator(pkg string, fileImports map[string]string) *generator {
+	return &generator{
+		pkg:         pkg,
+		fileImports: fileImports,
+		imports:     map[string]bool{"github.com/sonirico/parco": true},
+	}
+}
+
+func (g *generator) printf(format string, args ...any) {
+	fmt.Fprintf(&g.body, format, args...)
+}
+
+// source returns the gofmt'ed file contents.
+func (g *generator) source() ([]byte, error) {
+	for name, path := range g.fileImports {
+		if regexp.MustCompile(`\b` + regexp.QuoteMeta(name) + `\.`).Match(g.body.Bytes()) {
+			g.imports[path] = true
+		}
+	}
+
+	var out bytes.Buffer
+
+	fmt.Fprintf(&out, "// Code generated by parcogen. DO NOT EDIT.\n\n")
+	fmt.Fprintf(&out, "package %s\n\n", g.pkg)
+
+	// Standard library imports first, then the rest, as goimports does.
+	var std, others []string
+	for path := range g.imports {
+		if strings.Contains(strings.Split(path, "/")[0], ".") {
+			others = append(others, path)
+		} else {
+			std = append(std, path)
+		}
+	}
+	sort.Strings(std)
+	sort.Strings(others)
+
+	out.WriteString("import (\n")
+	for _, path := range std {
+		fmt.Fprintf(&out, "\t%q\n", path)
+	}
+	if len(std) > 0 {
+		out.WriteString("\n")
+	}
+	for _, path := range others {
+		fmt.Fprintf(&out, "\t%q\n", path)
+	}
+	out.WriteString(")\n\n")
+
+	out.Write(g.body.Bytes())
+
+	src, err := format.Source(out.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("formatting generated code: %w\n%s", err, out.String())
+	}
+	return src, nil
+}
+
+func (g *generator) model(m modelSpec) {
+	g.printf("// %sBuilder returns the parco builder for %s.\n", m.name, m.name)
+	g.printf("func %sBuilder() parco.ModelBuilder[%s] {\n", m.name, m.name)
+	g.printf("return parco.Builder[%s](parco.ObjectFactory[%s]())", m.name, m.name)
+	for _, f := range m.fields {
+		g.printf(".\n")
+		g.field(m.name, f)
+		g.printf(".\nNamed(%q)", f.name)
+	}
+	g.printf("\n}\n\n")
+}
+
+func (g *generator) field(model string, f fieldSpec) {
+	c := f.codec
+
+	switch c.kind {
+	case "slice":
+		g.printf("Slice(parco.SliceField[%s, %s](\n%s,\n%s,\n", model, c.elem.goType, g.header(*c.header), g.typeExpr(*c.elem))
+		g.printf("func(m *%s, v parco.SliceView[%s]) { m.%s = %s },\n",
+			model, c.elem.goType, f.name, convert(c.named, "v"))
+		g.printf("func(m *%s) parco.SliceView[%s] { return %s },\n))",
+			model, c.elem.goType, convert(choose(c.named != "", "parco.SliceView["+c.elem.goType+"]", ""), "m."+f.name))
+	case "array":
+		g.printf("Array(parco.ArrayField[%s, %s](\n%d,\n%s,\n", model, c.elem.goType, c.length, g.typeExpr(*c.elem))
+		g.printf("func(m *%s, v parco.SliceView[%s]) { copy(m.%s[:], v) },\n", model, c.elem.goType, f.name)
+		g.printf("func(m *%s) parco.SliceView[%s] { return m.%s[:] },\n))", model, c.elem.goType, f.name)
+	case "map":
+		g.printf("Map(parco.MapField[%s, %s, %s](\n%s,\n%s,\n%s,\n",
+			model, c.key.goType, c.value.goType, g.header(*c.header), g.typeExpr(*c.key), g.typeExpr(*c.value))
+		g.printf("func(m *%s, v %s) { m.%s = %s },\n", model, c.goType, f.name, convert(c.named, "v"))
+		g.printf("func(m *%s) %s { return %s },\n))", model, c.goType, convert(choose(c.named != "", c.goType, ""), "m."+f.name))
+	case "option":
+		g.printf("Option(parco.OptionField[%s, %s](\n%s,\n", model, c.elem.goType, g.typeExpr(*c.elem))
+		g.printf("func(m *%s, v *%s) { m.%s = v },\n", model, c.elem.goType, f.name)
+		g.printf("func(m *%s) *%s { return m.%s },\n))", model, c.elem.goType, f.name)
+	case "struct":
+		g.printf("Struct(parco.StructField[%s, %s](\n", model, c.goType)
+		g.printf("func(m *%s) %s { return m.%s },\n", model, c.goType, f.name)
+		g.printf("func(m *%s, v %s) { m.%s = v },\n", model, c.goType, f.name)
+		g.printf("%s(),\n))", c.builder)
+	default:
+		g.scalar(model, f)
+	}
+}
+
+// shortcuts maps scalar codecs to the ModelBuilder method that registers them
+// directly. The boolean reports whether the method takes a byte order.
+var shortcuts = map[string]struct {
+	method string
+	order  bool
+}{
+	"bool":            {"Bool", false},
+	"uint8":           {"UInt8", false},
+	"int8":            {"Int8", false},
+	"uint16":          {"UInt16", true},
+	"uint32":          {"UInt32", true},
+	"int32":           {"Int32", true},
+	"uint64":          {"UInt64", true},
+	"int64":           {"Int64", true},
+	"int":             {"Int", true},
+	"float32":         {"Float32", true},
+	"float64":         {"Float64", true},
+	"varint:uint8":    {"VarUInt8", false},
+	"varint:uint16":   {"VarUInt16", false},
+	"varint:uint32":   {"VarUInt32", false},
+	"varint:uint64":   {"VarUInt64", false},
+	"varint:uint":     {"VarUInt", false},
+	"varint:int8":     {"VarInt8", false},
+	"varint:int16":    {"VarInt16", false},
+	"varint:int32":    {"VarInt32", false},
+	"varint:int64":    {"VarInt64", false},
+	"varint:int":      {"VarInt", false},
+	"string:uint16le": {"Varchar", false},
+	"string:uint8":    {"SmallVarchar", false},
+	"time":            {"TimeUTC", false},
+	"time:loc":        {"TimeLocation", false},
+}
+
+func shortcutKey(c codec) string {
+	switch {
+	case c.varint:
+		return "varint:" + c.kind
+	case c.kind == "string":
+		return "string:" + c.header.kind + choose(c.header.kind == "uint8", "", c.header.order)
+	case c.kind == "time" && c.location:
+		return "time:loc"
+	}
+	return c.kind
+}
+
+func (g *generator) scalar(model string, f fieldSpec) {
+	c := f.codec
+	getter := fmt.Sprintf("func(m *%s) %s { return %s }", model, c.goType, convert(choose(c.named != "", c.goType, ""), "m."+f.name))
+	setter := fmt.Sprintf("func(m *%s, v %s) { m.%s = %s }", model, c.goType, f.name, convert(c.named, "v"))
+
+	if sc, ok := shortcuts[shortcutKey(c)]; ok {
+		g.printf("%s(\n", sc.method)
+		if sc.order {
+			g.printf("%s,\n", g.byteOrder(c.order))
+		}
+		g.printf("%s,\n%s,\n)", getter, setter)
+		return
+	}
+
+	g.printf("Field(parco.FixedField[%s, %s]{\nType: %s,\nGetter: %s,\nSetter: %s,\n})",
+		model, c.goType, g.typeExpr(c), getter, setter)
+}
+
+func (g *generator) byteOrder(order string) string {
+	g.imports["encoding/binary"] = true
+	return choose(order == "be", "binary.BigEndian", "binary.LittleEndian")
+}
+
+// typeExpr returns the expression building the parco.Type for c.
+func (g *generator) typeExpr(c codec) string {
+	suffix := choose(c.order == "be", "BE", "LE")
+
+	if c.varint {
+		name := strings.ToUpper(c.kind[:1]) + c.kind[1:]
+		if strings.HasPrefix(c.kind, "uint") {
+			name = "UInt" + strings.TrimPrefix(c.kind, "uint")
+		}
+		return "parco.Var" + name + "()"
+	}
+
+	switch c.kind {
+	case "bool":
+		return "parco.Bool()"
+	case "uint8":
+		return "parco.UInt8()"
+	case "int8":
+		return "parco.Int8()"
+	case "uint16", "uint32", "uint64", "uint":
+		return "parco.UInt" + strings.TrimPrefix(c.kind, "uint") + suffix + "()"
+	case "int16", "int32", "int64", "int":
+		return "parco.Int" + strings.TrimPrefix(c.kind, "int") + suffix + "()"
+	case "float32", "float64":
+		return "parco.Float" + strings.TrimPrefix(c.kind, "float") + suffix + "()"
+	case "string":
+		return "parco.NewVarcharType(" + g.header(*c.header) + ")"
+	case "blob":
+		return "parco.Blob(" + g.header(*c.header) + ")"
+	case "time":
+		return choose(c.location, "parco.TimeLocation()", "parco.TimeUTC()")
+	case "struct":
+		return "parco.Struct[" + c.goType + "](" + c.builder + "())"
+	}
+
+	panic("parcogen: no type expression for kind " + strconv.Quote(c.kind))
+}
+
+func (g *generator) header(c codec) string {
+	suffix := choose(c.order == "be", "BE", "LE")
+
+	switch c.kind {
+	case "uint8":
+		return "parco.UInt8Header()"
+	case "uint16":
+		return "parco.UInt16Header" + suffix + "()"
+	case "uint32":
+		return "parco.UInt32" + suffix + "Header()"
+	case "uint64":
+		return "parco.UInt64" + suffix + "Header()"
+	case "varint":
+		return "parco.VarUIntHeader()"
+	}
+
+	panic("parcogen: unknown header kind " + strconv.Quote(c.kind))
+}
+
+// convert wraps expr in a conversion to typ, or returns it untouched when no
+// conversion is needed.
+func convert(typ, expr string) string {
+	if typ == "" {
+		return expr
+	}
+	return typ + "(" + expr + ")"
+}
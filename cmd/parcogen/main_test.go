@@ -0,0 +1,101 @@
+package main
+
+import (
+	"go/parser"
+	"go/token"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func generateSource(t *testing.T, src string, types ...string) (string, error) {
+	t.Helper()
+	file, err := parser.ParseFile(token.NewFileSet(), "model.go", src, parser.SkipObjectResolution)
+	require.NoError(t, err)
+	out, err := generateFile(file, types)
+	return string(out), err
+}
+
+func TestGenerate_ExampleIsUpToDate(t *testing.T) {
+	expected, err := os.ReadFile("../../examples/codegen/model_parco.go")
+	require.NoError(t, err)
+
+	actual, err := generate("../../examples/codegen/model.go", []string{"Order", "Item", "Customer"})
+	require.NoError(t, err)
+
+	assert.Equal(t, string(expected), string(actual), "run go generate ./examples/codegen")
+}
+
+func TestGenerate_Scalars(t *testing.T) {
+	out, err := generateSource(t, `package model
+
+type Level int16
+
+type Reading struct {
+	Sensor  uint16
+	Value   float32 `+"`parco:\"be\"`"+`
+	Counter uint64  `+"`parco:\"varint\"`"+`
+	Level   Level
+	Active  bool
+	skipped int
+	Ignored int `+"`parco:\"-\"`"+`
+}
+`, "Reading")
+	require.NoError(t, err)
+
+	assert.Contains(t, out, "func ReadingBuilder() parco.ModelBuilder[Reading]")
+	assert.Contains(t, out, "UInt16(\n\t\t\tbinary.LittleEndian,")
+	assert.Contains(t, out, "Float32(\n\t\t\tbinary.BigEndian,")
+	assert.Contains(t, out, "VarUInt64(")
+	assert.Contains(t, out, "Type:   parco.Int16LE(),")
+	assert.Contains(t, out, "func(m *Reading) int16 { return int16(m.Level) }")
+	assert.Contains(t, out, "func(m *Reading, v int16) { m.Level = Level(v) }")
+	assert.Contains(t, out, "Bool(")
+	assert.NotContains(t, out, "skipped")
+	assert.NotContains(t, out, "Ignored")
+}
+
+func TestGenerate_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want string
+	}{
+		{
+			name: "unknown option",
+			src:  "package m\ntype M struct { A int `parco:\"wat\"` }",
+			want: `unknown option "wat"`,
+		},
+		{
+			name: "bad header",
+			src:  "package m\ntype M struct { A string `parco:\"header=int8\"` }",
+			want: `unsupported header "int8"`,
+		},
+		{
+			name: "nested collections",
+			src:  "package m\ntype M struct { A [][]int }",
+			want: "M.A: unsupported element type []int",
+		},
+		{
+			name: "varint float",
+			src:  "package m\ntype M struct { A float64 `parco:\"varint\"` }",
+			want: "varint is not supported for float64",
+		},
+		{
+			name: "not a struct",
+			src:  "package m\ntype M int",
+			want: "type M is not a struct",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := generateSource(t, tt.src, "M")
+			require.Error(t, err)
+			assert.True(t, strings.Contains(err.Error(), tt.want), err.Error())
+		})
+	}
+}
@@ -0,0 +1,372 @@
+package main
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// codec describes how a single Go value maps onto a parco type.
+type codec struct {
+	// kind is the wire kind: a builtin scalar name (bool, uint8, ..., float64),
+	// or one of string, blob, time, struct, slice, array, map and option.
+	kind string
+	// goType is the Go type handed to getters and setters, e.g. "uint16".
+	goType string
+	// named holds the declared type name when the field uses a named type
+	// whose underlying type is goType, so conversions are emitted.
+	named string
+
+	order    string
+	varint   bool
+	location bool
+	length   int
+	builder  string
+
+	header *codec
+	elem   *codec
+	key    *codec
+	value  *codec
+}
+
+type fieldSpec struct {
+	name  string
+	codec codec
+}
+
+type modelSpec struct {
+	name   string
+	fields []fieldSpec
+}
+
+type tagOptions struct {
+	skip     bool
+	order    string
+	varint   bool
+	location bool
+	header   string
+	elem     string
+	key      string
+	value    string
+	builder  string
+}
+
+var scalarKinds = map[string]bool{
+	"bool": true, "byte": true, "uint8": true, "int8": true,
+	"uint16": true, "int16": true, "uint32": true, "int32": true,
+	"uint64": true, "int64": true, "uint": true, "int": true,
+	"float32": true, "float64": true,
+}
+
+var headerKinds = map[string]bool{
+	"uint8": true, "uint16": true, "uint32": true, "uint64": true, "varint": true,
+}
+
+// parseTag reads the options of a `parco:"..."` tag. Options are comma
+// separated flags (le, be, varint, loc, -) or key=value pairs (header, elem,
+// key, value, builder).
+func parseTag(tag string) (opts tagOptions, err error) {
+	opts.order = "le"
+	if tag == "" {
+		return
+	}
+
+	for _, raw := range strings.Split(tag, ",") {
+		opt := strings.TrimSpace(raw)
+		name, value, hasValue := strings.Cut(opt, "=")
+
+		switch {
+		case opt == "":
+		case opt == "-":
+			opts.skip = true
+		case opt == "le" || opt == "be":
+			opts.order = opt
+		case opt == "varint":
+			opts.varint = true
+		case opt == "loc":
+			opts.location = true
+		case hasValue && name == "header":
+			if !headerKinds[value] {
+				return opts, fmt.Errorf("unsupported header %q", value)
+			}
+			opts.header = value
+		case hasValue && name == "elem":
+			opts.elem = value
+		case hasValue && name == "key":
+			opts.key = value
+		case hasValue && name == "value":
+			opts.value = value
+		case hasValue && name == "builder":
+			opts.builder = value
+		default:
+			return opts, fmt.Errorf("unknown option %q", opt)
+		}
+	}
+
+	return
+}
+
+// resolver turns struct field types into codecs. It knows the type
+// declarations of the file being processed so that named types and nested
+// structs can be followed.
+type resolver struct {
+	decls map[string]ast.Expr
+}
+
+func newResolver(file *ast.File) *resolver {
+	r := &resolver{decls: make(map[string]ast.Expr)}
+	for _, decl := range file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.TYPE {
+			continue
+		}
+		for _, s := range gen.Specs {
+			ts, ok := s.(*ast.TypeSpec)
+			if !ok || ts.TypeParams != nil {
+				continue
+			}
+			r.decls[ts.Name.Name] = ts.Type
+		}
+	}
+	return r
+}
+
+func (r *resolver) model(name string) (modelSpec, error) {
+	expr, ok := r.decls[name]
+	if !ok {
+		return modelSpec{}, fmt.Errorf("type %s not found", name)
+	}
+	st, ok := expr.(*ast.StructType)
+	if !ok {
+		return modelSpec{}, fmt.Errorf("type %s is not a struct", name)
+	}
+
+	model := modelSpec{name: name}
+	for _, field := range st.Fields.List {
+		tag := ""
+		if field.Tag != nil {
+			raw, err := strconv.Unquote(field.Tag.Value)
+			if err != nil {
+				return model, err
+			}
+			tag = reflect.StructTag(raw).Get("parco")
+		}
+
+		opts, err := parseTag(tag)
+		if err != nil {
+			return model, fmt.Errorf("%s: %w", name, err)
+		}
+		if opts.skip {
+			continue
+		}
+
+		if len(field.Names) == 0 {
+			return model, fmt.Errorf("%s: embedded fields are not supported, tag them with `parco:\"-\"`", name)
+		}
+
+		for _, ident := range field.Names {
+			if !ident.IsExported() {
+				continue
+			}
+			c, err := r.resolve(field.Type, opts)
+			if err != nil {
+				return model, fmt.Errorf("%s.%s: %w", name, ident.Name, err)
+			}
+			model.fields = append(model.fields, fieldSpec{name: ident.Name, codec: c})
+		}
+	}
+
+	return model, nil
+}
+
+func (r *resolver) resolve(expr ast.Expr, opts tagOptions) (codec, error) {
+	switch t := expr.(type) {
+	case *ast.Ident:
+		return r.resolveIdent(t.Name, opts)
+	case *ast.SelectorExpr:
+		pkg, ok := t.X.(*ast.Ident)
+		if !ok {
+			return codec{}, fmt.Errorf("unsupported type %s", exprString(expr))
+		}
+		if pkg.Name == "time" && t.Sel.Name == "Time" {
+			return codec{kind: "time", goType: "time.Time", location: opts.location}, nil
+		}
+		qualified := pkg.Name + "." + t.Sel.Name
+		return codec{
+			kind:    "struct",
+			goType:  qualified,
+			builder: choose(opts.builder != "", opts.builder, qualified+"Builder"),
+		}, nil
+	case *ast.StarExpr:
+		elem, err := r.resolve(t.X, elemOptions(opts, opts.elem))
+		if err != nil {
+			return codec{}, err
+		}
+		if !elemSupported(elem) {
+			return codec{}, fmt.Errorf("unsupported optional type %s", exprString(expr))
+		}
+		return codec{kind: "option", goType: "*" + elem.goType, elem: &elem}, nil
+	case *ast.ArrayType:
+		return r.resolveArray(t, opts)
+	case *ast.MapType:
+		key, err := r.resolve(t.Key, elemOptions(opts, opts.key))
+		if err != nil {
+			return codec{}, err
+		}
+		value, err := r.resolve(t.Value, elemOptions(opts, opts.value))
+		if err != nil {
+			return codec{}, err
+		}
+		if !elemSupported(key) || key.named != "" || !elemSupported(value) || value.named != "" {
+			return codec{}, fmt.Errorf("unsupported map type %s", exprString(expr))
+		}
+		header := headerCodec(opts)
+		return codec{
+			kind:   "map",
+			goType: "map[" + key.goType + "]" + value.goType,
+			header: &header,
+			key:    &key,
+			value:  &value,
+		}, nil
+	}
+
+	return codec{}, fmt.Errorf("unsupported type %s", exprString(expr))
+}
+
+func (r *resolver) resolveIdent(name string, opts tagOptions) (codec, error) {
+	switch {
+	case scalarKinds[name]:
+		kind := name
+		if kind == "byte" {
+			kind = "uint8"
+		}
+		if opts.varint && strings.HasPrefix(kind, "float") {
+			return codec{}, fmt.Errorf("varint is not supported for %s", name)
+		}
+		return codec{kind: kind, goType: name, order: opts.order, varint: opts.varint}, nil
+	case name == "string":
+		header := headerCodec(opts)
+		return codec{kind: "string", goType: "string", header: &header}, nil
+	}
+
+	decl, ok := r.decls[name]
+	if !ok {
+		return codec{}, fmt.Errorf("unknown type %s", name)
+	}
+
+	if _, ok := decl.(*ast.StructType); ok {
+		return codec{
+			kind:    "struct",
+			goType:  name,
+			builder: choose(opts.builder != "", opts.builder, name+"Builder"),
+		}, nil
+	}
+
+	// Named non-struct types are encoded as their underlying type, converting
+	// at the getter and setter.
+	underlying, err := r.resolve(decl, opts)
+	if err != nil {
+		return codec{}, err
+	}
+	if underlying.named != "" {
+		return codec{}, fmt.Errorf("type %s: named types of named types are not supported", name)
+	}
+	underlying.named = name
+	return underlying, nil
+}
+
+func (r *resolver) resolveArray(t *ast.ArrayType, opts tagOptions) (codec, error) {
+	if t.Len == nil {
+		if ident, ok := t.Elt.(*ast.Ident); ok && (ident.Name == "byte" || ident.Name == "uint8") && opts.elem == "" {
+			header := headerCodec(opts)
+			return codec{kind: "blob", goType: "[]byte", header: &header}, nil
+		}
+	}
+
+	elem, err := r.resolve(t.Elt, elemOptions(opts, opts.elem))
+	if err != nil {
+		return codec{}, err
+	}
+	if !elemSupported(elem) || elem.named != "" {
+		return codec{}, fmt.Errorf("unsupported element type %s", exprString(t.Elt))
+	}
+
+	if t.Len == nil {
+		header := headerCodec(opts)
+		return codec{kind: "slice", goType: "[]" + elem.goType, header: &header, elem: &elem}, nil
+	}
+
+	lit, ok := t.Len.(*ast.BasicLit)
+	if !ok || lit.Kind != token.INT {
+		return codec{}, fmt.Errorf("array length must be an integer literal")
+	}
+	length, err := strconv.Atoi(lit.Value)
+	if err != nil {
+		return codec{}, err
+	}
+
+	return codec{
+		kind:   "array",
+		goType: "[" + lit.Value + "]" + elem.goType,
+		length: length,
+		elem:   &elem,
+	}, nil
+}
+
+// elemOptions derives the options applied to the element of a collection:
+// byte order is inherited while the header belongs to the collection itself.
+// spec, when given, is a single flag such as "varint" or "be".
+func elemOptions(opts tagOptions, spec string) tagOptions {
+	elem := tagOptions{order: opts.order, location: opts.location}
+	switch spec {
+	case "varint":
+		elem.varint = true
+	case "le", "be":
+		elem.order = spec
+	}
+	return elem
+}
+
+func elemSupported(c codec) bool {
+	switch c.kind {
+	case "slice", "array", "map", "option":
+		return false
+	}
+	return true
+}
+
+func headerCodec(opts tagOptions) codec {
+	kind := choose(opts.header != "", opts.header, "uint16")
+	return codec{kind: kind, goType: "int", order: opts.order}
+}
+
+func exprString(expr ast.Expr) string {
+	switch t := expr.(type) {
+	case *ast.Ident:
+		return t.Name
+	case *ast.SelectorExpr:
+		return exprString(t.X) + "." + t.Sel.Name
+	case *ast.StarExpr:
+		return "*" + exprString(t.X)
+	case *ast.ArrayType:
+		if t.Len == nil {
+			return "[]" + exprString(t.Elt)
+		}
+		return "[" + exprString(t.Len) + "]" + exprString(t.Elt)
+	case *ast.BasicLit:
+		return t.Value
+	case *ast.MapType:
+		return "map[" + exprString(t.Key) + "]" + exprString(t.Value)
+	}
+	return fmt.Sprintf("%T", expr)
+}
+
+func choose[T any](cond bool, a, b T) T {
+	if cond {
+		return a
+	}
+	return b
+}
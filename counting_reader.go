@@ -0,0 +1,52 @@
+package parco
+
+import "io"
+
+type (
+	// OffsetReader is a reader that knows how many bytes it has read so far.
+	// Parse errors report absolute stream offsets when parsing from one.
+	OffsetReader interface {
+		io.Reader
+		Offset() int
+	}
+
+	// CountingReader wraps a stream to count the bytes read from it. Parse
+	// from a CountingReader to have errors located in the whole stream
+	// rather than within the failing message.
+	CountingReader struct {
+		r   io.Reader
+		n   int
+		box [1]byte
+	}
+)
+
+func NewCountingReader(r io.Reader) *CountingReader {
+	return &CountingReader{r: r}
+}
+
+func (c *CountingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += n
+	return n, err
+}
+
+func (c *CountingReader) ReadByte() (byte, error) {
+	if br, ok := c.r.(io.ByteReader); ok {
+		b, err := br.ReadByte()
+		if err == nil {
+			c.n++
+		}
+		return b, err
+	}
+	n, err := io.ReadFull(c.r, c.box[:])
+	c.n += n
+	if err != nil {
+		return 0, err
+	}
+	return c.box[0], nil
+}
+
+// Offset returns the number of bytes read so far.
+func (c *CountingReader) Offset() int {
+	return c.n
+}
@@ -0,0 +1,53 @@
+package parco
+
+import (
+	"bytes"
+	"io"
+	"testing"
+	"testing/iotest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCountingReader(t *testing.T) {
+	tests := []struct {
+		name string
+		r    io.Reader
+	}{
+		{"byte reader", bytes.NewReader([]byte{1, 2, 3})},
+		{"plain reader", iotest.OneByteReader(bytes.NewReader([]byte{1, 2, 3}))},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewCountingReader(tt.r)
+
+			b, err := r.ReadByte()
+			require.NoError(t, err)
+			assert.Equal(t, byte(1), b)
+			assert.Equal(t, 1, r.Offset())
+
+			box := make([]byte, 2)
+			_, err = io.ReadFull(r, box)
+			require.NoError(t, err)
+			assert.Equal(t, 3, r.Offset())
+
+			_, err = r.ReadByte()
+			assert.ErrorIs(t, err, io.EOF)
+			assert.Equal(t, 3, r.Offset())
+		})
+	}
+}
+
+func TestBufferCursor_ReadByte(t *testing.T) {
+	cursor := NewBufferCursor([]byte{7}, 0)
+
+	b, err := cursor.ReadByte()
+	require.NoError(t, err)
+	assert.Equal(t, byte(7), b)
+	assert.Equal(t, 1, cursor.Offset())
+
+	_, err = cursor.ReadByte()
+	assert.ErrorIs(t, err, ErrCannotRead)
+}
@@ -0,0 +1,215 @@
+package parco
+
+import (
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// dumpRowBytes is the number of bytes shown per dump row.
+const dumpRowBytes = 8
+
+// DumpLine annotates a span of a message.
+type DumpLine struct {
+	Offset int
+	Bytes  []byte
+	// Path locates the value, e.g. "Order.Items[3].Price".
+	Path string
+	// Type is the schema type of the value, e.g. "uint32 LE".
+	Type string
+	// Header is what the span tells about what follows: a length ("len 3"),
+	// an option presence ("some", "none") or a tagged field key. Empty for
+	// plain values.
+	Header string
+	// Value is the decoded value, formatted. Empty for headers.
+	Value string
+	// Err is set on the line where decoding failed, which spans the rest of
+	// the data.
+	Err error
+}
+
+// Explain decodes data as a message of the model described by d, usually a
+// ModelBuilder, and returns one line per header and value read. Decoding
+// only needs the schema, so models must be described by built-in types:
+// their parsers read every value, within the DecodeLimits of d if any.
+// When decoding fails, the last line points at the failure and the error is
+// returned as well.
+func Explain(d Describer, data []byte) ([]DumpLine, error) {
+	cursor := NewBufferCursor(data, 0)
+	x := explainer{}
+	err := x.message(&cursor, 0, limitsOf(d), d.Schema(), nil)
+	x.trailing(&cursor)
+	return x.lines, err
+}
+
+// Dump writes the annotated hex dump of data, a message of the model
+// described by d, one line per header and value:
+//
+//	offset  bytes        path                  type                       value
+//	     0  2a 00        Order.ID              uint16 LE                  42
+//	     2  01           Order.Items           slice<uint8>[struct Item]  len 1
+//	     3  02 61 62     Order.Items[0].SKU    string<uint8>              len 2 "ab"
+//	     6  07 00 00 00  Order.Items[0].Price  uint32 LE                  7
+//
+// It returns the decoding error, if any, after writing the dump up to the
+// failure.
+func Dump(w io.Writer, d Describer, data []byte) error {
+	lines, err := Explain(d, data)
+	if werr := writeDump(w, lines); werr != nil {
+		return werr
+	}
+	return err
+}
+
+// Explain decodes data as a stream of messages. See Explain.
+func (b *ModelMultiBuilder[T]) Explain(data []byte) ([]DumpLine, error) {
+	cursor := NewBufferCursor(data, 0)
+	x := explainer{}
+
+	for cursor.cursor < len(cursor.data) {
+		origin := cursor.cursor
+		start := origin
+		id, err := b.header.Parse(&cursor)
+		if err != nil {
+			return x.lines, x.fail(&cursor, start, "id "+Describe(b.header).TypeString(), err)
+		}
+		x.add(&cursor, start, DumpLine{Type: "id " + Describe(b.header).TypeString(), Value: fmt.Sprint(id)})
+
+		size := -1
+		if b.frame != nil {
+			start = cursor.cursor
+			if size, err = b.frame.Parse(&cursor); err != nil {
+				return x.lines, x.fail(&cursor, start, "frame "+Describe(b.frame).TypeString(), err)
+			}
+			x.add(&cursor, start, DumpLine{Type: "frame " + Describe(b.frame).TypeString(), Header: "len " + strconv.Itoa(size)})
+		}
+
+		p, ok := b.parsers[id]
+		if !ok {
+			err = fmt.Errorf("%v: %w", id, ErrUnknownType)
+			return x.lines, x.fail(&cursor, cursor.cursor, "", err)
+		}
+
+		if err = x.message(&cursor, origin, b.limits, Describe(p), &size); err != nil {
+			return x.lines, err
+		}
+	}
+
+	return x.lines, nil
+}
+
+// Dump writes the annotated hex dump of a stream of messages. See Dump.
+func (b *ModelMultiBuilder[T]) Dump(w io.Writer, data []byte) error {
+	lines, err := b.Explain(data)
+	if werr := writeDump(w, lines); werr != nil {
+		return werr
+	}
+	return err
+}
+
+type explainer struct {
+	lines []DumpLine
+}
+
+// message decodes one message described by s, starting at origin, within
+// the next *size bytes if size is given and not negative.
+func (x *explainer) message(cursor *BufferCursor, origin int, limits *DecodeLimits, s Schema, size *int) error {
+	dec := schemaDecoder{cursor: cursor, visit: x.visit, origin: origin, budget: newBudget(limits)}
+	root := If(s.Type != "", s.Type, s.Kind.String())
+
+	var err error
+	if size != nil && *size >= 0 {
+		_, err = dec.bounded(*size, s, root, func() (any, error) {
+			return dec.decode(s, root)
+		})
+	} else {
+		_, err = dec.decode(s, root)
+	}
+	return err
+}
+
+func (x *explainer) visit(e schemaEvent) {
+	line := DumpLine{
+		Offset: e.start,
+		Bytes:  e.bytes,
+		Path:   e.path,
+		Type:   e.schema.TypeString(),
+		Header: e.header,
+		Err:    e.err,
+	}
+	if e.err == nil {
+		line.Value = formatDumpValue(e.value)
+	}
+	x.lines = append(x.lines, line)
+}
+
+func (x *explainer) add(cursor *BufferCursor, start int, line DumpLine) {
+	line.Offset = start
+	line.Bytes = cursor.data[start:cursor.cursor]
+	x.lines = append(x.lines, line)
+}
+
+// fail reports err raised by the stream framing at start.
+func (x *explainer) fail(cursor *BufferCursor, start int, tp string, err error) error {
+	x.lines = append(x.lines, DumpLine{
+		Offset: start,
+		Bytes:  cursor.data[start:],
+		Type:   tp,
+		Err:    err,
+	})
+	return &ParseError{Index: -1, Offset: start, MessageStart: -1, StreamOffset: -1, Err: err}
+}
+
+// trailing reports the bytes left after the message.
+func (x *explainer) trailing(cursor *BufferCursor) {
+	if cursor.cursor < len(cursor.data) && (len(x.lines) == 0 || x.lines[len(x.lines)-1].Err == nil) {
+		x.lines = append(x.lines, DumpLine{
+			Offset: cursor.cursor,
+			Bytes:  cursor.data[cursor.cursor:],
+			Header: "trailing bytes",
+		})
+	}
+}
+
+func formatDumpValue(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return strconv.Quote(v)
+	case []byte:
+		return "0x" + hex.EncodeToString(v)
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	}
+	return fmt.Sprint(value)
+}
+
+func writeDump(w io.Writer, lines []DumpLine) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintln(tw, "offset\tbytes\tpath\ttype\tvalue")
+
+	for _, line := range lines {
+		value := strings.TrimSpace(line.Header + " " + line.Value)
+		if line.Err != nil {
+			value = "<- " + line.Err.Error()
+		}
+
+		rows := max(1, (len(line.Bytes)+dumpRowBytes-1)/dumpRowBytes)
+		for row := range rows {
+			chunk := line.Bytes[min(row*dumpRowBytes, len(line.Bytes)):min((row+1)*dumpRowBytes, len(line.Bytes))]
+			if row == 0 {
+				fmt.Fprintf(tw, "%6d\t% x\t%s\t%s\t%s\n", line.Offset, chunk, line.Path, line.Type, value)
+				continue
+			}
+			fmt.Fprintf(tw, "%6d\t% x\t\t\t\n", line.Offset+row*dumpRowBytes, chunk)
+		}
+	}
+
+	return tw.Flush()
+}
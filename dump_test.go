@@ -0,0 +1,261 @@
+package parco
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func dumpPaths(lines []DumpLine) []string {
+	paths := make([]string, len(lines))
+	for i, line := range lines {
+		paths[i] = line.Path
+	}
+	return paths
+}
+
+func TestDump(t *testing.T) {
+	data := compileParseOrder(t, parseOrder{
+		ID:     42,
+		Items:  []parseItem{{"ab", 7}},
+		Labels: map[string]uint16{"x": 1},
+	})
+
+	out := bytes.NewBuffer(nil)
+	require.NoError(t, Dump(out, parseOrderBuilder(), data))
+
+	expected := strings.Join([]string{
+		`offset  bytes        path                       type                                value`,
+		`     0  2a 00        parseOrder.ID              uint16 LE                           42`,
+		`     2  01           parseOrder.Items           slice<uint8>[struct parseItem]      len 1`,
+		`     3  02 61 62     parseOrder.Items[0].SKU    string<uint8>                       len 2 "ab"`,
+		`     6  07 00 00 00  parseOrder.Items[0].Price  uint32 LE                           7`,
+		`    10  01           parseOrder.Labels          map<uint8>[string<uint8>]uint16 LE  len 1`,
+		`    11  01 78        parseOrder.Labels[#0]      string<uint8>                       len 1 "x"`,
+		`    13  01 00        parseOrder.Labels[x]       uint16 LE                           1`,
+		`    15  00           parseOrder.Note            option[struct parseItem]            none`,
+		``,
+	}, "\n")
+	assert.Equal(t, expected, out.String())
+}
+
+func TestDump_LongValues(t *testing.T) {
+	buf := bytes.NewBuffer(nil)
+	require.NoError(t, parseItemBuilder().Compile(parseItem{"abcdefghijk", 1}, buf))
+
+	out := bytes.NewBuffer(nil)
+	require.NoError(t, Dump(out, parseItemBuilder(), buf.Bytes()))
+
+	expected := strings.Join([]string{
+		`offset  bytes                    path             type           value`,
+		`     0  0b 61 62 63 64 65 66 67  parseItem.SKU    string<uint8>  len 11 "abcdefghijk"`,
+		`     8  68 69 6a 6b                                              `,
+		`    12  01 00 00 00              parseItem.Price  uint32 LE      1`,
+		``,
+	}, "\n")
+	assert.Equal(t, expected, out.String())
+}
+
+func TestExplain_Failure(t *testing.T) {
+	data := compileParseOrder(t, parseOrder{ID: 1, Note: &parseItem{"n", 5}})
+
+	lines, err := Explain(parseOrderBuilder(), data[:len(data)-2])
+
+	var pe *ParseError
+	require.ErrorAs(t, err, &pe)
+	assert.ErrorIs(t, err, ErrCannotRead)
+	assert.Equal(t, "parseOrder.Note.Price", pe.Path)
+
+	last := lines[len(lines)-1]
+	assert.Equal(t, "parseOrder.Note.Price", last.Path)
+	assert.Equal(t, []byte{5, 0}, last.Bytes)
+	assert.Equal(t, pe.Offset, last.Offset)
+	assert.ErrorIs(t, last.Err, ErrCannotRead)
+	assert.Empty(t, last.Value)
+
+	out := bytes.NewBuffer(nil)
+	assert.ErrorIs(t, Dump(out, parseOrderBuilder(), data[:len(data)-2]), ErrCannotRead)
+	assert.Contains(t, out.String(), "<- unsufficient bytes read")
+}
+
+func TestExplain_TrailingBytes(t *testing.T) {
+	data := compileParseOrder(t, parseOrder{ID: 1})
+
+	lines, err := Explain(parseOrderBuilder(), append(data, 1, 2))
+	require.NoError(t, err)
+
+	last := lines[len(lines)-1]
+	assert.Equal(t, len(data), last.Offset)
+	assert.Equal(t, []byte{1, 2}, last.Bytes)
+	assert.Equal(t, "trailing bytes", last.Header)
+}
+
+func TestExplain_Tagged(t *testing.T) {
+	limit := int16(-3)
+	buf := bytes.NewBuffer(nil)
+	require.NoError(t, settingsBuilder().Compile(settings{Name: "db", Limit: &limit, Seq: 1}, buf))
+
+	lines, err := Explain(settingsBuilder(), buf.Bytes())
+	require.NoError(t, err)
+
+	headers := make([]string, len(lines))
+	values := make([]string, len(lines))
+	for i, line := range lines {
+		headers[i], values[i] = line.Header, line.Value
+	}
+
+	assert.Equal(t, []string{
+		"settings.Name", "settings.Name",
+		"settings.Limit", "settings.Limit", "settings.Limit",
+		"settings.Seq", "settings.Seq",
+		"settings",
+	}, dumpPaths(lines))
+	assert.Equal(t, []string{
+		"field 1 bytes len 3", "len 2",
+		"field 7 bytes len 3", "some", "",
+		"field 20 varint", "",
+		"end",
+	}, headers)
+	assert.Equal(t, []string{`"db"`, "-3", "1"}, []string{values[1], values[4], values[6]})
+}
+
+func TestExplain_ExtensibleUnknownFields(t *testing.T) {
+	buf := bytes.NewBuffer(nil)
+	v2 := deviceV2{ID: 1, Name: "a", Firmware: 3}
+	require.NoError(t, deviceV2Builder(ObjectFactory[deviceV2]()).Compile(v2, buf))
+
+	lines, err := Explain(deviceV1Builder(), buf.Bytes())
+	require.NoError(t, err)
+
+	require.Len(t, lines, 4)
+	assert.Equal(t, "len 9", lines[0].Header)
+	assert.Equal(t, "deviceV1.Name", lines[2].Path)
+	assert.Equal(t, "unknown bytes", lines[3].Header)
+	assert.Equal(t, []byte{3, 0, 0, 0, 0}, lines[3].Bytes)
+}
+
+func TestModelMultiBuilder_Explain(t *testing.T) {
+	buf := bytes.NewBuffer(nil)
+	b := multiBuilder()
+	require.NoError(t, b.CompileAny(multiItemType, parseItem{"ab", 1}, buf))
+	buf.WriteByte(9)
+
+	lines, err := b.Explain(buf.Bytes())
+	assert.ErrorIs(t, err, ErrUnknownType)
+
+	assert.Equal(t, []string{"", "parseItem.SKU", "parseItem.Price", "", ""}, dumpPaths(lines))
+	assert.Equal(t, "id uint8", lines[0].Type)
+	assert.Equal(t, "1", lines[0].Value)
+	assert.Equal(t, "9", lines[3].Value)
+	assert.ErrorIs(t, lines[4].Err, ErrUnknownType)
+}
+
+func TestModelMultiBuilder_ExplainFramed(t *testing.T) {
+	buf := bytes.NewBuffer(nil)
+	b := multiBuilder().Framed(UInt8Header())
+	require.NoError(t, b.CompileAny(multiItemType, parseItem{"ab", 1}, buf))
+
+	out := bytes.NewBuffer(nil)
+	require.NoError(t, b.Dump(out, buf.Bytes()))
+
+	expected := strings.Join([]string{
+		`offset  bytes        path             type           value`,
+		`     0  01                            id uint8       1`,
+		`     1  07                            frame uint8    len 7`,
+		`     2  02 61 62     parseItem.SKU    string<uint8>  len 2 "ab"`,
+		`     5  01 00 00 00  parseItem.Price  uint32 LE      1`,
+		``,
+	}, "\n")
+	assert.Equal(t, expected, out.String())
+}
+
+func TestExplain_AgreesWithParser(t *testing.T) {
+	schema := Schema{
+		Kind: KindStruct,
+		Type: "Model",
+		Fields: []Schema{
+			jsonField("A", VarUInt8()),
+			jsonField("B", VarInt32()),
+			jsonField("C", String(VarUIntHeader())),
+			jsonField("D", Slice(UInt8Header(), UInt16LE())),
+			jsonField("E", MapType(UInt8Header(), VarUInt16(), Bool())),
+			jsonField("F", Option(Float32LE())),
+			jsonField("G", TimeLocation()),
+		},
+	}
+	model, err := Dynamic(schema)
+	require.NoError(t, err)
+
+	buf := bytes.NewBuffer(nil)
+	err = model.Compile(map[string]any{
+		"A": uint8(200), "B": int32(-70000), "C": "abc",
+		"D": []any{uint16(1), uint16(2)}, "E": map[any]any{uint16(300): true},
+		"F": float32(1.5), "G": time.Unix(1, 0),
+	}, buf)
+	require.NoError(t, err)
+	valid := buf.Bytes()
+
+	// Every prefix of the message, and the message with each byte replaced.
+	corpus := [][]byte{
+		{0x81, 0x80, 0x00},                   // A as an overlong varint
+		{0x01, 0x80, 0x80, 0x80, 0x80, 0x10}, // B overflowing int32
+	}
+	for i := range valid {
+		corpus = append(corpus, valid[:i])
+		for _, b := range []byte{0x00, 0x02, 0x80, 0xff} {
+			mutated := bytes.Clone(valid)
+			mutated[i] = b
+			corpus = append(corpus, mutated)
+		}
+	}
+
+	for _, data := range corpus {
+		_, explainErr := Explain(schema, data)
+		_, parseErr := model.ParseBytes(data)
+		assert.Equal(t, parseErr == nil, explainErr == nil, "input %x: Explain: %v, parse: %v", data, explainErr, parseErr)
+	}
+}
+
+func TestExplain_Limits(t *testing.T) {
+	data := compileParseOrder(t, parseOrder{ID: 1, Items: []parseItem{{"abcdef", 1}}})
+
+	_, err := Explain(parseOrderBuilder().Limits(DecodeLimits{MaxStringBytes: 4}), data)
+	var limitErr *LimitError
+	require.ErrorAs(t, err, &limitErr)
+	assert.Equal(t, "MaxStringBytes", limitErr.Limit)
+
+	_, err = Explain(parseOrderBuilder().Limits(DecodeLimits{MaxMessageBytes: 4}), data)
+	require.ErrorAs(t, err, &limitErr)
+	assert.Equal(t, "MaxMessageBytes", limitErr.Limit)
+}
+
+func TestExplain_StructKeys(t *testing.T) {
+	type stock struct {
+		Counts map[parseItem]uint8
+	}
+	builder := Builder[stock](ObjectFactory[stock]()).
+		Map(MapField[stock, parseItem, uint8](
+			UInt8Header(),
+			Struct[parseItem](parseItemBuilder()),
+			UInt8(),
+			func(s *stock, v map[parseItem]uint8) { s.Counts = v },
+			func(s *stock) map[parseItem]uint8 { return s.Counts },
+		)).Named("Counts")
+
+	data, err := builder.Append(nil, stock{Counts: map[parseItem]uint8{{"ab", 7}: 3}})
+	require.NoError(t, err)
+
+	// Struct keys decode to maps, which cannot key a map themselves.
+	lines, err := Explain(builder, data)
+	var pe *ParseError
+	require.ErrorAs(t, err, &pe)
+	assert.ErrorIs(t, err, ErrUnknownType)
+	assert.Equal(t, "stock.Counts", pe.Path)
+	assert.Equal(t, "len 1", lines[0].Header)
+
+	assert.ErrorIs(t, Dump(bytes.NewBuffer(nil), builder, data), ErrUnknownType)
+}
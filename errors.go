@@ -6,13 +6,21 @@ import (
 )
 
 var (
-	ErrNotIntegerType    = errors.New("not an integer type")
-	ErrOverflow          = errors.New("bytes overflow")
-	ErrCannotRead        = errors.New("unsufficient bytes read")
-	ErrCannotWrite       = errors.New("unsufficient bytes written")
-	ErrAlreadyRegistered = errors.New("builder is registered already")
-	ErrUnknownType       = errors.New("unknown type")
-	ErrInvalidLength     = errors.New("invalid length")
+	ErrNotIntegerType     = errors.New("not an integer type")
+	ErrOverflow           = errors.New("bytes overflow")
+	ErrCannotRead         = errors.New("unsufficient bytes read")
+	ErrCannotWrite        = errors.New("unsufficient bytes written")
+	ErrAlreadyRegistered  = errors.New("builder is registered already")
+	ErrUnknownType        = errors.New("unknown type")
+	ErrInvalidLength      = errors.New("invalid length")
+	ErrIncompatibleSchema = errors.New("incompatible schema")
+	ErrUnknownVersion     = errors.New("unknown version")
+	ErrWireType           = errors.New("unexpected wire type")
+	ErrInvalidSchema      = errors.New("invalid schema")
+	ErrChecksumMismatch   = errors.New("checksum mismatch")
+	ErrFrameTooLarge      = errors.New("frame too large")
+	ErrLimitExceeded      = errors.New("decode limit exceeded")
+	ErrNonCanonical       = errors.New("non-canonical encoding")
 )
 
 type ErrUnSufficientBytes struct {
@@ -69,3 +77,55 @@ func (e ErrCompile) Error() string {
 func NewErrCompile(reason string) ErrCompile {
 	return ErrCompile{reason: reason}
 }
+
+// ParseError locates a parse failure within a message. It wraps the cause,
+// so errors.Is(err, ErrCannotRead) and friends keep working.
+type ParseError struct {
+	// Path locates the failing value from the model, e.g.
+	// "Order.Items[3].Price". Unnamed fields are shown as #index.
+	Path string
+	// Index is the position of the failing field within the innermost model.
+	Index int
+	// Offset is the number of bytes of the message read before the failing
+	// value.
+	Offset int
+	// MessageStart is the offset of the message within the stream, and
+	// StreamOffset the offset of the failing value. Both are -1 unless
+	// parsing from an OffsetReader, such as CountingReader or BufferCursor.
+	MessageStart int
+	StreamOffset int
+	Err          error
+
+	// root is the model name heading Path.
+	root string
+}
+
+func (e *ParseError) Error() string {
+	if e.Path == "" {
+		return fmt.Sprintf("parse message (offset %d): %v", e.Offset, e.Err)
+	}
+	return fmt.Sprintf("parse %s (field %d, offset %d): %v", e.Path, e.Index, e.Offset, e.Err)
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// within prefixes the path of err with segment, turning err into a
+// ParseError unless it is one already.
+func within(segment string, offset int, err error) *ParseError {
+	pe, ok := err.(*ParseError)
+	if !ok {
+		return &ParseError{Path: segment, Index: -1, Offset: offset, Err: err}
+	}
+	pe.Path = segment + pe.Path[len(pe.root):]
+	pe.root = ""
+	return pe
+}
+
+// rooted heads the path of e with the model name root.
+func (e *ParseError) rooted(root string) *ParseError {
+	e.Path = root + e.Path
+	e.root = root
+	return e
+}
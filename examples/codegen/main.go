@@ -0,0 +1,53 @@
+package main
+
+import (
+	"bytes"
+	"log"
+	"reflect"
+	"time"
+
+	"github.com/sonirico/parco"
+)
+
+func main() {
+	builder := OrderBuilder()
+
+	log.Printf("schema:\n%s", builder.Schema())
+
+	order := Order{
+		ID:     42,
+		Status: 3,
+		Customer: Customer{
+			ID:    1 << 20,
+			Name:  "Ada",
+			Email: "ada@example.com",
+		},
+		Items: []Item{
+			{SKU: "keyboard", Quantity: 1, Price: 89.9},
+			{SKU: "cable", Quantity: 300, Price: 4.5},
+		},
+		Tags:      map[string]uint32{"priority": 1},
+		Discount:  parco.Ptr[float32](0.1),
+		Checksum:  [4]byte{0xde, 0xad, 0xbe, 0xef},
+		Notes:     []byte("leave at the door"),
+		CreatedAt: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+	}
+
+	buf := bytes.NewBuffer(nil)
+	if err := builder.Compile(order, buf); err != nil {
+		log.Fatal(err)
+	}
+
+	log.Println(parco.FormatBytes(buf.Bytes()))
+
+	parsed, err := builder.Parse(buf)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(order, parsed) {
+		log.Fatalf("not equals:\n%+v\n%+v", order, parsed)
+	}
+
+	log.Printf("%+v", parsed)
+}
@@ -0,0 +1,35 @@
+package main
+
+import "time"
+
+//go:generate go run github.com/sonirico/parco/cmd/parcogen -type Order,Item,Customer
+
+type (
+	Status uint8
+
+	Customer struct {
+		ID    uint64 `parco:"varint"`
+		Name  string `parco:"header=uint8"`
+		Email string
+	}
+
+	Item struct {
+		SKU      string  `parco:"header=uint8"`
+		Quantity uint16  `parco:"varint"`
+		Price    float64 `parco:"be"`
+	}
+
+	Order struct {
+		ID        uint32 `parco:"be"`
+		Status    Status
+		Customer  Customer
+		Items     []Item            `parco:"header=uint8"`
+		Tags      map[string]uint32 `parco:"header=varint,value=varint"`
+		Discount  *float32
+		Checksum  [4]byte
+		Notes     []byte `parco:"header=uint32"`
+		CreatedAt time.Time
+		internal  string
+		Cache     map[string]any `parco:"-"`
+	}
+)
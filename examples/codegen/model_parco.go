@@ -0,0 +1,112 @@
+// Code generated by parcogen. DO NOT EDIT.
+
+package main
+
+import (
+	"encoding/binary"
+	"time"
+
+	"github.com/sonirico/parco"
+)
+
+// OrderBuilder returns the parco builder for Order.
+func OrderBuilder() parco.ModelBuilder[Order] {
+	return parco.Builder[Order](parco.ObjectFactory[Order]()).
+		UInt32(
+			binary.BigEndian,
+			func(m *Order) uint32 { return m.ID },
+			func(m *Order, v uint32) { m.ID = v },
+		).
+		Named("ID").
+		UInt8(
+			func(m *Order) uint8 { return uint8(m.Status) },
+			func(m *Order, v uint8) { m.Status = Status(v) },
+		).
+		Named("Status").
+		Struct(parco.StructField[Order, Customer](
+			func(m *Order) Customer { return m.Customer },
+			func(m *Order, v Customer) { m.Customer = v },
+			CustomerBuilder(),
+		)).
+		Named("Customer").
+		Slice(parco.SliceField[Order, Item](
+			parco.UInt8Header(),
+			parco.Struct[Item](ItemBuilder()),
+			func(m *Order, v parco.SliceView[Item]) { m.Items = v },
+			func(m *Order) parco.SliceView[Item] { return m.Items },
+		)).
+		Named("Items").
+		Map(parco.MapField[Order, string, uint32](
+			parco.VarUIntHeader(),
+			parco.NewVarcharType(parco.UInt16HeaderLE()),
+			parco.VarUInt32(),
+			func(m *Order, v map[string]uint32) { m.Tags = v },
+			func(m *Order) map[string]uint32 { return m.Tags },
+		)).
+		Named("Tags").
+		Option(parco.OptionField[Order, float32](
+			parco.Float32LE(),
+			func(m *Order, v *float32) { m.Discount = v },
+			func(m *Order) *float32 { return m.Discount },
+		)).
+		Named("Discount").
+		Array(parco.ArrayField[Order, byte](
+			4,
+			parco.UInt8(),
+			func(m *Order, v parco.SliceView[byte]) { copy(m.Checksum[:], v) },
+			func(m *Order) parco.SliceView[byte] { return m.Checksum[:] },
+		)).
+		Named("Checksum").
+		Field(parco.FixedField[Order, []byte]{
+			Type:   parco.Blob(parco.UInt32LEHeader()),
+			Getter: func(m *Order) []byte { return m.Notes },
+			Setter: func(m *Order, v []byte) { m.Notes = v },
+		}).
+		Named("Notes").
+		TimeUTC(
+			func(m *Order) time.Time { return m.CreatedAt },
+			func(m *Order, v time.Time) { m.CreatedAt = v },
+		).
+		Named("CreatedAt")
+}
+
+// ItemBuilder returns the parco builder for Item.
+func ItemBuilder() parco.ModelBuilder[Item] {
+	return parco.Builder[Item](parco.ObjectFactory[Item]()).
+		SmallVarchar(
+			func(m *Item) string { return m.SKU },
+			func(m *Item, v string) { m.SKU = v },
+		).
+		Named("SKU").
+		VarUInt16(
+			func(m *Item) uint16 { return m.Quantity },
+			func(m *Item, v uint16) { m.Quantity = v },
+		).
+		Named("Quantity").
+		Float64(
+			binary.BigEndian,
+			func(m *Item) float64 { return m.Price },
+			func(m *Item, v float64) { m.Price = v },
+		).
+		Named("Price")
+}
+
+// CustomerBuilder returns the parco builder for Customer.
+func CustomerBuilder() parco.ModelBuilder[Customer] {
+	return parco.Builder[Customer](parco.ObjectFactory[Customer]()).
+		VarUInt64(
+			func(m *Customer) uint64 { return m.ID },
+			func(m *Customer, v uint64) { m.ID = v },
+		).
+		Named("ID").
+		SmallVarchar(
+			func(m *Customer) string { return m.Name },
+			func(m *Customer, v string) { m.Name = v },
+		).
+		Named("Name").
+		Varchar(
+			func(m *Customer) string { return m.Email },
+			func(m *Customer, v string) { m.Email = v },
+		).
+		Named("Email")
+}
@@ -1,6 +1,9 @@
 package parco
 
-import "io"
+import (
+	"io"
+	"strconv"
+)
 
 type (
 	Setter[T, U any] func(*T, U)
@@ -11,4 +14,30 @@ type (
 		Parse(*T, io.Reader) error
 		Compile(*T, io.Writer) error
 	}
+
+	// renamer is implemented by the built-in fields so that builders can name
+	// them after registration. withID returns a copy of the field.
+	renamer interface {
+		withID(id string) any
+	}
 )
+
+// renamed returns f named id, or f itself if it cannot be renamed.
+func renamed[F any](f F, id string) F {
+	r, ok := any(f).(renamer)
+	if !ok {
+		return f
+	}
+	if named, ok := r.withID(id).(F); ok {
+		return named
+	}
+	return f
+}
+
+// fieldName returns the name of f, or #i for unnamed fields.
+func fieldName(f any, i int) string {
+	if f, ok := f.(interface{ ID() string }); ok && f.ID() != "" {
+		return f.ID()
+	}
+	return "#" + strconv.Itoa(i)
+}
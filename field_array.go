@@ -16,6 +16,15 @@ func (s BasicArrayField[T, U]) ID() string {
 	return s.id
 }
 
+func (s BasicArrayField[T, U]) Schema() Schema {
+	return s.inner.Schema().named(s.id)
+}
+
+func (s BasicArrayField[T, U]) withID(id string) any {
+	s.id = id
+	return s
+}
+
 func (s BasicArrayField[T, U]) Parse(item *T, r io.Reader) error {
 	values, err := s.inner.Parse(r)
 	if err != nil {
@@ -30,6 +39,14 @@ func (s BasicArrayField[T, U]) Compile(item *T, w io.Writer) error {
 	return s.inner.Compile(value, w)
 }
 
+func (s BasicArrayField[T, U]) Size(item *T) (int, error) {
+	values := s.getter(item)
+	if len(values) != s.inner.length {
+		return 0, ErrInvalidLength
+	}
+	return sizeElems(s.inner.inner, values)
+}
+
 func ArrayField[T, U any](
 	length int,
 	inner Type[U],
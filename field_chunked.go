@@ -0,0 +1,59 @@
+package parco
+
+import "io"
+
+type (
+	BasicChunkedField[T, U any] struct {
+		id     string
+		inner  ChunkedType[U]
+		setter Setter[T, SliceView[U]]
+		getter Getter[T, SliceView[U]]
+	}
+)
+
+func (s BasicChunkedField[T, U]) ID() string {
+	return s.id
+}
+
+func (s BasicChunkedField[T, U]) Schema() Schema {
+	return s.inner.Schema().named(s.id)
+}
+
+func (s BasicChunkedField[T, U]) withID(id string) any {
+	s.id = id
+	return s
+}
+
+func (s BasicChunkedField[T, U]) Parse(item *T, r io.Reader) error {
+	values, err := s.inner.Parse(r)
+	if err != nil {
+		return err
+	}
+	s.setter(item, values.Unwrap())
+	return nil
+}
+
+func (s BasicChunkedField[T, U]) Compile(item *T, w io.Writer) error {
+	return s.inner.Compile(s.getter(item), w)
+}
+
+func (s BasicChunkedField[T, U]) Size(item *T) (int, error) {
+	return s.inner.sizeValues(s.getter(item))
+}
+
+// ChunkedField is a slice field written in chunks of up to chunk elements.
+// See Chunked. To write elements as they are produced, see
+// StreamSliceFieldChunked.
+func ChunkedField[T, U any](
+	header IntType,
+	chunk int,
+	inner Type[U],
+	setter Setter[T, SliceView[U]],
+	getter Getter[T, SliceView[U]],
+) Field[T, U] {
+	return BasicChunkedField[T, U]{
+		inner:  Chunked[U](header, chunk, inner),
+		setter: setter,
+		getter: getter,
+	}
+}
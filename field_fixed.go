@@ -16,6 +16,15 @@ func (s FixedField[T, U]) ID() string {
 	return s.Id
 }
 
+func (s FixedField[T, U]) Schema() Schema {
+	return Describe(s.Type).named(s.Id)
+}
+
+func (s FixedField[T, U]) withID(id string) any {
+	s.Id = id
+	return s
+}
+
 func (s FixedField[T, U]) Parse(item *T, r io.Reader) error {
 	value, err := s.Type.Parse(r)
 	if err != nil {
@@ -31,6 +40,10 @@ func (s FixedField[T, U]) Compile(item *T, w io.Writer) (err error) {
 	return s.Type.Compile(value, w)
 }
 
+func (s FixedField[T, U]) Size(item *T) (int, error) {
+	return sizeOf(s.Type, s.Getter(item))
+}
+
 func BoolField[T any](
 	tp Type[bool],
 	getter Getter[T, bool],
@@ -291,6 +304,32 @@ func IntFieldSetter[T any](
 	return IntField[T](tp, nil, setter)
 }
 
+func UIntField[T any](
+	tp Type[uint],
+	getter Getter[T, uint],
+	setter Setter[T, uint],
+) Field[T, uint] {
+	return FixedField[T, uint]{
+		Type:   tp,
+		Setter: setter,
+		Getter: getter,
+	}
+}
+
+func UIntFieldGetter[T any](
+	tp Type[uint],
+	getter Getter[T, uint],
+) Field[T, uint] {
+	return UIntField[T](tp, getter, nil)
+}
+
+func UIntFieldSetter[T any](
+	tp Type[uint],
+	setter Setter[T, uint],
+) Field[T, uint] {
+	return UIntField[T](tp, nil, setter)
+}
+
 func Float32Field[T any](
 	tp Type[float32],
 	getter Getter[T, float32],
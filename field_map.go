@@ -16,6 +16,15 @@ func (s mapField[T, K, V]) ID() string {
 	return s.id
 }
 
+func (s mapField[T, K, V]) Schema() Schema {
+	return s.inner.Schema().named(s.id)
+}
+
+func (s mapField[T, K, V]) withID(id string) any {
+	s.id = id
+	return s
+}
+
 func (s mapField[T, K, V]) Parse(item *T, r io.Reader) error {
 	values, err := s.inner.Parse(r)
 	if err != nil {
@@ -30,6 +39,10 @@ func (s mapField[T, K, V]) Compile(item *T, w io.Writer) error {
 	return s.inner.Compile(value, w)
 }
 
+func (s mapField[T, K, V]) Size(item *T) (int, error) {
+	return s.inner.Size(s.getter(item))
+}
+
 func MapField[T any, K comparable, V any](
 	header IntType,
 	keyType Type[K],
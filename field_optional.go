@@ -13,6 +13,15 @@ func (s OptionalField[T, U]) ID() string {
 	return s.id
 }
 
+func (s OptionalField[T, U]) Schema() Schema {
+	return s.inner.Schema().named(s.id)
+}
+
+func (s OptionalField[T, U]) withID(id string) any {
+	s.id = id
+	return s
+}
+
 func (s OptionalField[T, U]) Parse(item *T, r io.Reader) error {
 	value, err := s.inner.Parse(r)
 	if err != nil {
@@ -28,6 +37,10 @@ func (s OptionalField[T, U]) Compile(item *T, w io.Writer) (err error) {
 	return s.inner.Compile(value, w)
 }
 
+func (s OptionalField[T, U]) Size(item *T) (int, error) {
+	return s.inner.Size(s.getter(item))
+}
+
 func OptionField[T, U any](
 	tp Type[U],
 	setter Setter[T, *U],
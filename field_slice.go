@@ -16,6 +16,15 @@ func (s BasicSliceField[T, U]) ID() string {
 	return s.id
 }
 
+func (s BasicSliceField[T, U]) Schema() Schema {
+	return s.inner.Schema().named(s.id)
+}
+
+func (s BasicSliceField[T, U]) withID(id string) any {
+	s.id = id
+	return s
+}
+
 func (s BasicSliceField[T, U]) Parse(item *T, r io.Reader) error {
 	values, err := s.inner.Parse(r)
 	if err != nil {
@@ -30,6 +39,10 @@ func (s BasicSliceField[T, U]) Compile(item *T, w io.Writer) error {
 	return s.inner.Compile(value, w)
 }
 
+func (s BasicSliceField[T, U]) Size(item *T) (int, error) {
+	return s.inner.sizeValues(s.getter(item))
+}
+
 func SliceField[T, U any](
 	header IntType,
 	inner Type[U],
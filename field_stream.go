@@ -0,0 +1,250 @@
+package parco
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+)
+
+type (
+	// Producer emits the elements of a streamed slice of item, in order.
+	// Errors returned by emit must be returned as is.
+	Producer[T, U any] func(item *T, emit func(U) error) error
+
+	// Consumer receives the elements of a streamed slice of item as they are
+	// parsed. Returning an error stops parsing.
+	Consumer[T, U any] func(item *T, value U) error
+
+	// StreamSliceField is a slice field whose elements are never held
+	// together: they are compiled as a Producer emits them and handed to a
+	// Consumer as they are parsed, so that memory stays bounded whatever their
+	// count. It is written like a SliceField, so either peer may use one, or
+	// like a ChunkedField if chunked.
+	StreamSliceField[T, U any] struct {
+		id     string
+		header IntType
+		inner  Type[U]
+		// length, if set, tells the element count ahead, nil if deferred.
+		length  Getter[T, int]
+		produce Producer[T, U]
+		consume Consumer[T, U]
+		// chunked, if set, writes the elements in chunks.
+		chunked *ChunkedType[U]
+	}
+)
+
+func (s StreamSliceField[T, U]) ID() string {
+	return s.id
+}
+
+func (s StreamSliceField[T, U]) Schema() Schema {
+	if s.chunked != nil {
+		return s.chunked.Schema().named(s.id)
+	}
+	return Slice[U](s.header, s.inner).Schema().named(s.id)
+}
+
+func (s StreamSliceField[T, U]) withID(id string) any {
+	s.id = id
+	return s
+}
+
+func (s StreamSliceField[T, U]) Parse(item *T, r io.Reader) error {
+	if s.chunked != nil {
+		return s.chunked.parseEach(r, false, func(value U) error {
+			return s.consume(item, value)
+		})
+	}
+
+	length, err := s.header.Parse(r)
+	if err != nil {
+		return err
+	}
+	// Elements are not kept, so the count is only capped by DecodeLimits.
+	if err = checkLength(r, length, 0); err != nil {
+		return err
+	}
+
+	for i := range length {
+		offset := readOffset(r)
+		value, err := s.inner.Parse(r)
+		if err == nil {
+			err = s.consume(item, value)
+		}
+		if err != nil {
+			return within("["+strconv.Itoa(i)+"]", offset, err)
+		}
+	}
+	return nil
+}
+
+// Compile writes the elements as they are produced. With a count known
+// ahead, or in chunks, they go out to the underlying writer as they are
+// compiled, along with the message before them, unless the message must be
+// held whole, e.g. to prefix it with its length or follow it with its
+// checksum. On failure, part of the message may have been written. With a
+// deferred count, the header is written once they all are, in front of
+// them.
+func (s StreamSliceField[T, U]) Compile(item *T, w io.Writer) error {
+	if s.chunked != nil {
+		return s.chunked.compileEach(func(emit func(U) error) error {
+			return s.produce(item, emit)
+		}, w)
+	}
+	if s.length != nil {
+		return s.compileCounted(item, w)
+	}
+
+	cw, ok := w.(*compileWriter)
+	if !ok {
+		cw = getCompileWriter(w)
+		defer putCompileWriter(cw)
+		if err := s.Compile(item, cw); err != nil {
+			return err
+		}
+		return cw.flush()
+	}
+
+	start := cw.pin()
+	defer cw.unpin()
+	length := 0
+	err := s.produce(item, func(value U) error {
+		length++
+		return s.inner.Compile(value, cw)
+	})
+	if err != nil {
+		return err
+	}
+
+	if headerCapacity(s.header) < length {
+		return ErrOverflow
+	}
+	mid := len(cw.buf)
+	if err = s.header.Compile(length, cw); err != nil {
+		return err
+	}
+	cw.moveFront(start, mid)
+	return nil
+}
+
+// compileCounted writes the header ahead, then checks that the producer
+// kept its word.
+func (s StreamSliceField[T, U]) compileCounted(item *T, w io.Writer) error {
+	want := s.length(item)
+	if err := s.header.Compile(want, w); err != nil {
+		return err
+	}
+
+	cw, _ := w.(*compileWriter)
+	have := 0
+	err := s.produce(item, func(value U) error {
+		if have++; have > want {
+			return fmt.Errorf("%w: want %d elements, have more", ErrInvalidLength, want)
+		}
+		if cw != nil {
+			// Flush what precedes the element, header included.
+			if err := cw.stream(); err != nil {
+				return err
+			}
+		}
+		return s.inner.Compile(value, w)
+	})
+	if err != nil {
+		return err
+	}
+	if have != want {
+		return fmt.Errorf("%w: want %d elements, have %d", ErrInvalidLength, want, have)
+	}
+	return nil
+}
+
+// Size runs the producer once more to size the elements.
+func (s StreamSliceField[T, U]) Size(item *T) (int, error) {
+	length, size := 0, 0
+	err := s.produce(item, func(value U) error {
+		n, err := sizeOf(s.inner, value)
+		length++
+		size += n
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	if s.chunked != nil {
+		headers, err := s.chunked.headersSize(length)
+		return headers + size, err
+	}
+
+	header, err := sizeOf(s.header, length)
+	return header + size, err
+}
+
+// StreamSliceFieldDeferred streams a slice whose element count is only known
+// once produced. Compiling it buffers the encoded elements, as compiling any
+// message does, but never holds them as values.
+func StreamSliceFieldDeferred[T, U any](
+	header IntType,
+	inner Type[U],
+	consume Consumer[T, U],
+	produce Producer[T, U],
+) Field[T, U] {
+	return StreamSliceField[T, U]{
+		header:  header,
+		inner:   inner,
+		produce: produce,
+		consume: consume,
+	}
+}
+
+// StreamSliceFieldCounted streams a slice whose element count, told by
+// length, is known ahead. Producing another count fails with
+// ErrInvalidLength.
+func StreamSliceFieldCounted[T, U any](
+	header IntType,
+	inner Type[U],
+	length Getter[T, int],
+	consume Consumer[T, U],
+	produce Producer[T, U],
+) Field[T, U] {
+	return StreamSliceField[T, U]{
+		header:  header,
+		inner:   inner,
+		length:  length,
+		produce: produce,
+		consume: consume,
+	}
+}
+
+// StreamSliceFieldChunked streams a slice in chunks of up to chunk elements,
+// written like a ChunkedField, so the element count need not be known ahead.
+func StreamSliceFieldChunked[T, U any](
+	header IntType,
+	chunk int,
+	inner Type[U],
+	consume Consumer[T, U],
+	produce Producer[T, U],
+) Field[T, U] {
+	chunked := Chunked[U](header, chunk, inner)
+	return StreamSliceField[T, U]{
+		header:  header,
+		inner:   inner,
+		produce: produce,
+		consume: consume,
+		chunked: &chunked,
+	}
+}
+
+// ChanProducer emits the values received from the channel of item until it
+// is closed. A channel is drained once only, so models holding one cannot
+// be sized, nor compiled twice.
+func ChanProducer[T, U any](getter Getter[T, <-chan U]) Producer[T, U] {
+	return func(item *T, emit func(U) error) error {
+		for value := range getter(item) {
+			if err := emit(value); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
@@ -0,0 +1,170 @@
+package parco
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type export struct {
+	Rows  int
+	Sum   uint32
+	Items chan uint32
+}
+
+// exportBuilder streams Rows elements, from 1 on, and sums them on parse.
+func exportBuilder(field func(Consumer[export, uint32], Producer[export, uint32]) Field[export, uint32]) ModelBuilder[export] {
+	return Builder[export](ObjectFactory[export]()).
+		Slice(field(
+			func(e *export, v uint32) error {
+				e.Rows++
+				e.Sum += v
+				return nil
+			},
+			func(e *export, emit func(uint32) error) error {
+				for i := range e.Rows {
+					if err := emit(uint32(i + 1)); err != nil {
+						return err
+					}
+				}
+				return nil
+			},
+		)).Named("Rows")
+}
+
+func TestStreamSliceField(t *testing.T) {
+	sliceBuilder := Builder[[]uint32](ObjectFactory[[]uint32]()).
+		Slice(SliceField[[]uint32, uint32](
+			VarUIntHeader(),
+			UInt32LE(),
+			func(s *[]uint32, v SliceView[uint32]) { *s = v },
+			func(s *[]uint32) SliceView[uint32] { return *s },
+		))
+	want, err := sliceBuilder.Append(nil, createSlice(uint32(0), 300))
+	require.NoError(t, err)
+
+	for name, builder := range map[string]ModelBuilder[export]{
+		"deferred": exportBuilder(func(c Consumer[export, uint32], p Producer[export, uint32]) Field[export, uint32] {
+			return StreamSliceFieldDeferred(VarUIntHeader(), UInt32LE(), c, p)
+		}),
+		"counted": exportBuilder(func(c Consumer[export, uint32], p Producer[export, uint32]) Field[export, uint32] {
+			return StreamSliceFieldCounted(VarUIntHeader(), UInt32LE(), func(e *export) int { return e.Rows }, c, p)
+		}),
+	} {
+		t.Run(name, func(t *testing.T) {
+			data, err := builder.Append(nil, export{Rows: 300})
+			require.NoError(t, err)
+			// Written like a slice field.
+			require.Len(t, data, len(want))
+			assert.Equal(t, want[:2], data[:2])
+
+			size, err := builder.Size(export{Rows: 300})
+			require.NoError(t, err)
+			assert.Equal(t, len(data), size)
+
+			parsed, err := builder.ParseBytes(data)
+			require.NoError(t, err)
+			assert.Equal(t, export{Rows: 300, Sum: 300 * 301 / 2}, parsed)
+
+			values, err := sliceBuilder.ParseBytes(data)
+			require.NoError(t, err)
+			assert.Len(t, values, 300)
+			assert.Equal(t, uint32(300), values[299])
+		})
+	}
+}
+
+func TestStreamSliceField_Failures(t *testing.T) {
+	lying := exportBuilder(func(c Consumer[export, uint32], p Producer[export, uint32]) Field[export, uint32] {
+		return StreamSliceFieldCounted(UInt8Header(), UInt32LE(), func(*export) int { return 2 }, c, p)
+	})
+	_, err := lying.Append(nil, export{Rows: 3})
+	assert.ErrorIs(t, err, ErrInvalidLength)
+	_, err = lying.Append(nil, export{Rows: 1})
+	assert.ErrorIs(t, err, ErrInvalidLength)
+
+	overflow := exportBuilder(func(c Consumer[export, uint32], p Producer[export, uint32]) Field[export, uint32] {
+		return StreamSliceFieldDeferred(UInt8Header(), UInt32LE(), c, p)
+	})
+	_, err = overflow.Append(nil, export{Rows: 256})
+	assert.ErrorIs(t, err, ErrOverflow)
+
+	// Consumer failures stop parsing, located at the element.
+	errFull := errors.New("full")
+	full := Builder[export](ObjectFactory[export]()).
+		Slice(StreamSliceFieldDeferred(UInt8Header(), UInt32LE(),
+			func(e *export, v uint32) error {
+				if e.Rows++; e.Rows > 1 {
+					return errFull
+				}
+				return nil
+			},
+			nil,
+		)).Named("Rows")
+	_, err = full.ParseBytes([]byte{2, 1, 0, 0, 0, 2, 0, 0, 0})
+	assert.ErrorIs(t, err, errFull)
+	var parseErr *ParseError
+	require.ErrorAs(t, err, &parseErr)
+	assert.Equal(t, "export.Rows[1]", parseErr.Path)
+}
+
+func TestChanProducer(t *testing.T) {
+	builder := Builder[export](ObjectFactory[export]()).
+		Slice(StreamSliceFieldDeferred(UInt8Header(), UInt32LE(),
+			func(e *export, v uint32) error {
+				e.Sum += v
+				return nil
+			},
+			ChanProducer(func(e *export) <-chan uint32 { return e.Items }),
+		))
+
+	items := make(chan uint32)
+	go func() {
+		defer close(items)
+		for i := range 3 {
+			items <- uint32(i + 1)
+		}
+	}()
+
+	data, err := builder.Append(nil, export{Items: items})
+	require.NoError(t, err)
+	assert.Equal(t, []byte{3, 1, 0, 0, 0, 2, 0, 0, 0, 3, 0, 0, 0}, data)
+
+	parsed, err := builder.ParseBytes(data)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(6), parsed.Sum)
+}
+
+func TestStreamSliceField_WritesThrough(t *testing.T) {
+	w := bytes.NewBuffer(nil)
+	var written []int
+	produce := func(e *export, emit func(uint32) error) error {
+		for i := range e.Rows {
+			written = append(written, w.Len())
+			if err := emit(uint32(i + 1)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	counted := Builder[export](ObjectFactory[export]()).
+		Slice(StreamSliceFieldCounted(UInt8Header(), UInt32LE(), func(e *export) int { return e.Rows }, nil, produce))
+	require.NoError(t, counted.Compile(export{Rows: 3}, w))
+	assert.Equal(t, []byte{3, 1, 0, 0, 0, 2, 0, 0, 0, 3, 0, 0, 0}, w.Bytes())
+	// The header, then every element, reach the writer as they are produced.
+	assert.Equal(t, []int{0, 1, 5}, written)
+
+	// Extensible messages are written whole, once their length is known.
+	w.Reset()
+	written = nil
+	extensible := Builder[export](ObjectFactory[export]()).
+		Extensible(UInt8Header()).
+		Slice(StreamSliceFieldCounted(UInt8Header(), UInt32LE(), func(e *export) int { return e.Rows }, nil, produce))
+	require.NoError(t, extensible.Compile(export{Rows: 3}, w))
+	assert.Equal(t, []int{0, 0, 0}, written)
+	assert.Equal(t, 14, w.Len())
+}
@@ -20,6 +20,15 @@ func (s structField[T, U]) ID() string {
 	return s.id
 }
 
+func (s structField[T, U]) Schema() Schema {
+	return s.inner.Schema().named(s.id)
+}
+
+func (s structField[T, U]) withID(id string) any {
+	s.id = id
+	return s
+}
+
 func (s structField[T, U]) Parse(item *T, r io.Reader) error {
 	model, err := s.inner.Parse(r)
 	if err != nil {
@@ -34,6 +43,10 @@ func (s structField[T, U]) Compile(item *T, w io.Writer) error {
 	return s.inner.Compile(value, w)
 }
 
+func (s structField[T, U]) Size(item *T) (int, error) {
+	return s.inner.Size(s.getter(item))
+}
+
 func newStructField[T, U any](
 	setter Setter[T, U],
 	getter Getter[T, U],
@@ -0,0 +1,197 @@
+package parco
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+type (
+	// FrameWriter writes messages to a stream as frames: each one preceded by
+	// its byte length and optionally followed by its checksum, so that a
+	// FrameReader can skip those it fails to parse and go on with the next.
+	FrameWriter struct {
+		w        io.Writer
+		header   IntType
+		maxSize  int
+		checksum Checksum
+		// cw buffers the frame being written, compiled in place.
+		cw compileWriter
+	}
+
+	// FrameReader reads the frames written by a FrameWriter, configured alike.
+	FrameReader struct {
+		r        *CountingReader
+		header   IntType
+		maxSize  int
+		checksum Checksum
+		buf      []byte
+		frame    BufferCursor
+	}
+)
+
+// NewFrameWriter writes frames to w, their lengths with header, e.g.
+// UInt32LEHeader() or VarUIntHeader().
+func NewFrameWriter(w io.Writer, header IntType) *FrameWriter {
+	return &FrameWriter{w: w, header: header, maxSize: MaxReasonableVarSize}
+}
+
+// MaxSize rejects frames longer than size bytes, checksum excluded.
+func (f *FrameWriter) MaxSize(size int) *FrameWriter {
+	f.maxSize = size
+	return f
+}
+
+// Checksum follows every frame with the checksum of its bytes.
+func (f *FrameWriter) Checksum(sum Checksum) *FrameWriter {
+	f.checksum = sum
+	return f
+}
+
+// WriteFrame writes payload as a frame.
+func (f *FrameWriter) WriteFrame(payload []byte) error {
+	f.cw.buf = append(f.cw.buf[:0], payload...)
+	return f.flush()
+}
+
+// EncodeFrame compiles value with c, e.g. a ModelBuilder, and writes it as a
+// frame. Messages are compiled straight into the frame.
+func EncodeFrame[T any](f *FrameWriter, c CompilerType[T], value T) error {
+	f.cw.buf = f.cw.buf[:0]
+	if err := c.Compile(value, &f.cw); err != nil {
+		return err
+	}
+	return f.flush()
+}
+
+// EncodeFrameAny compiles item, preceded by its type id, with the multi
+// builder b and writes it as a frame.
+func EncodeFrameAny[T comparable](f *FrameWriter, b *ModelMultiBuilder[T], id T, item any) error {
+	f.cw.buf = f.cw.buf[:0]
+	if err := b.append(&f.cw, id, item); err != nil {
+		return err
+	}
+	return f.flush()
+}
+
+// flush writes the frame buffered so far in a single Write.
+func (f *FrameWriter) flush() error {
+	size := len(f.cw.buf)
+	if size > f.maxSize {
+		return fmt.Errorf("%d bytes: %w", size, ErrFrameTooLarge)
+	}
+
+	if err := f.cw.prefixLength(0, f.header); err != nil {
+		return err
+	}
+
+	if f.checksum != nil {
+		payload := f.cw.buf[len(f.cw.buf)-size:]
+		f.cw.buf = binary.LittleEndian.AppendUint32(f.cw.buf, f.checksum.Sum(payload))
+	}
+
+	written, err := f.w.Write(f.cw.buf)
+	if err != nil {
+		return err
+	}
+	if written != len(f.cw.buf) {
+		return ErrCannotWrite
+	}
+	return nil
+}
+
+// NewFrameReader reads frames from r, their lengths with header.
+func NewFrameReader(r io.Reader, header IntType) *FrameReader {
+	return &FrameReader{r: NewCountingReader(r), header: header, maxSize: MaxReasonableVarSize}
+}
+
+// MaxSize rejects frames longer than size bytes, checksum excluded, before
+// reading them.
+func (f *FrameReader) MaxSize(size int) *FrameReader {
+	f.maxSize = size
+	return f
+}
+
+// Checksum verifies every frame against the checksum following it.
+func (f *FrameReader) Checksum(sum Checksum) *FrameReader {
+	f.checksum = sum
+	return f
+}
+
+// Offset returns the number of bytes of the stream read so far.
+func (f *FrameReader) Offset() int {
+	return f.r.Offset()
+}
+
+// ReadFrame reads the next frame and returns a cursor over its payload,
+// valid until the next call. It returns io.EOF at the end of the stream.
+//
+// Frames failing their checksum are consumed whole and reported as
+// ErrChecksumMismatch, so that the next call reads the following frame.
+// Only frames longer than the max size, reported as ErrFrameTooLarge, or
+// cut short leave the stream out of sync.
+func (f *FrameReader) ReadFrame() (*BufferCursor, error) {
+	start := f.r.Offset()
+
+	size, err := f.header.Parse(f.r)
+	if err != nil {
+		if atEOF(f.r, start, err) {
+			return nil, io.EOF
+		}
+		return nil, f.fail(start, err)
+	}
+
+	if size < 0 || size > f.maxSize {
+		return nil, f.fail(start, fmt.Errorf("%d bytes: %w", size, ErrFrameTooLarge))
+	}
+
+	n := size
+	if f.checksum != nil {
+		n += checksumByteLength
+	}
+
+	if n <= cap(f.buf) {
+		f.buf = f.buf[:n]
+		err = readFull(f.r, f.buf)
+	} else {
+		// Grow as data arrives: a corrupted length must not allocate up front.
+		var data []byte
+		if data, err = readChunked(f.r, n, DefaultPoolAnySize); err == nil {
+			f.buf = data
+		}
+	}
+	if err != nil {
+		return nil, f.fail(start, err)
+	}
+
+	payload := f.buf[:size]
+	if f.checksum != nil && f.checksum.Sum(payload) != binary.LittleEndian.Uint32(f.buf[size:]) {
+		return nil, f.fail(start, ErrChecksumMismatch)
+	}
+
+	f.frame = NewBufferCursor(payload, 0)
+	return &f.frame, nil
+}
+
+func (f *FrameReader) fail(start int, err error) error {
+	return fmt.Errorf("frame at offset %d: %w", start, err)
+}
+
+// DecodeFrame reads the next frame and parses it with p, e.g. a
+// ModelBuilder. The frame is consumed whole whether or not it parses.
+func DecodeFrame[T any](f *FrameReader, p ParserType[T]) (res T, err error) {
+	frame, err := f.ReadFrame()
+	if err != nil {
+		return res, err
+	}
+	return p.Parse(frame)
+}
+
+// DecodeFrameAny reads the next frame and parses it with the multi builder b.
+func DecodeFrameAny[T comparable](f *FrameReader, b *ModelMultiBuilder[T]) (id T, res any, err error) {
+	frame, err := f.ReadFrame()
+	if err != nil {
+		return id, nil, err
+	}
+	return b.Parse(frame)
+}
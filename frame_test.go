@@ -0,0 +1,100 @@
+package parco
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFrame_RoundTrip(t *testing.T) {
+	buf := bytes.NewBuffer(nil)
+	w := NewFrameWriter(buf, UInt32LEHeader()).Checksum(CRC32C())
+
+	require.NoError(t, EncodeFrame[parseItem](w, parseItemBuilder(), parseItem{"ab", 1}))
+	require.NoError(t, EncodeFrameAny(w, multiBuilder(), multiOrderType, parseOrder{ID: 3}))
+	require.NoError(t, w.WriteFrame([]byte("raw")))
+
+	// Length, SKU header and bytes, price, checksum.
+	assert.Equal(t, []byte{7, 0, 0, 0, 2, 'a', 'b', 1, 0, 0, 0}, buf.Bytes()[:11])
+
+	r := NewFrameReader(buf, UInt32LEHeader()).Checksum(CRC32C())
+
+	item, err := DecodeFrame[parseItem](r, parseItemBuilder())
+	require.NoError(t, err)
+	assert.Equal(t, parseItem{"ab", 1}, item)
+
+	id, order, err := DecodeFrameAny(r, multiBuilder())
+	require.NoError(t, err)
+	assert.Equal(t, multiOrderType, id)
+	assert.Equal(t, parseOrder{ID: 3, Items: []parseItem{}, Labels: map[string]uint16{}}, order)
+
+	frame, err := r.ReadFrame()
+	require.NoError(t, err)
+	raw := make([]byte, 3)
+	_, err = frame.Read(raw)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("raw"), raw)
+
+	_, err = r.ReadFrame()
+	assert.ErrorIs(t, err, io.EOF)
+}
+
+func TestFrameReader_SkipsBadFrames(t *testing.T) {
+	buf := bytes.NewBuffer(nil)
+	w := NewFrameWriter(buf, VarUIntHeader()).Checksum(CRC32())
+	for _, item := range []parseItem{{"ab", 1}, {"cd", 2}, {"ef", 3}} {
+		require.NoError(t, EncodeFrame[parseItem](w, parseItemBuilder(), item))
+	}
+
+	data := buf.Bytes()
+	frame := len(data) / 3
+	// Corrupt the price of the first item, and the SKU length of the second.
+	data[frame-5] = 9
+	data[frame+1] = 200
+
+	r := NewFrameReader(bytes.NewReader(data), VarUIntHeader()).Checksum(CRC32())
+
+	_, err := DecodeFrame[parseItem](r, parseItemBuilder())
+	assert.ErrorIs(t, err, ErrChecksumMismatch)
+	assert.EqualError(t, err, "frame at offset 0: checksum mismatch")
+
+	_, err = DecodeFrame[parseItem](r, parseItemBuilder())
+	assert.ErrorIs(t, err, ErrChecksumMismatch)
+
+	item, err := DecodeFrame[parseItem](r, parseItemBuilder())
+	require.NoError(t, err)
+	assert.Equal(t, parseItem{"ef", 3}, item)
+	assert.Equal(t, len(data), r.Offset())
+
+	// Without checksums, the bad frame fails to parse and is skipped all the same.
+	buf.Reset()
+	w = NewFrameWriter(buf, VarUIntHeader())
+	require.NoError(t, w.WriteFrame([]byte{200, 'x'}))
+	require.NoError(t, EncodeFrame[parseItem](w, parseItemBuilder(), parseItem{"ok", 1}))
+
+	r = NewFrameReader(buf, VarUIntHeader())
+	_, err = DecodeFrame[parseItem](r, parseItemBuilder())
+	assert.ErrorIs(t, err, ErrCannotRead)
+	item, err = DecodeFrame[parseItem](r, parseItemBuilder())
+	require.NoError(t, err)
+	assert.Equal(t, "ok", item.SKU)
+}
+
+func TestFrame_MaxSize(t *testing.T) {
+	buf := bytes.NewBuffer(nil)
+	w := NewFrameWriter(buf, UInt8Header()).MaxSize(4)
+	assert.ErrorIs(t, w.WriteFrame([]byte("12345")), ErrFrameTooLarge)
+	assert.Zero(t, buf.Len())
+	assert.ErrorIs(t, NewFrameWriter(buf, UInt8Header()).WriteFrame(make([]byte, 256)), ErrOverflow)
+
+	require.NoError(t, NewFrameWriter(buf, UInt8Header()).WriteFrame([]byte("12345")))
+	_, err := NewFrameReader(buf, UInt8Header()).MaxSize(4).ReadFrame()
+	assert.ErrorIs(t, err, ErrFrameTooLarge)
+
+	// Cut short.
+	_, err = NewFrameReader(bytes.NewReader([]byte{3, 'a'}), UInt8Header()).ReadFrame()
+	assert.ErrorIs(t, err, ErrCannotRead)
+}
@@ -0,0 +1,634 @@
+package parco
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type (
+	// jsonObject is a JSON object read with its members in document order.
+	jsonObject []jsonMember
+
+	jsonMember struct {
+		key   string
+		value any
+	}
+
+	// jsonMessage is the JSON form of a message of a multi-model stream.
+	jsonMessage[T comparable] struct {
+		Type  T               `json:"type"`
+		Value json.RawMessage `json:"value"`
+	}
+)
+
+var errTrailingJSON = errors.New("invalid JSON: trailing data")
+
+// ToJSON converts data, a message of the model described by d, to JSON.
+// Like Explain, it only needs the schema, so models must be described by
+// built-in types: data is parsed by the types Dynamic builds, within the
+// DecodeLimits of d if any. Structs become objects with their fields in wire order,
+// unnamed fields keyed by #index; options are null when absent; bytes are
+// base64 strings; times are RFC 3339 strings, followed by a space and the
+// location name for times carrying their location. Map keys are rendered
+// as strings and sorted.
+func ToJSON(d Describer, data []byte) ([]byte, error) {
+	cursor := NewBufferCursor(data, 0)
+	buf := bytes.NewBuffer(nil)
+
+	tp, err := dynamicTypes{}.typ(d.Schema())
+	if err != nil {
+		return nil, err
+	}
+	if err = toJSON(buf, &cursor, 0, limitsOf(d), d.Schema(), tp, -1); err != nil {
+		return nil, err
+	}
+
+	if left := len(data) - cursor.cursor; left > 0 {
+		return nil, fmt.Errorf("%d trailing bytes: %w", left, ErrInvalidLength)
+	}
+	return buf.Bytes(), nil
+}
+
+// FromJSON compiles doc, a JSON document in the form ToJSON writes, as a
+// message of the model described by d, with the types Dynamic builds.
+// Missing fields are written as zero values, and unknown fields are
+// rejected. Maps are written in any order, as Compile writes them.
+func FromJSON(d Describer, doc []byte) ([]byte, error) {
+	value, err := readJSON(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	s := d.Schema()
+	tp, err := dynamicTypes{}.typ(s)
+	if err != nil {
+		return nil, err
+	}
+	if value, err = fromJSON(s, If(s.Type != "", s.Type, s.Kind.String()), value); err != nil {
+		return nil, err
+	}
+
+	buf := bytes.NewBuffer(nil)
+	if err = tp.Compile(value, buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// ToJSON converts data, a message of the stream, to a JSON document holding
+// its type id and value:
+//
+//	{"type":1,"value":{"SKU":"ab","Price":1}}
+func (b *ModelMultiBuilder[T]) ToJSON(data []byte) ([]byte, error) {
+	cursor := NewBufferCursor(data, 0)
+	buf := bytes.NewBuffer(nil)
+
+	if err := b.toJSON(buf, &cursor, map[T]Type[any]{}); err != nil {
+		return nil, err
+	}
+
+	if left := len(data) - cursor.cursor; left > 0 {
+		return nil, fmt.Errorf("%d trailing bytes: %w", left, ErrInvalidLength)
+	}
+	return buf.Bytes(), nil
+}
+
+// StreamToJSON converts data, a stream of messages, to JSON, writing one
+// document per line in the form ToJSON does.
+func (b *ModelMultiBuilder[T]) StreamToJSON(w io.Writer, data []byte) error {
+	cursor := NewBufferCursor(data, 0)
+	buf := bytes.NewBuffer(nil)
+	types := map[T]Type[any]{}
+
+	for cursor.cursor < len(data) {
+		buf.Reset()
+		if err := b.toJSON(buf, &cursor, types); err != nil {
+			return err
+		}
+		buf.WriteByte('\n')
+		if _, err := w.Write(buf.Bytes()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// toJSON converts the message at cursor, with the types of the models built
+// so far.
+func (b *ModelMultiBuilder[T]) toJSON(buf *bytes.Buffer, cursor *BufferCursor, types map[T]Type[any]) error {
+	start := cursor.cursor
+	fail := func(err error) error {
+		return &ParseError{Index: -1, Offset: cursor.cursor - start, MessageStart: start, StreamOffset: cursor.cursor, Err: err}
+	}
+
+	id, err := b.header.Parse(cursor)
+	if err != nil {
+		return fail(err)
+	}
+
+	size := -1
+	if b.frame != nil {
+		if size, err = b.frame.Parse(cursor); err != nil {
+			return fail(err)
+		}
+	}
+
+	p, ok := b.parsers[id]
+	if !ok {
+		return fail(fmt.Errorf("%v: %w", id, ErrUnknownType))
+	}
+
+	tp, ok := types[id]
+	if !ok {
+		if tp, err = (dynamicTypes{}).typ(Describe(p)); err != nil {
+			return err
+		}
+		types[id] = tp
+	}
+
+	typ, err := json.Marshal(id)
+	if err != nil {
+		return err
+	}
+
+	buf.WriteString(`{"type":`)
+	buf.Write(typ)
+	buf.WriteString(`,"value":`)
+	if err = toJSON(buf, cursor, start, b.limits, Describe(p), tp, size); err != nil {
+		return err
+	}
+	buf.WriteByte('}')
+	return nil
+}
+
+// FromJSON compiles doc, a JSON document in the form ToJSON writes, as a
+// message of the stream, type id and frame included.
+func (b *ModelMultiBuilder[T]) FromJSON(doc []byte) ([]byte, error) {
+	var msg jsonMessage[T]
+	if err := json.Unmarshal(doc, &msg); err != nil {
+		return nil, err
+	}
+
+	p, ok := b.parsers[msg.Type]
+	if !ok {
+		return nil, fmt.Errorf("%v: %w", msg.Type, ErrUnknownType)
+	}
+
+	body, err := FromJSON(Describe(p), msg.Value)
+	if err != nil {
+		return nil, err
+	}
+
+	buf := bytes.NewBuffer(nil)
+	if err = b.header.Compile(msg.Type, buf); err != nil {
+		return nil, err
+	}
+
+	if b.frame != nil {
+		if headerCapacity(b.frame) < len(body) {
+			return nil, ErrOverflow
+		}
+		if err = b.frame.Compile(len(body), buf); err != nil {
+			return nil, err
+		}
+	}
+
+	buf.Write(body)
+	return buf.Bytes(), nil
+}
+
+// StreamFromJSON compiles a sequence of JSON documents read from r, in the
+// form FromJSON takes, to a stream of messages written to w.
+func (b *ModelMultiBuilder[T]) StreamFromJSON(w io.Writer, r io.Reader) error {
+	dec := json.NewDecoder(r)
+	for {
+		var doc json.RawMessage
+		if err := dec.Decode(&doc); err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		data, err := b.FromJSON(doc)
+		if err != nil {
+			return err
+		}
+		if _, err = w.Write(data); err != nil {
+			return err
+		}
+	}
+}
+
+// toJSON parses a message described by s with tp, the type Dynamic builds
+// for s, from cursor, within the next size bytes if size is not negative,
+// and writes it to buf as JSON. The message starts at origin in the cursor.
+func toJSON(buf *bytes.Buffer, cursor *BufferCursor, origin int, limits *DecodeLimits, s Schema, tp Type[any], size int) error {
+	root := If(s.Type != "", s.Type, s.Kind.String())
+
+	r := cursor
+	if size >= 0 {
+		end := cursor.cursor + size
+		if end > len(cursor.data) {
+			return &ParseError{Path: root, Index: -1, Offset: cursor.cursor - origin, MessageStart: origin, StreamOffset: cursor.cursor, Err: ErrCannotRead}
+		}
+		// Bytes left in the frame belong to a newer version of the model.
+		body := NewBufferCursor(cursor.data[:end], cursor.cursor)
+		r = &body
+		defer func() { cursor.cursor = end }()
+	}
+
+	m := getMessageReader(r, r.cursor-origin)
+	defer putMessageReader(m)
+	if limits != nil {
+		m.limit(*limits)
+	}
+
+	value, err := tp.Parse(m)
+	if err != nil {
+		if _, ok := err.(*ParseError); !ok {
+			err = m.locate(within("", m.n, err).rooted(root))
+		}
+		return err
+	}
+
+	return writeJSON(buf, s, root, value)
+}
+
+// fromJSON converts value, read by readJSON, to the value the type Dynamic
+// builds for s compiles, checking it against s.
+func fromJSON(s Schema, path string, value any) (any, error) {
+	var err error
+
+	switch s.Kind {
+	case KindStruct:
+		return structFromJSON(s, path, value)
+	case KindSlice, KindArray, KindChunked:
+		return listFromJSON(s, path, value)
+	case KindMap:
+		return mapFromJSON(s, path, value)
+	case KindOption:
+		if value == nil || s.Elem == nil {
+			return nil, nil
+		}
+		return fromJSON(*s.Elem, path, value)
+	case KindTime:
+		if str, ok := value.(string); ok {
+			value, err = parseJSONTime(str, s.Location)
+		}
+	case KindFixed, KindVarint:
+		value, err = numberFromJSON(s, value)
+	case KindVarchar:
+		if s.Type == "bytes" {
+			value, err = toBytes(value)
+		} else if _, ok := value.(string); !ok && value != nil {
+			err = typeMismatch("string", value)
+		}
+	case KindSkip:
+		value = nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return value, nil
+}
+
+func numberFromJSON(s Schema, value any) (any, error) {
+	switch {
+	case s.Type == "bool":
+		if _, ok := value.(bool); !ok && value != nil {
+			return nil, typeMismatch("bool", value)
+		}
+		return value, nil
+	case s.Type == "float32", s.Type == "float64":
+		return toFloat64(value)
+	case signed(s.Type):
+		return toInt64(value, typeBits(s.Type))
+	case strings.HasPrefix(s.Type, "uint"):
+		return toUint64(value, typeBits(s.Type))
+	}
+	return nil, fmt.Errorf("%s: %w", s.TypeString(), ErrUnknownType)
+}
+
+func structFromJSON(s Schema, path string, value any) (any, error) {
+	get, err := structFields(s, value)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	fields := make(map[string]any, len(s.Fields))
+	for i, f := range s.Fields {
+		name := schemaFieldName(f.Name, i)
+		if fields[name], err = fromJSON(f, joinPath(path, name), get(name)); err != nil {
+			return nil, err
+		}
+	}
+	return fields, nil
+}
+
+func listFromJSON(s Schema, path string, value any) (any, error) {
+	values, ok := value.([]any)
+	if !ok && value != nil {
+		return nil, fmt.Errorf("%s: %w", path, typeMismatch("list", value))
+	}
+	if s.Elem == nil {
+		return nil, fmt.Errorf("%s: %w", path, ErrUnknownType)
+	}
+
+	if s.Kind == KindArray {
+		if value != nil && len(values) != s.Length {
+			return nil, fmt.Errorf("%s: %w", path, ErrInvalidLength)
+		}
+		// A missing array is as many zero values.
+		values = append(values, make([]any, s.Length-len(values))...)
+	}
+
+	res := make([]any, len(values))
+	for i, v := range values {
+		var err error
+		if res[i], err = fromJSON(*s.Elem, path+"["+strconv.Itoa(i)+"]", v); err != nil {
+			return nil, err
+		}
+	}
+	return res, nil
+}
+
+func mapFromJSON(s Schema, path string, value any) (any, error) {
+	if s.Key == nil || s.Elem == nil {
+		return nil, fmt.Errorf("%s: %w", path, ErrUnknownType)
+	}
+
+	entries, err := toEntries(*s.Key, value)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	res := make(map[any]any, len(entries))
+	for i, entry := range entries {
+		key, err := fromJSON(*s.Key, path+"[#"+strconv.Itoa(i)+"]", entry.key)
+		if err != nil {
+			return nil, err
+		}
+		if res[key], err = fromJSON(*s.Elem, fmt.Sprintf("%s[%v]", path, entry.key), entry.value); err != nil {
+			return nil, err
+		}
+	}
+	return res, nil
+}
+
+// writeJSON writes value, as parsed by the types Dynamic builds, in the JSON form
+// described by ToJSON.
+func writeJSON(buf *bytes.Buffer, s Schema, path string, value any) error {
+	switch s.Kind {
+	case KindStruct:
+		fields, _ := value.(map[string]any)
+		buf.WriteByte('{')
+		first := true
+		for i, f := range s.Fields {
+			name := schemaFieldName(f.Name, i)
+			v, ok := fields[name]
+			if !ok || f.Kind == KindSkip {
+				continue
+			}
+			if !first {
+				buf.WriteByte(',')
+			}
+			first = false
+			writeJSONString(buf, name)
+			buf.WriteByte(':')
+			if err := writeJSON(buf, f, joinPath(path, name), v); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte('}')
+		return nil
+	case KindSlice, KindArray, KindChunked:
+		values, _ := value.([]any)
+		buf.WriteByte('[')
+		for i, v := range values {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			if err := writeJSON(buf, *s.Elem, path+"["+strconv.Itoa(i)+"]", v); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte(']')
+		return nil
+	case KindMap:
+		values, _ := value.(map[any]any)
+		entries := make([]mapEntry, 0, len(values))
+		for k, v := range values {
+			entries = append(entries, mapEntry{k, v})
+		}
+		sortEntries(entries)
+
+		buf.WriteByte('{')
+		for i, entry := range entries {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			writeJSONString(buf, formatJSONKey(entry.key))
+			buf.WriteByte(':')
+			if err := writeJSON(buf, *s.Elem, fmt.Sprintf("%s[%v]", path, entry.key), entry.value); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte('}')
+		return nil
+	case KindOption:
+		if value == nil {
+			buf.WriteString("null")
+			return nil
+		}
+		return writeJSON(buf, *s.Elem, path, value)
+	case KindTime:
+		t, _ := value.(time.Time)
+		writeJSONString(buf, formatJSONTime(t, s.Location))
+		return nil
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	buf.Write(data)
+	return nil
+}
+
+func writeJSONString(buf *bytes.Buffer, s string) {
+	data, _ := json.Marshal(s)
+	buf.Write(data)
+}
+
+// formatJSONKey renders a map key as a JSON object key.
+func formatJSONKey(key any) string {
+	switch k := key.(type) {
+	case string:
+		return k
+	case time.Time:
+		return formatJSONTime(k, false)
+	}
+	return fmt.Sprint(key)
+}
+
+// formatJSONTime renders t as RFC 3339, followed by the name of its location
+// when withLocation is set, e.g. "2024-05-01T10:00:00+02:00 Europe/Madrid".
+func formatJSONTime(t time.Time, withLocation bool) string {
+	if !withLocation {
+		return t.UTC().Format(time.RFC3339Nano)
+	}
+	return t.Format(time.RFC3339Nano) + " " + t.Location().String()
+}
+
+// parseJSONTime parses a time rendered by formatJSONTime.
+func parseJSONTime(s string, withLocation bool) (time.Time, error) {
+	value, name, found := strings.Cut(s, " ")
+
+	t, err := time.Parse(time.RFC3339Nano, value)
+	if err != nil || !withLocation || !found {
+		return t, err
+	}
+
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return t.In(loc), nil
+}
+
+// readJSON reads doc, keeping the members of objects in document order.
+func readJSON(doc []byte) (any, error) {
+	dec := json.NewDecoder(bytes.NewReader(doc))
+	dec.UseNumber()
+
+	value, err := readJSONValue(dec)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err = dec.Token(); err != io.EOF {
+		return nil, errTrailingJSON
+	}
+	return value, nil
+}
+
+func readJSONValue(dec *json.Decoder) (any, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	switch tok {
+	case json.Delim('{'):
+		obj := jsonObject{}
+		for dec.More() {
+			key, err := dec.Token()
+			if err != nil {
+				return nil, err
+			}
+			value, err := readJSONValue(dec)
+			if err != nil {
+				return nil, err
+			}
+			obj = append(obj, jsonMember{key: key.(string), value: value})
+		}
+		_, err = dec.Token()
+		return obj, err
+	case json.Delim('['):
+		values := []any{}
+		for dec.More() {
+			value, err := readJSONValue(dec)
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, value)
+		}
+		_, err = dec.Token()
+		return values, err
+	}
+
+	return tok, nil
+}
+
+func (o jsonObject) get(key string) any {
+	i := slices.IndexFunc(o, func(m jsonMember) bool { return m.key == key })
+	if i < 0 {
+		return nil
+	}
+	return o[i].value
+}
+
+// mapEntry is a key/value pair of a map being converted.
+type mapEntry struct {
+	key, value any
+}
+
+// toEntries lists the entries of a map value. JSON object keys are strings,
+// which are converted to the key type.
+func toEntries(key Schema, value any) ([]mapEntry, error) {
+	var entries []mapEntry
+
+	switch v := value.(type) {
+	case nil:
+	case map[any]any:
+		for k, x := range v {
+			entries = append(entries, mapEntry{k, x})
+		}
+		sortEntries(entries)
+	case map[string]any:
+		for k, x := range v {
+			entries = append(entries, mapEntry{jsonKey(key, k), x})
+		}
+		sortEntries(entries)
+	case jsonObject:
+		for _, m := range v {
+			entries = append(entries, mapEntry{jsonKey(key, m.key), m.value})
+		}
+	default:
+		return nil, typeMismatch("map", value)
+	}
+
+	return entries, nil
+}
+
+// jsonKey converts a JSON object key to a value of the key type.
+func jsonKey(key Schema, k string) any {
+	switch {
+	case key.Kind == KindVarchar, key.Kind == KindTime:
+		return k
+	case key.Type == "bool":
+		if b, err := strconv.ParseBool(k); err == nil {
+			return b
+		}
+		return k
+	}
+	return json.Number(k)
+}
+
+// sortEntries orders map entries by key, so that maps encode the same way
+// every time.
+func sortEntries(entries []mapEntry) {
+	slices.SortFunc(entries, func(a, b mapEntry) int {
+		return compareKeys(a.key, b.key)
+	})
+}
+
+func compareKeys(a, b any) int {
+	if x, err := toFloat64(a); err == nil {
+		if y, err := toFloat64(b); err == nil && x != y {
+			if x < y {
+				return -1
+			}
+			return 1
+		}
+	}
+	return strings.Compare(formatJSONKey(a), formatJSONKey(b))
+}
@@ -0,0 +1,255 @@
+package parco
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func jsonField(name string, x any) Schema {
+	s := Describe(x)
+	s.Name = name
+	return s
+}
+
+func TestToJSON(t *testing.T) {
+	data := compileParseOrder(t, parseOrder{
+		ID:     42,
+		Items:  []parseItem{{"ab", 7}},
+		Labels: map[string]uint16{"x": 1, "a": 2},
+		Note:   &parseItem{"n", 5},
+	})
+
+	doc, err := ToJSON(parseOrderBuilder(), data)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"ID": 42,
+		"Items": [{"SKU": "ab", "Price": 7}],
+		"Labels": {"a": 2, "x": 1},
+		"Note": {"SKU": "n", "Price": 5}
+	}`, string(doc))
+	// Fields in wire order, map keys sorted.
+	assert.Equal(t, `{"ID":42,"Items":[{"SKU":"ab","Price":7}],"Labels":{"a":2,"x":1},"Note":{"SKU":"n","Price":5}}`, string(doc))
+
+	back, err := FromJSON(parseOrderBuilder(), doc)
+	require.NoError(t, err)
+
+	order, err := parseOrderBuilder().Parse(bytes.NewReader(back))
+	require.NoError(t, err)
+	assert.Equal(t, parseOrder{
+		ID:     42,
+		Items:  []parseItem{{"ab", 7}},
+		Labels: map[string]uint16{"x": 1, "a": 2},
+		Note:   &parseItem{"n", 5},
+	}, order)
+}
+
+func TestFromJSON_ExactLayout(t *testing.T) {
+	for name, tc := range map[string]struct {
+		builder Describer
+		compile func(w *bytes.Buffer) error
+	}{
+		"plain": {
+			builder: parseOrderBuilder(),
+			compile: func(w *bytes.Buffer) error {
+				return parseOrderBuilder().Compile(parseOrder{ID: 1, Items: []parseItem{{"a", 1}}, Labels: map[string]uint16{"k": 3}}, w)
+			},
+		},
+		"tagged": {
+			builder: settingsBuilder(),
+			compile: func(w *bytes.Buffer) error {
+				return settingsBuilder().Compile(settings{Name: "db", Ratio: 0.5, Hosts: []string{"a"}, Limit: Ptr[int16](-3), Seq: 1}, w)
+			},
+		},
+		"extensible": {
+			builder: deviceV2Builder(ObjectFactory[deviceV2]()),
+			compile: func(w *bytes.Buffer) error {
+				return deviceV2Builder(ObjectFactory[deviceV2]()).Compile(deviceV2{ID: 1, Name: "a", Firmware: 3, Tags: []string{"x"}}, w)
+			},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			buf := bytes.NewBuffer(nil)
+			require.NoError(t, tc.compile(buf))
+
+			doc, err := ToJSON(tc.builder, buf.Bytes())
+			require.NoError(t, err)
+
+			back, err := FromJSON(tc.builder, doc)
+			require.NoError(t, err)
+			assert.Equal(t, buf.Bytes(), back)
+		})
+	}
+}
+
+func TestJSON_SchemaOnly(t *testing.T) {
+	schema := Schema{
+		Kind: KindStruct,
+		Type: "event",
+		Fields: []Schema{
+			jsonField("At", TimeUTC()),
+			jsonField("Local", TimeLocation()),
+			jsonField("Payload", Blob(UInt8Header())),
+			jsonField("Delta", VarInt64()),
+			jsonField("Ratio", Float32(binary.BigEndian)),
+			jsonField("Flags", Array(2, Bool())),
+			jsonField("", SkipType(2)),
+			jsonField("Counts", MapType(UInt8Header(), UInt16LE(), VarUInt32())),
+		},
+	}
+
+	madrid, err := time.LoadLocation("Europe/Madrid")
+	require.NoError(t, err)
+	at := time.Date(2024, 5, 1, 8, 0, 0, 1, time.UTC)
+
+	// The same message compiled by the types themselves.
+	buf := bytes.NewBuffer(nil)
+	require.NoError(t, TimeUTC().Compile(at, buf))
+	require.NoError(t, TimeLocation().Compile(at.In(madrid), buf))
+	require.NoError(t, Blob(UInt8Header()).Compile([]byte{1, 2}, buf))
+	require.NoError(t, VarInt64().Compile(-300, buf))
+	require.NoError(t, Float32(binary.BigEndian).Compile(1.5, buf))
+	require.NoError(t, Array(2, Bool()).Compile(SliceView[bool]{true, false}, buf))
+	require.NoError(t, SkipType(2).Compile(nil, buf))
+	require.NoError(t, MapType(UInt8Header(), UInt16LE(), VarUInt32()).Compile(map[uint16]uint32{7: 70000}, buf))
+
+	doc, err := ToJSON(schema, buf.Bytes())
+	require.NoError(t, err)
+	assert.Equal(t, `{"At":"2024-05-01T08:00:00.000000001Z",`+
+		`"Local":"2024-05-01T10:00:00.000000001+02:00 Europe/Madrid",`+
+		`"Payload":"AQI=","Delta":-300,"Ratio":1.5,"Flags":[true,false],`+
+		`"Counts":{"7":70000}}`, string(doc))
+
+	back, err := FromJSON(schema, doc)
+	require.NoError(t, err)
+	assert.Equal(t, buf.Bytes(), back)
+}
+
+func TestFromJSON_MissingFieldsAreZero(t *testing.T) {
+	data, err := FromJSON(parseOrderBuilder(), []byte(`{"ID": 3}`))
+	require.NoError(t, err)
+
+	order, err := parseOrderBuilder().Parse(bytes.NewReader(data))
+	require.NoError(t, err)
+	assert.Equal(t, uint16(3), order.ID)
+	assert.Empty(t, order.Items)
+	assert.Nil(t, order.Note)
+}
+
+func TestFromJSON_Errors(t *testing.T) {
+	_, err := FromJSON(parseOrderBuilder(), []byte(`{"ID": 3, "Extra": 1}`))
+	var notFound ErrFieldNotFound
+	assert.ErrorAs(t, err, &notFound)
+
+	_, err = FromJSON(parseOrderBuilder(), []byte(`{"ID": 70000}`))
+	assert.ErrorIs(t, err, ErrOverflow)
+	assert.ErrorContains(t, err, "parseOrder.ID")
+
+	_, err = FromJSON(parseOrderBuilder(), []byte(`{"Items": [{"SKU": 1}]}`))
+	var mismatch ErrTypeAssertion
+	assert.ErrorAs(t, err, &mismatch)
+	assert.ErrorContains(t, err, "parseOrder.Items[0].SKU")
+
+	_, err = FromJSON(parseOrderBuilder(), []byte(`{"Labels": {"a": -1}}`))
+	assert.ErrorIs(t, err, ErrOverflow)
+	assert.ErrorContains(t, err, "parseOrder.Labels[a]")
+
+	_, err = FromJSON(parseOrderBuilder(), []byte(`{} {}`))
+	assert.ErrorIs(t, err, errTrailingJSON)
+}
+
+func TestToJSON_Errors(t *testing.T) {
+	data := compileParseOrder(t, parseOrder{ID: 1, Note: &parseItem{"n", 5}})
+
+	_, err := ToJSON(parseOrderBuilder(), data[:len(data)-1])
+	var pe *ParseError
+	require.ErrorAs(t, err, &pe)
+	assert.Equal(t, "parseOrder.Note.Price", pe.Path)
+	assert.ErrorIs(t, err, ErrCannotRead)
+
+	_, err = ToJSON(parseOrderBuilder(), append(data, 0))
+	assert.ErrorIs(t, err, ErrInvalidLength)
+
+	_, err = ToJSON(parseOrderBuilder().Limits(DecodeLimits{MaxMessageBytes: 4}), data)
+	var limitErr *LimitError
+	require.ErrorAs(t, err, &limitErr)
+	assert.Equal(t, "MaxMessageBytes", limitErr.Limit)
+}
+
+func TestToJSON_AgreesWithParser(t *testing.T) {
+	valid := compileParseOrder(t, parseOrder{ID: 1, Items: []parseItem{{"ab", 7}}, Labels: map[string]uint16{"k": 3}})
+
+	corpus := [][]byte{}
+	for i := range valid {
+		corpus = append(corpus, valid[:i])
+		for _, b := range []byte{0x00, 0x02, 0x80, 0xff} {
+			mutated := bytes.Clone(valid)
+			mutated[i] = b
+			corpus = append(corpus, mutated)
+		}
+	}
+
+	for _, data := range corpus {
+		_, jsonErr := ToJSON(parseOrderBuilder(), data)
+		_, parseErr := parseOrderBuilder().ParseBytes(data)
+		if parseErr == nil && jsonErr != nil {
+			// ToJSON rejects trailing bytes, which ParseBytes leaves.
+			assert.ErrorIs(t, jsonErr, ErrInvalidLength, "input %x", data)
+			continue
+		}
+		assert.Equal(t, parseErr == nil, jsonErr == nil, "input %x: ToJSON: %v, parse: %v", data, jsonErr, parseErr)
+	}
+}
+
+func TestModelMultiBuilder_JSON(t *testing.T) {
+	for name, b := range map[string]*ModelMultiBuilder[int]{
+		"plain":  multiBuilder(),
+		"framed": multiBuilder().Framed(UInt8Header()),
+	} {
+		t.Run(name, func(t *testing.T) {
+			buf := bytes.NewBuffer(nil)
+			require.NoError(t, b.CompileAny(multiItemType, parseItem{"ab", 1}, buf))
+
+			doc, err := b.ToJSON(buf.Bytes())
+			require.NoError(t, err)
+			assert.Equal(t, `{"type":1,"value":{"SKU":"ab","Price":1}}`, string(doc))
+
+			back, err := b.FromJSON(doc)
+			require.NoError(t, err)
+			assert.Equal(t, buf.Bytes(), back)
+
+			_, err = b.FromJSON([]byte(`{"type":9,"value":{}}`))
+			assert.ErrorIs(t, err, ErrUnknownType)
+		})
+	}
+}
+
+func TestModelMultiBuilder_StreamJSON(t *testing.T) {
+	b := multiBuilder().Framed(VarUIntHeader())
+
+	buf := bytes.NewBuffer(nil)
+	require.NoError(t, b.CompileAny(multiItemType, parseItem{"ab", 1}, buf))
+	require.NoError(t, b.CompileAny(multiOrderType, parseOrder{ID: 2}, buf))
+	stream := buf.Bytes()
+
+	out := bytes.NewBuffer(nil)
+	require.NoError(t, b.StreamToJSON(out, stream))
+	assert.Equal(t, `{"type":1,"value":{"SKU":"ab","Price":1}}`+"\n"+
+		`{"type":2,"value":{"ID":2,"Items":[],"Labels":{},"Note":null}}`+"\n", out.String())
+
+	back := bytes.NewBuffer(nil)
+	require.NoError(t, b.StreamFromJSON(back, out))
+	assert.Equal(t, stream, back.Bytes())
+
+	// Failures are located in the stream.
+	err := b.StreamToJSON(bytes.NewBuffer(nil), stream[:len(stream)-1])
+	var pe *ParseError
+	require.ErrorAs(t, err, &pe)
+	first := 2 + 7
+	assert.Equal(t, first, pe.MessageStart)
+	assert.Equal(t, "parseOrder", pe.Path)
+}
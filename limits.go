@@ -0,0 +1,155 @@
+package parco
+
+import (
+	"fmt"
+	"io"
+	"unsafe"
+)
+
+type (
+	// DecodeLimits bounds what parsing a single message may read and
+	// allocate, failing with a LimitError past any of them. Zero fields fall
+	// back to the package defaults, MaxReasonableVarSize and friends, or
+	// leave the corresponding limit out.
+	DecodeLimits struct {
+		// MaxStringBytes caps the length of strings and blobs.
+		MaxStringBytes int
+		// MaxCollectionLength caps the element count of slices, chunked
+		// sequences, streamed slices and maps.
+		MaxCollectionLength int
+		// MaxDepth caps how deep models nest, the message itself being at
+		// depth 1.
+		MaxDepth int
+		// MaxMessageBytes caps the bytes read for the message.
+		MaxMessageBytes int
+		// MaxAllocBytes caps the bytes allocated for the strings, blobs and
+		// collections of the message, estimated from their lengths and
+		// element types. Zero-copy strings and blobs allocate nothing.
+		MaxAllocBytes int
+	}
+
+	// decodeBudget tracks a message against its limits. Nested readers of
+	// the message share it.
+	decodeBudget struct {
+		limits DecodeLimits
+		depth  int
+		alloc  int
+	}
+)
+
+// LimitError reports the DecodeLimits field that tripped, its value and the
+// value it was given. It wraps ErrLimitExceeded.
+type LimitError struct {
+	Limit string
+	Max   int
+	Have  int
+}
+
+func (e *LimitError) Error() string {
+	return fmt.Sprintf("%v: %s is %d, have %d", ErrLimitExceeded, e.Limit, e.Max, e.Have)
+}
+
+func (e *LimitError) Unwrap() error {
+	return ErrLimitExceeded
+}
+
+// limitsOf returns the DecodeLimits d parses messages with, nil if none.
+func limitsOf(d any) *DecodeLimits {
+	if l, ok := d.(interface{ decodeLimits() *DecodeLimits }); ok {
+		return l.decodeLimits()
+	}
+	return nil
+}
+
+// newBudget returns a budget tracking limits, nil if unlimited.
+func newBudget(limits *DecodeLimits) *decodeBudget {
+	if limits == nil {
+		return nil
+	}
+	return &decodeBudget{limits: *limits}
+}
+
+// budgetOf returns the budget of the message r reads, nil if unlimited.
+func budgetOf(r io.Reader) *decodeBudget {
+	if m, ok := r.(*messageReader); ok {
+		return m.budget
+	}
+	return nil
+}
+
+// checkVarSize checks the length of a string or blob read from r.
+func checkVarSize(r io.Reader, size int) error {
+	b := budgetOf(r)
+	if b == nil || b.limits.MaxStringBytes == 0 {
+		if size < 0 || size > MaxReasonableVarSize {
+			return ErrOverflow
+		}
+		return nil
+	}
+	if size < 0 {
+		return ErrOverflow
+	}
+	if size > b.limits.MaxStringBytes {
+		return &LimitError{Limit: "MaxStringBytes", Max: b.limits.MaxStringBytes, Have: size}
+	}
+	return nil
+}
+
+// checkLength checks the element count of a collection read from r, fallback
+// being the limit without DecodeLimits, or 0 for none.
+func checkLength(r io.Reader, length, fallback int) error {
+	if length < 0 {
+		return ErrOverflow
+	}
+	b := budgetOf(r)
+	if b == nil || b.limits.MaxCollectionLength == 0 {
+		if fallback > 0 && length > fallback {
+			return ErrOverflow
+		}
+		return nil
+	}
+	if length > b.limits.MaxCollectionLength {
+		return &LimitError{Limit: "MaxCollectionLength", Max: b.limits.MaxCollectionLength, Have: length}
+	}
+	return nil
+}
+
+// allocate charges n bytes about to be allocated to the message r reads.
+func allocate(r io.Reader, n int) error {
+	b := budgetOf(r)
+	if b == nil || b.limits.MaxAllocBytes == 0 {
+		return nil
+	}
+	if b.alloc += n; b.alloc > b.limits.MaxAllocBytes {
+		return &LimitError{Limit: "MaxAllocBytes", Max: b.limits.MaxAllocBytes, Have: b.alloc}
+	}
+	return nil
+}
+
+// allocateElems charges length values of T.
+func allocateElems[T any](r io.Reader, length int) error {
+	var zero T
+	return allocate(r, length*int(unsafe.Sizeof(zero)))
+}
+
+// enter descends into a model. Every call is paired with one to leave.
+func (b *decodeBudget) enter() error {
+	b.depth++
+	if limit := b.limits.MaxDepth; limit > 0 && b.depth > limit {
+		return &LimitError{Limit: "MaxDepth", Max: limit, Have: b.depth}
+	}
+	return nil
+}
+
+func (b *decodeBudget) leave() {
+	b.depth--
+}
+
+// readable checks that n more bytes may be read for the message, read bytes
+// having been read so far.
+func (b *decodeBudget) readable(read, n int) error {
+	if limit := b.limits.MaxMessageBytes; limit > 0 && read+n > limit {
+		return &LimitError{Limit: "MaxMessageBytes", Max: limit, Have: read + n}
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+package parco
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecodeLimits(t *testing.T) {
+	order := parseOrder{
+		ID:     1,
+		Items:  []parseItem{{"abcd", 1}, {"ef", 2}, {"g", 3}},
+		Labels: map[string]uint16{"x": 1, "y": 2},
+	}
+	data := compileParseOrder(t, order)
+
+	tests := []struct {
+		Name   string
+		Limits DecodeLimits
+		Limit  string
+		Path   string
+	}{
+		{"strings", DecodeLimits{MaxStringBytes: 3}, "MaxStringBytes", "parseOrder.Items[0].SKU"},
+		{"collections", DecodeLimits{MaxCollectionLength: 2}, "MaxCollectionLength", "parseOrder.Items"},
+		{"depth", DecodeLimits{MaxDepth: 1}, "MaxDepth", "parseOrder.Items[0]"},
+		{"message bytes", DecodeLimits{MaxMessageBytes: len(data) - 1}, "MaxMessageBytes", "parseOrder.Note"},
+		{"allocations", DecodeLimits{MaxAllocBytes: 64}, "MaxAllocBytes", "parseOrder.Items"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			builder := parseOrderBuilder().Limits(test.Limits)
+
+			for name, parse := range map[string]func() (parseOrder, error){
+				"stream":    func() (parseOrder, error) { return builder.Parse(bytes.NewReader(data)) },
+				"bytes":     func() (parseOrder, error) { return builder.ParseBytes(data) },
+				"zero-copy": func() (parseOrder, error) { return builder.ParseBytesZeroCopy(data) },
+			} {
+				_, err := parse()
+				assert.ErrorIs(t, err, ErrLimitExceeded, name)
+
+				var limitErr *LimitError
+				require.ErrorAs(t, err, &limitErr, name)
+				assert.Equal(t, test.Limit, limitErr.Limit, name)
+
+				var parseErr *ParseError
+				require.ErrorAs(t, err, &parseErr, name)
+				assert.Equal(t, test.Path, parseErr.Path, name)
+			}
+		})
+	}
+
+	// Limits that hold let messages through.
+	parsed, err := parseOrderBuilder().Limits(DecodeLimits{
+		MaxStringBytes:      4,
+		MaxCollectionLength: 3,
+		MaxDepth:            2,
+		MaxMessageBytes:     len(data),
+		MaxAllocBytes:       1 << 10,
+	}).ParseBytes(data)
+	require.NoError(t, err)
+	assert.Equal(t, order.Items, parsed.Items)
+}
+
+func TestDecodeLimits_Defaults(t *testing.T) {
+	// Lengths past the defaults overflow, unless allowed.
+	data := []byte{0x80, 0x80, 0x80, 0x80, 0x01}
+	streamed := Builder[export](ObjectFactory[export]()).
+		Slice(StreamSliceFieldDeferred(VarUIntHeader(), UInt8(),
+			func(e *export, v uint8) error { return nil },
+			nil,
+		))
+
+	_, err := streamed.ParseBytes(data)
+	assert.ErrorIs(t, err, ErrCannotRead)
+
+	_, err = streamed.Limits(DecodeLimits{MaxCollectionLength: 1000}).ParseBytes(data)
+	assert.ErrorIs(t, err, ErrLimitExceeded)
+	assert.EqualError(t, err, "parse export.#0 (field 0, offset 0): decode limit exceeded: MaxCollectionLength is 1000, have 268435456")
+}
+
+func TestModelMultiBuilder_Limits(t *testing.T) {
+	b := multiBuilder().Limits(DecodeLimits{MaxStringBytes: 1})
+
+	data, err := b.AppendAny(nil, multiItemType, parseItem{"a", 1})
+	require.NoError(t, err)
+	_, _, err = b.Parse(bytes.NewReader(data))
+	require.NoError(t, err)
+
+	data, err = b.AppendAny(nil, multiItemType, parseItem{"ab", 1})
+	require.NoError(t, err)
+	_, _, err = b.Parse(bytes.NewReader(data))
+	assert.ErrorIs(t, err, ErrLimitExceeded)
+}
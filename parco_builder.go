@@ -32,6 +32,16 @@ func (b ModelBuilder[T]) Compile(value T, w io.Writer) error {
 	return b.compiler.Compile(value, w)
 }
 
+// Size returns the encoded size of value. See Compiler.Size.
+func (b ModelBuilder[T]) Size(value T) (int, error) {
+	return b.compiler.Size(value)
+}
+
+// Append appends the encoding of value to dst. See Compiler.Append.
+func (b ModelBuilder[T]) Append(dst []byte, value T) ([]byte, error) {
+	return b.compiler.Append(dst, value)
+}
+
 func (b ModelBuilder[T]) CompileAny(value any, w io.Writer) error {
 	t, ok := value.(T)
 	if !ok {
@@ -44,6 +54,21 @@ func (b ModelBuilder[T]) Parse(r io.Reader) (T, error) {
 	return b.parser.Parse(r)
 }
 
+func (b ModelBuilder[T]) ParseBytes(data []byte) (T, error) {
+	return b.parser.ParseBytes(data)
+}
+
+// ParseBytesZeroCopy parses data in zero-copy mode. See
+// Parser.ParseBytesZeroCopy.
+func (b ModelBuilder[T]) ParseBytesZeroCopy(data []byte) (T, error) {
+	return b.parser.ParseBytesZeroCopy(data)
+}
+
+// Scan reads the messages concatenated in r one by one. See Scanner.
+func (b ModelBuilder[T]) Scan(r io.Reader) *Scanner[T] {
+	return b.parser.Scan(r)
+}
+
 func (b ModelBuilder[T]) ParseAny(r io.Reader) (any, error) {
 	return b.parser.Parse(r)
 }
@@ -52,6 +77,90 @@ func (b ModelBuilder[T]) Parco() (*Parser[T], *Compiler[T]) {
 	return b.parser, b.compiler
 }
 
+// Named names the last registered field, e.g.
+//
+//	Builder[User](factory).UInt8(getAge, setAge).Named("Age")
+//
+// Names are not part of the wire format: they describe the model in its
+// Schema and locate failures in parse errors.
+func (b ModelBuilder[T]) Named(name string) ModelBuilder[T] {
+	b.parser.Named(name)
+	b.compiler.Named(name)
+	return b
+}
+
+// Extensible prefixes every message with its byte length, so that fields
+// can be appended to the model without breaking older peers: they skip the
+// fields they do not know, while newer peers leave the fields missing from
+// older messages as the factory built them. Fields must only ever be added at
+// the end, and both peers must be extensible.
+//
+//	Builder[User](factory).Extensible(VarUIntHeader()).UInt8(getAge, setAge)
+func (b ModelBuilder[T]) Extensible(header IntType) ModelBuilder[T] {
+	b.parser.Extensible(header)
+	b.compiler.Extensible(header)
+	return b
+}
+
+// Checksum follows every message with its checksum, e.g. CRC32C(), verified
+// on parse: corrupted messages fail with ErrChecksumMismatch rather than
+// decoding garbage.
+//
+//	Builder[User](factory).Checksum(CRC32C()).UInt8(getAge, setAge)
+func (b ModelBuilder[T]) Checksum(sum Checksum) ModelBuilder[T] {
+	b.parser.Checksum(sum)
+	b.compiler.Checksum(sum)
+	return b
+}
+
+// Limits bounds what parsing a message may read and allocate, e.g. for
+// messages from untrusted peers:
+//
+//	Builder[User](factory).Limits(DecodeLimits{MaxStringBytes: 256, MaxDepth: 4})
+//
+// Messages past any limit fail with a LimitError. See DecodeLimits.
+func (b ModelBuilder[T]) Limits(limits DecodeLimits) ModelBuilder[T] {
+	b.parser.Limits(limits)
+	return b
+}
+
+func (b ModelBuilder[T]) decodeLimits() *DecodeLimits {
+	return b.parser.limits
+}
+
+// Tagged switches to the tagged layout: each field is preceded by its number
+// and wire type, and the message ends with a zero byte. Parsers match fields
+// by number in any order and skip those they do not know, so fields can be
+// added, removed and reordered freely as long as numbers are never reused.
+// Fields whose encoding is all zero bytes (zero numbers, empty strings and
+// collections, absent options) are not written at all: parsers parse them
+// from zeros, so they come out as if they had been sent.
+func (b ModelBuilder[T]) Tagged() ModelBuilder[T] {
+	b.parser.Tagged()
+	b.compiler.Tagged()
+	return b
+}
+
+// Tag numbers the last registered field, e.g.
+//
+//	Builder[User](factory).Tagged().UInt8(getAge, setAge).Tag(3)
+//
+// Fields are numbered from 1 in registration order, each one after the
+// highest number so far, unless told otherwise. It panics if num is in use.
+func (b ModelBuilder[T]) Tag(num int) ModelBuilder[T] {
+	b.parser.Tag(num)
+	b.compiler.Tag(num)
+	return b
+}
+
+// Schema describes the fields of the model in wire order.
+func (b ModelBuilder[T]) Schema() Schema {
+	if len(b.parser.fields) >= len(b.compiler.fields) {
+		return b.parser.Schema()
+	}
+	return b.compiler.Schema()
+}
+
 func (b ModelBuilder[T]) Struct(field fieldBuilder[T]) ModelBuilder[T] {
 	b.parser.Struct(field)
 	b.compiler.Struct(field)
@@ -180,6 +289,66 @@ func (b ModelBuilder[T]) Int(order binary.ByteOrder, getter Getter[T, int], sett
 	return b
 }
 
+func (b ModelBuilder[T]) VarUInt8(getter Getter[T, uint8], setter Setter[T, uint8]) ModelBuilder[T] {
+	b.parser.VarUInt8(setter)
+	b.compiler.VarUInt8(getter)
+	return b
+}
+
+func (b ModelBuilder[T]) VarInt8(getter Getter[T, int8], setter Setter[T, int8]) ModelBuilder[T] {
+	b.parser.VarInt8(setter)
+	b.compiler.VarInt8(getter)
+	return b
+}
+
+func (b ModelBuilder[T]) VarUInt16(getter Getter[T, uint16], setter Setter[T, uint16]) ModelBuilder[T] {
+	b.parser.VarUInt16(setter)
+	b.compiler.VarUInt16(getter)
+	return b
+}
+
+func (b ModelBuilder[T]) VarUInt32(getter Getter[T, uint32], setter Setter[T, uint32]) ModelBuilder[T] {
+	b.parser.VarUInt32(setter)
+	b.compiler.VarUInt32(getter)
+	return b
+}
+
+func (b ModelBuilder[T]) VarUInt64(getter Getter[T, uint64], setter Setter[T, uint64]) ModelBuilder[T] {
+	b.parser.VarUInt64(setter)
+	b.compiler.VarUInt64(getter)
+	return b
+}
+
+func (b ModelBuilder[T]) VarInt16(getter Getter[T, int16], setter Setter[T, int16]) ModelBuilder[T] {
+	b.parser.VarInt16(setter)
+	b.compiler.VarInt16(getter)
+	return b
+}
+
+func (b ModelBuilder[T]) VarInt32(getter Getter[T, int32], setter Setter[T, int32]) ModelBuilder[T] {
+	b.parser.VarInt32(setter)
+	b.compiler.VarInt32(getter)
+	return b
+}
+
+func (b ModelBuilder[T]) VarInt64(getter Getter[T, int64], setter Setter[T, int64]) ModelBuilder[T] {
+	b.parser.VarInt64(setter)
+	b.compiler.VarInt64(getter)
+	return b
+}
+
+func (b ModelBuilder[T]) VarInt(getter Getter[T, int], setter Setter[T, int]) ModelBuilder[T] {
+	b.parser.VarInt(setter)
+	b.compiler.VarInt(getter)
+	return b
+}
+
+func (b ModelBuilder[T]) VarUInt(getter Getter[T, uint], setter Setter[T, uint]) ModelBuilder[T] {
+	b.parser.VarUInt(setter)
+	b.compiler.VarUInt(getter)
+	return b
+}
+
 func (b ModelBuilder[T]) Float32(
 	order binary.ByteOrder,
 	getter Getter[T, float32],
@@ -27,9 +27,15 @@ type (
 	ModelMultiBuilder[T comparable] struct {
 		header Type[T]
 
+		// frame is the length header of framed streams, nil otherwise.
+		frame IntType
+
 		parsers map[T]parserAny
 
 		compilers map[T]compilerAny
+
+		// limits, if set, bound the messages parsed, whatever their type.
+		limits *DecodeLimits
 	}
 )
 
@@ -59,12 +65,61 @@ func (b *ModelMultiBuilder[T]) MustRegister(id T, builder builderAny) *ModelMult
 	panic(fmt.Sprintf("this id is registered already: %v", id))
 }
 
+// Framed writes the byte length of every message, with header, right after
+// its type id. A corrupted or unknown message is then consumed whole, so
+// the next Parse resumes at the following message. Only failures reading
+// the id or the length itself leave the stream out of sync.
+func (b *ModelMultiBuilder[T]) Framed(header IntType) *ModelMultiBuilder[T] {
+	b.frame = header
+	return b
+}
+
+// Limits bounds what parsing a message may read and allocate, type id
+// included, in place of those of the registered builders. See DecodeLimits.
+func (b *ModelMultiBuilder[T]) Limits(limits DecodeLimits) *ModelMultiBuilder[T] {
+	b.limits = &limits
+	return b
+}
+
+// Parse reads the type id of the next message and parses it with the
+// builder registered for it. Failures are reported as *ParseError, located
+// in the stream when r is an OffsetReader such as CountingReader.
 func (b *ModelMultiBuilder[T]) Parse(r io.Reader) (id T, res any, err error) {
-	id, err = b.header.Parse(r)
+	m := getMessageReader(r, 0)
+	defer putMessageReader(m)
+	if b.limits != nil {
+		m.limit(*b.limits)
+	}
+
+	if id, res, err = b.parse(m); err != nil {
+		pe, ok := err.(*ParseError)
+		if !ok {
+			pe = within("", m.n, err)
+		}
+		err = m.locate(pe)
+	}
+	return
+}
+
+// Scan reads the messages concatenated in r one by one, whatever their
+// type. See Scanner.
+func (b *ModelMultiBuilder[T]) Scan(r io.Reader) *Scanner[Message[T]] {
+	return newScanner(r, func(r io.Reader) (Message[T], error) {
+		id, res, err := b.Parse(r)
+		return Message[T]{ID: id, Value: res}, err
+	})
+}
+
+func (b *ModelMultiBuilder[T]) parse(m *messageReader) (id T, res any, err error) {
+	id, err = b.header.Parse(m)
 	if err != nil {
 		return
 	}
 
+	if b.frame != nil {
+		return b.parseFrame(id, m)
+	}
+
 	p, ok := b.parsers[id]
 
 	if !ok {
@@ -72,10 +127,42 @@ func (b *ModelMultiBuilder[T]) Parse(r io.Reader) (id T, res any, err error) {
 		return
 	}
 
-	res, err = p.ParseAny(r)
+	res, err = p.ParseAny(m)
 	return
 }
 
+func (b *ModelMultiBuilder[T]) parseFrame(id T, m *messageReader) (_ T, res any, err error) {
+	var size int
+	if size, err = b.frame.Parse(m); err != nil {
+		return id, nil, err
+	}
+
+	if size < 0 || size > MaxReasonableVarSize {
+		return id, nil, ErrOverflow
+	}
+
+	offset := m.n
+
+	cursor, box, err := m.body(size)
+	if box != nil {
+		defer SinglePool.Put(box)
+	}
+	if err != nil {
+		return id, nil, err
+	}
+
+	p, ok := b.parsers[id]
+	if !ok {
+		return id, nil, fmt.Errorf("%v: %w", id, ErrUnknownType)
+	}
+
+	body := m.nested(&cursor, offset)
+	defer putMessageReader(body)
+
+	res, err = p.ParseAny(body)
+	return id, res, err
+}
+
 func (b *ModelMultiBuilder[T]) Compile(item serializable[T], w io.Writer) (err error) {
 	return b.compile(item.ParcoID(), item, w)
 }
@@ -84,22 +171,50 @@ func (b *ModelMultiBuilder[T]) CompileAny(id T, item any, w io.Writer) (err erro
 	return b.compile(id, item, w)
 }
 
-func (b *ModelMultiBuilder[T]) compile(id T, item any, w io.Writer) (err error) {
-	err = b.header.Compile(id, w)
+func (b *ModelMultiBuilder[T]) compile(id T, item any, w io.Writer) error {
+	cw := getCompileWriter(w)
+	defer putCompileWriter(cw)
 
-	if err != nil {
-		return
+	if err := b.append(cw, id, item); err != nil {
+		return err
 	}
+	return cw.flush()
+}
 
-	c, ok := b.compilers[id]
+// Append appends item, preceded by its type id, to dst and returns the
+// extended buffer. See Compiler.Append.
+func (b *ModelMultiBuilder[T]) Append(dst []byte, item serializable[T]) ([]byte, error) {
+	return b.AppendAny(dst, item.ParcoID(), item)
+}
 
+func (b *ModelMultiBuilder[T]) AppendAny(dst []byte, id T, item any) ([]byte, error) {
+	cw := compileWriter{buf: dst}
+	if err := b.append(&cw, id, item); err != nil {
+		return dst, err
+	}
+	return cw.buf, nil
+}
+
+func (b *ModelMultiBuilder[T]) append(cw *compileWriter, id T, item any) error {
+	c, ok := b.compilers[id]
 	if !ok {
-		err = fmt.Errorf("%v: %w", id, ErrUnknownType)
-		return
+		return fmt.Errorf("%v: %w", id, ErrUnknownType)
 	}
 
-	err = c.CompileAny(item, w)
-	return
+	if err := b.header.Compile(id, cw); err != nil {
+		return err
+	}
+
+	if b.frame == nil {
+		return c.CompileAny(item, cw)
+	}
+
+	start := cw.pin()
+	defer cw.unpin()
+	if err := c.CompileAny(item, cw); err != nil {
+		return err
+	}
+	return cw.prefixLength(start, b.frame)
 }
 
 func newAtomicPtr[T comparable](builder *ModelMultiBuilder[T]) *atomic.Pointer[ModelMultiBuilder[T]] {
@@ -0,0 +1,138 @@
+package parco
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	multiItemType  = 1
+	multiOrderType = 2
+)
+
+func multiBuilder() *ModelMultiBuilder[int] {
+	return MultiBuilder[int](UInt8Header()).
+		MustRegister(multiItemType, parseItemBuilder()).
+		MustRegister(multiOrderType, parseOrderBuilder())
+}
+
+func TestModelMultiBuilder_StreamOffsets(t *testing.T) {
+	b := multiBuilder()
+
+	buf := bytes.NewBuffer(nil)
+	require.NoError(t, b.CompileAny(multiItemType, parseItem{"ab", 1}, buf))
+	first := buf.Len()
+	require.NoError(t, b.CompileAny(multiItemType, parseItem{"cd", 2}, buf))
+
+	// Cut the second item within its price.
+	data := buf.Bytes()[:buf.Len()-1]
+	r := NewCountingReader(bytes.NewReader(data))
+
+	_, item, err := b.Parse(r)
+	require.NoError(t, err)
+	assert.Equal(t, parseItem{"ab", 1}, item)
+	assert.Equal(t, first, r.Offset())
+
+	_, _, err = b.Parse(r)
+	var pe *ParseError
+	require.ErrorAs(t, err, &pe)
+	assert.ErrorIs(t, err, ErrCannotRead)
+	assert.Equal(t, "parseItem.Price", pe.Path)
+	assert.Equal(t, first, pe.MessageStart)
+	// Type id, then SKU header and 2 bytes.
+	assert.Equal(t, 4, pe.Offset)
+	assert.Equal(t, first+4, pe.StreamOffset)
+}
+
+func TestModelMultiBuilder_BufferCursorOffsets(t *testing.T) {
+	b := multiBuilder()
+
+	buf := bytes.NewBuffer(nil)
+	require.NoError(t, b.CompileAny(multiItemType, parseItem{"ab", 1}, buf))
+	buf.WriteByte(9)
+
+	cursor := NewBufferCursor(buf.Bytes(), 0)
+	_, _, err := b.Parse(&cursor)
+	require.NoError(t, err)
+
+	_, _, err = b.Parse(&cursor)
+	var pe *ParseError
+	require.ErrorAs(t, err, &pe)
+	assert.ErrorIs(t, err, ErrUnknownType)
+	assert.Equal(t, 8, pe.MessageStart)
+	assert.Equal(t, 9, pe.StreamOffset)
+	assert.Equal(t, "parse message (offset 1): 9: unknown type", err.Error())
+}
+
+func TestModelMultiBuilder_WithoutOffsets(t *testing.T) {
+	_, _, err := multiBuilder().Parse(bytes.NewReader([]byte{multiItemType}))
+
+	var pe *ParseError
+	require.ErrorAs(t, err, &pe)
+	assert.Equal(t, -1, pe.MessageStart)
+	assert.Equal(t, -1, pe.StreamOffset)
+}
+
+func TestModelMultiBuilder_FramedResync(t *testing.T) {
+	b := multiBuilder().Framed(VarUIntHeader())
+
+	buf := bytes.NewBuffer(nil)
+	require.NoError(t, b.CompileAny(multiItemType, parseItem{"ab", 1}, buf))
+	require.NoError(t, b.CompileAny(multiOrderType, parseOrder{ID: 3}, buf))
+	require.NoError(t, b.CompileAny(multiItemType, parseItem{"cd", 2}, buf))
+
+	// type id, frame length, SKU header and bytes, price.
+	assert.Equal(t, []byte{multiItemType, 7, 2, 'a', 'b', 1, 0, 0, 0}, buf.Bytes()[:9])
+
+	data := buf.Bytes()
+	// Corrupt the SKU length of the first item, and the type of the order.
+	data[2] = 200
+	data[9] = 42
+
+	r := NewCountingReader(bytes.NewReader(data))
+
+	_, _, err := b.Parse(r)
+	assert.ErrorIs(t, err, ErrCannotRead)
+
+	_, _, err = b.Parse(r)
+	assert.ErrorIs(t, err, ErrUnknownType)
+
+	id, item, err := b.Parse(r)
+	require.NoError(t, err)
+	assert.Equal(t, multiItemType, id)
+	assert.Equal(t, parseItem{"cd", 2}, item)
+	assert.Equal(t, len(data), r.Offset())
+}
+
+func TestModelMultiBuilder_Errors(t *testing.T) {
+	_, err := multiBuilder().Register(multiItemType, parseItemBuilder())
+	assert.ErrorIs(t, err, ErrAlreadyRegistered)
+
+	err = multiBuilder().CompileAny(7, parseItem{}, bytes.NewBuffer(nil))
+	assert.True(t, errors.Is(err, ErrUnknownType))
+
+	err = multiBuilder().Framed(UInt8Header()).CompileAny(7, parseItem{}, bytes.NewBuffer(nil))
+	assert.ErrorIs(t, err, ErrUnknownType)
+}
+
+func TestModelMultiBuilder_Append(t *testing.T) {
+	for _, b := range []*ModelMultiBuilder[int]{multiBuilder(), multiBuilder().Framed(VarUIntHeader())} {
+		buf := bytes.NewBuffer(nil)
+		require.NoError(t, b.CompileAny(multiItemType, parseItem{"ab", 1}, buf))
+		require.NoError(t, b.CompileAny(multiOrderType, parseOrder{ID: 3}, buf))
+
+		dst, err := b.AppendAny(nil, multiItemType, parseItem{"ab", 1})
+		require.NoError(t, err)
+		dst, err = b.AppendAny(dst, multiOrderType, parseOrder{ID: 3})
+		require.NoError(t, err)
+		assert.Equal(t, buf.Bytes(), dst)
+
+		out, err := b.AppendAny(dst, 42, parseItem{})
+		assert.ErrorIs(t, err, ErrUnknownType)
+		assert.Equal(t, dst, out)
+	}
+}
@@ -0,0 +1,205 @@
+package parco
+
+import (
+	"fmt"
+	"io"
+	"sort"
+)
+
+type (
+	// VersionCodec reads, and optionally writes, one version of model T.
+	// ModelBuilder[T] is a VersionCodec[T] for the latest version; older
+	// versions adapt their own model with Upgrade.
+	VersionCodec[T any] interface {
+		Parse(io.Reader) (T, error)
+	}
+
+	versionCompiler[T any] interface {
+		Compile(T, io.Writer) error
+	}
+
+	// VersionAdapter parses a past version V of a model and upgrades it to
+	// the latest T. Given a Downgrade function, it also writes T as V.
+	VersionAdapter[V, T any] struct {
+		builder   ModelBuilder[V]
+		upgrade   func(V) (T, error)
+		downgrade func(T) (V, error)
+	}
+
+	// VersionedBuilder writes a version header before each model and, when
+	// parsing, dispatches on it to the codec of that version. Every version
+	// parses to the latest model T, so peers running different versions can
+	// talk to each other during rolling deployments.
+	VersionedBuilder[T any] struct {
+		header IntType
+
+		versions map[int]VersionCodec[T]
+
+		writes int
+	}
+)
+
+// Upgrade adapts the builder of a past version V of a model, converting the
+// values it parses to the latest model T with the given function.
+func Upgrade[V, T any](builder ModelBuilder[V], upgrade func(V) (T, error)) *VersionAdapter[V, T] {
+	return &VersionAdapter[V, T]{builder: builder, upgrade: upgrade}
+}
+
+// Downgrade makes the version writable: values of T are converted to V with
+// the given function before being compiled.
+func (a *VersionAdapter[V, T]) Downgrade(downgrade func(T) (V, error)) *VersionAdapter[V, T] {
+	a.downgrade = downgrade
+	return a
+}
+
+func (a *VersionAdapter[V, T]) Parse(r io.Reader) (res T, err error) {
+	v, err := a.builder.Parse(r)
+	if err != nil {
+		return
+	}
+	return a.upgrade(v)
+}
+
+func (a *VersionAdapter[V, T]) Compile(value T, w io.Writer) error {
+	if a.downgrade == nil {
+		return NewErrCompile("version cannot be written, it has no downgrade function")
+	}
+	v, err := a.downgrade(value)
+	if err != nil {
+		return err
+	}
+	return a.builder.Compile(v, w)
+}
+
+func (a *VersionAdapter[V, T]) writable() bool {
+	return a.downgrade != nil
+}
+
+func (a *VersionAdapter[V, T]) Schema() Schema {
+	return a.builder.Schema()
+}
+
+// Versioned returns an empty VersionedBuilder writing versions with header.
+func Versioned[T any](header IntType) *VersionedBuilder[T] {
+	return &VersionedBuilder[T]{
+		header:   header,
+		versions: make(map[int]VersionCodec[T]),
+		writes:   -1,
+	}
+}
+
+// Version registers the codec of version v. It panics if v is registered
+// already. The highest writable version is the one written, unless
+// WriteVersion says otherwise.
+func (b *VersionedBuilder[T]) Version(v int, codec VersionCodec[T]) *VersionedBuilder[T] {
+	if _, ok := b.versions[v]; ok {
+		panic(fmt.Sprintf("this version is registered already: %d", v))
+	}
+	b.versions[v] = codec
+	return b
+}
+
+// WriteVersion pins the version used by Compile. Services rolling out a new
+// version keep writing the previous one until every peer can read the new.
+func (b *VersionedBuilder[T]) WriteVersion(v int) *VersionedBuilder[T] {
+	b.writes = v
+	return b
+}
+
+// Versions returns the registered versions in ascending order.
+func (b *VersionedBuilder[T]) Versions() []int {
+	res := make([]int, 0, len(b.versions))
+	for v := range b.versions {
+		res = append(res, v)
+	}
+	sort.Ints(res)
+	return res
+}
+
+// Schema describes version v.
+func (b *VersionedBuilder[T]) Schema(v int) (Schema, bool) {
+	codec, ok := b.versions[v]
+	if !ok {
+		return Schema{}, false
+	}
+	return Describe(codec), true
+}
+
+func (b *VersionedBuilder[T]) Parse(r io.Reader) (res T, err error) {
+	_, res, err = b.ParseVersion(r)
+	return
+}
+
+// ParseVersion parses a model of any registered version, returning which.
+func (b *VersionedBuilder[T]) ParseVersion(r io.Reader) (v int, res T, err error) {
+	v, err = b.header.Parse(r)
+	if err != nil {
+		return
+	}
+
+	codec, ok := b.versions[v]
+	if !ok {
+		err = fmt.Errorf("%d: %w", v, ErrUnknownVersion)
+		return
+	}
+
+	res, err = codec.Parse(r)
+	return
+}
+
+func (b *VersionedBuilder[T]) ParseAny(r io.Reader) (any, error) {
+	return b.Parse(r)
+}
+
+func (b *VersionedBuilder[T]) Compile(value T, w io.Writer) error {
+	v := b.writes
+	if v < 0 {
+		v = b.latestWritable()
+	}
+	return b.CompileVersion(v, value, w)
+}
+
+func (b *VersionedBuilder[T]) CompileAny(value any, w io.Writer) error {
+	t, ok := value.(T)
+	if !ok {
+		return ErrUnknownType
+	}
+	return b.Compile(t, w)
+}
+
+// CompileVersion writes value as version v.
+func (b *VersionedBuilder[T]) CompileVersion(v int, value T, w io.Writer) error {
+	codec, ok := b.versions[v]
+	if !ok {
+		return fmt.Errorf("%d: %w", v, ErrUnknownVersion)
+	}
+
+	c, ok := versionWriter(codec)
+	if !ok {
+		return fmt.Errorf("%d: %w", v, NewErrCompile("version cannot be written"))
+	}
+
+	if err := b.header.Compile(v, w); err != nil {
+		return err
+	}
+
+	return c.Compile(value, w)
+}
+
+func (b *VersionedBuilder[T]) latestWritable() int {
+	latest := -1
+	for v, codec := range b.versions {
+		if _, ok := versionWriter(codec); ok && v > latest {
+			latest = v
+		}
+	}
+	return latest
+}
+
+func versionWriter[T any](codec VersionCodec[T]) (versionCompiler[T], bool) {
+	if w, ok := codec.(interface{ writable() bool }); ok && !w.writable() {
+		return nil, false
+	}
+	c, ok := codec.(versionCompiler[T])
+	return c, ok
+}
@@ -0,0 +1,148 @@
+package parco
+
+import (
+	"bytes"
+	"encoding/binary"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type (
+	userV1 struct {
+		Name string
+	}
+
+	userV2 struct {
+		First, Last string
+		Age         uint8
+	}
+)
+
+func userV1Builder() ModelBuilder[userV1] {
+	return Builder[userV1](ObjectFactory[userV1]()).
+		SmallVarchar(
+			func(u *userV1) string { return u.Name },
+			func(u *userV1, v string) { u.Name = v },
+		)
+}
+
+func userV2Builder() ModelBuilder[userV2] {
+	return Builder[userV2](ObjectFactory[userV2]()).
+		SmallVarchar(
+			func(u *userV2) string { return u.First },
+			func(u *userV2, v string) { u.First = v },
+		).
+		SmallVarchar(
+			func(u *userV2) string { return u.Last },
+			func(u *userV2, v string) { u.Last = v },
+		).
+		UInt8(
+			func(u *userV2) uint8 { return u.Age },
+			func(u *userV2, v uint8) { u.Age = v },
+		)
+}
+
+func upgradeUserV1(u userV1) (userV2, error) {
+	first, last, _ := strings.Cut(u.Name, " ")
+	return userV2{First: first, Last: last}, nil
+}
+
+func downgradeUserV1(u userV2) (userV1, error) {
+	return userV1{Name: u.First + " " + u.Last}, nil
+}
+
+func userVersions() *VersionedBuilder[userV2] {
+	return Versioned[userV2](UInt8Header()).
+		Version(1, Upgrade(userV1Builder(), upgradeUserV1)).
+		Version(2, userV2Builder())
+}
+
+func TestVersionedBuilder_ParsesEveryVersion(t *testing.T) {
+	b := userVersions()
+
+	buf := bytes.NewBuffer(nil)
+	require.NoError(t, UInt8Header().Compile(1, buf))
+	require.NoError(t, userV1Builder().Compile(userV1{Name: "Ada Lovelace"}, buf))
+
+	v, user, err := b.ParseVersion(buf)
+	require.NoError(t, err)
+	assert.Equal(t, 1, v)
+	assert.Equal(t, userV2{First: "Ada", Last: "Lovelace"}, user)
+
+	expected := userV2{First: "Alan", Last: "Turing", Age: 41}
+	require.NoError(t, b.Compile(expected, buf))
+	assert.Equal(t, byte(2), buf.Bytes()[0])
+
+	user, err = b.Parse(buf)
+	require.NoError(t, err)
+	assert.Equal(t, expected, user)
+}
+
+func TestVersionedBuilder_WriteVersion(t *testing.T) {
+	b := Versioned[userV2](UInt8Header()).
+		Version(1, Upgrade(userV1Builder(), upgradeUserV1).Downgrade(downgradeUserV1)).
+		Version(2, userV2Builder()).
+		WriteVersion(1)
+
+	buf := bytes.NewBuffer(nil)
+	require.NoError(t, b.Compile(userV2{First: "Ada", Last: "Lovelace", Age: 36}, buf))
+
+	// An old peer only knows about version 1.
+	old := Versioned[userV1](UInt8Header()).Version(1, userV1Builder())
+	user, err := old.Parse(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	assert.Equal(t, userV1{Name: "Ada Lovelace"}, user)
+
+	user2, err := b.Parse(buf)
+	require.NoError(t, err)
+	assert.Equal(t, userV2{First: "Ada", Last: "Lovelace"}, user2)
+}
+
+func TestVersionedBuilder_Errors(t *testing.T) {
+	b := Versioned[userV2](UInt16HeaderLE()).
+		Version(1, Upgrade(userV1Builder(), upgradeUserV1))
+
+	err := b.Compile(userV2{}, bytes.NewBuffer(nil))
+	assert.ErrorIs(t, err, ErrUnknownVersion)
+
+	err = b.CompileVersion(1, userV2{}, bytes.NewBuffer(nil))
+	assert.ErrorAs(t, err, &ErrCompile{})
+
+	_, err = b.Parse(bytes.NewReader(binary.LittleEndian.AppendUint16(nil, 7)))
+	assert.ErrorIs(t, err, ErrUnknownVersion)
+
+	assert.Panics(t, func() { b.Version(1, userV2Builder()) })
+}
+
+func TestVersionedBuilder_MultiBuilder(t *testing.T) {
+	multi := MultiBuilder[int](UInt8Header()).MustRegister(9, userVersions())
+
+	buf := bytes.NewBuffer(nil)
+	expected := userV2{First: "Grace", Last: "Hopper", Age: 85}
+	require.NoError(t, multi.CompileAny(9, expected, buf))
+
+	id, res, err := multi.Parse(buf)
+	require.NoError(t, err)
+	assert.Equal(t, 9, id)
+	assert.Equal(t, expected, res)
+}
+
+func TestVersionedBuilder_Schema(t *testing.T) {
+	b := userVersions()
+
+	assert.Equal(t, []int{1, 2}, b.Versions())
+
+	v1, ok := b.Schema(1)
+	require.True(t, ok)
+	assert.Len(t, v1.Fields, 1)
+
+	v2, ok := b.Schema(2)
+	require.True(t, ok)
+	assert.Len(t, v2.Fields, 3)
+
+	_, ok = b.Schema(3)
+	assert.False(t, ok)
+}
@@ -11,8 +11,23 @@ type (
 		Compile(item *T, writer io.Writer) error
 	}
 
+	// fieldSizer is implemented by fields telling their encoded size, as the
+	// built-in ones do.
+	fieldSizer[T any] interface {
+		Size(item *T) (int, error)
+	}
+
 	Compiler[T any] struct {
 		fields []fieldCompiler[T]
+		// envelope is the length header of extensible models, nil otherwise.
+		envelope IntType
+		// tags number the fields, which are only written in tagged models.
+		tags   []fieldTag
+		tagged bool
+		// name replaces the Go type name of models built at runtime.
+		name string
+		// checksum, if set, follows every message.
+		checksum Checksum
 	}
 )
 
@@ -33,7 +48,95 @@ func (c Compiler[T]) Compile(value T, w io.Writer) error {
 	return cw.flush()
 }
 
+// Append appends the encoding of value to dst and returns the extended
+// buffer, like strconv.AppendInt. Messages are written straight into dst,
+// which grows as needed. On failure, dst is returned as it was.
+func (c Compiler[T]) Append(dst []byte, value T) ([]byte, error) {
+	cw := compileWriter{buf: dst}
+	if err := c.compile(value, &cw); err != nil {
+		return dst, err
+	}
+	return cw.buf, nil
+}
+
+// Size returns the exact number of bytes Compile writes for value, without
+// compiling it or allocating buffers, so that buffers can be sized and limits
+// enforced up front. Tagged models are compiled into a pooled buffer instead,
+// since whether their fields are written depends on their bytes.
+func (c Compiler[T]) Size(value T) (int, error) {
+	return c.sizePtr(&value)
+}
+
+// sizePtr is Size for values held elsewhere, e.g. in slices, sparing copies
+// of them.
+func (c Compiler[T]) sizePtr(value *T) (int, error) {
+	if c.tagged {
+		cw := getCompileWriter(nil)
+		defer putCompileWriter(cw)
+		if err := c.compile(*value, cw); err != nil {
+			return 0, err
+		}
+		return len(cw.buf), nil
+	}
+
+	size := 0
+	for _, f := range c.fields {
+		n, err := fieldSize(f, value)
+		if err != nil {
+			return 0, err
+		}
+		size += n
+	}
+
+	if c.envelope != nil {
+		if headerCapacity(c.envelope) < size {
+			return 0, ErrOverflow
+		}
+		header, err := sizeOf(c.envelope, size)
+		if err != nil {
+			return 0, err
+		}
+		size += header
+	}
+
+	if c.checksum != nil {
+		size += checksumByteLength
+	}
+	return size, nil
+}
+
+// fieldSize returns the encoded size of f within item, compiling it aside if
+// f cannot tell.
+func fieldSize[T any](f fieldCompiler[T], item *T) (int, error) {
+	if s, ok := f.(fieldSizer[T]); ok {
+		return s.Size(item)
+	}
+
+	d := discard{}
+	err := f.Compile(item, &d)
+	return d.Size(), err
+}
+
 func (c Compiler[T]) compile(value T, w io.Writer) error {
+	if c.checksum != nil {
+		return c.compileChecked(value, w)
+	}
+	return c.compileBody(value, w)
+}
+
+func (c Compiler[T]) compileBody(value T, w io.Writer) error {
+	if c.envelope != nil {
+		return c.compileEnvelope(value, w)
+	}
+
+	return c.compileFields(value, w)
+}
+
+func (c Compiler[T]) compileFields(value T, w io.Writer) error {
+	if c.tagged {
+		return compileTagged(&value, c.fields, c.tags, w)
+	}
+
 	for _, f := range c.fields {
 		if err := f.Compile(&value, w); err != nil {
 			return err
@@ -43,6 +146,38 @@ func (c Compiler[T]) compile(value T, w io.Writer) error {
 	return nil
 }
 
+// compileEnvelope compiles the fields first to learn the message length,
+// then writes it before the message.
+func (c Compiler[T]) compileEnvelope(value T, w io.Writer) error {
+	if cw, ok := w.(*compileWriter); ok {
+		start := cw.pin()
+		defer cw.unpin()
+		if err := c.compileFields(value, cw); err != nil {
+			return err
+		}
+		return cw.prefixLength(start, c.envelope)
+	}
+
+	body := getCompileWriter(nil)
+	defer putCompileWriter(body)
+
+	if err := c.compileFields(value, body); err != nil {
+		return err
+	}
+
+	size := len(body.buf)
+	if headerCapacity(c.envelope) < size {
+		return ErrOverflow
+	}
+
+	if err := c.envelope.Compile(size, w); err != nil {
+		return err
+	}
+
+	_, err := w.Write(body.buf)
+	return err
+}
+
 func CompilerModel[T any]() *Compiler[T] {
 	return &Compiler[T]{}
 }
@@ -123,6 +258,46 @@ func (c *Compiler[T]) Int(order binary.ByteOrder, getter Getter[T, int]) *Compil
 	return c.register(IntFieldGetter[T](Int(order), getter))
 }
 
+func (c *Compiler[T]) VarUInt8(getter Getter[T, uint8]) *Compiler[T] {
+	return c.register(UInt8FieldGetter[T](VarUInt8(), getter))
+}
+
+func (c *Compiler[T]) VarInt8(getter Getter[T, int8]) *Compiler[T] {
+	return c.register(Int8FieldGetter[T](VarInt8(), getter))
+}
+
+func (c *Compiler[T]) VarUInt16(getter Getter[T, uint16]) *Compiler[T] {
+	return c.register(UInt16FieldGetter[T](VarUInt16(), getter))
+}
+
+func (c *Compiler[T]) VarUInt32(getter Getter[T, uint32]) *Compiler[T] {
+	return c.register(UInt32FieldGetter[T](VarUInt32(), getter))
+}
+
+func (c *Compiler[T]) VarUInt64(getter Getter[T, uint64]) *Compiler[T] {
+	return c.register(UInt64FieldGetter[T](VarUInt64(), getter))
+}
+
+func (c *Compiler[T]) VarInt16(getter Getter[T, int16]) *Compiler[T] {
+	return c.register(Int16FieldGetter[T](VarInt16(), getter))
+}
+
+func (c *Compiler[T]) VarInt32(getter Getter[T, int32]) *Compiler[T] {
+	return c.register(Int32FieldGetter[T](VarInt32(), getter))
+}
+
+func (c *Compiler[T]) VarInt64(getter Getter[T, int64]) *Compiler[T] {
+	return c.register(Int64FieldGetter[T](VarInt64(), getter))
+}
+
+func (c *Compiler[T]) VarInt(getter Getter[T, int]) *Compiler[T] {
+	return c.register(IntFieldGetter[T](VarInt(), getter))
+}
+
+func (c *Compiler[T]) VarUInt(getter Getter[T, uint]) *Compiler[T] {
+	return c.register(UIntFieldGetter[T](VarUInt(), getter))
+}
+
 func (c *Compiler[T]) Float32(order binary.ByteOrder, getter Getter[T, float32]) *Compiler[T] {
 	return c.register(Float32FieldGetter[T](Float32(order), getter))
 }
@@ -151,7 +326,50 @@ func (c *Compiler[T]) Field(f fieldCompiler[T]) *Compiler[T] {
 	return c.register(f)
 }
 
+// Named names the last registered field. Names show up in the Schema.
+func (c *Compiler[T]) Named(name string) *Compiler[T] {
+	if n := len(c.fields); n > 0 {
+		c.fields[n-1] = renamed(c.fields[n-1], name)
+	}
+	return c
+}
+
+// Extensible prefixes every message with its byte length, written with
+// header. See Parser.Extensible.
+func (c *Compiler[T]) Extensible(header IntType) *Compiler[T] {
+	c.envelope = header
+	return c
+}
+
+// Checksum follows every message with the checksum of its bytes, so that
+// parsers detect corrupted or truncated messages. Both peers must use the
+// same checksum.
+func (c *Compiler[T]) Checksum(sum Checksum) *Compiler[T] {
+	c.checksum = sum
+	return c
+}
+
+// Tagged switches to the tagged layout. See Parser.Tagged.
+func (c *Compiler[T]) Tagged() *Compiler[T] {
+	c.tagged = true
+	return c
+}
+
+// Tag numbers the last registered field. See Parser.Tag.
+func (c *Compiler[T]) Tag(num int) *Compiler[T] {
+	if len(c.tags) > 0 {
+		retag(c.tags, num)
+	}
+	return c
+}
+
+// Schema describes the fields of the model in wire order.
+func (c *Compiler[T]) Schema() Schema {
+	return modelSchema[T](c.fields).typed(c.name).enveloped(c.envelope).numbered(c.tagged, c.tags).checksummed(c.checksum)
+}
+
 func (c *Compiler[T]) register(field fieldCompiler[T]) *Compiler[T] {
+	c.tags = append(c.tags, nextTag(c.tags, field))
 	c.fields = append(c.fields, field)
 	return c
 }
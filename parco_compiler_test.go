@@ -9,7 +9,9 @@ import (
 	"strconv"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/vmihailenco/msgpack/v5"
 )
@@ -261,3 +263,125 @@ func TestCompiler_Compile(t *testing.T) {
 		require.ErrorIs(t, err, ErrCannotWrite)
 	})
 }
+
+func TestCompiler_Append(t *testing.T) {
+	order := parseOrder{ID: 7, Items: []parseItem{{"ab", 1}}, Note: &parseItem{"n", 2}}
+	device := deviceV2{ID: 1, Name: "dev", Firmware: 3, Tags: []string{"x"}}
+	conf := settings{Name: "db", Retries: 2, Hosts: []string{"a"}, Limit: Ptr[int16](-1)}
+
+	buf := bytes.NewBuffer(nil)
+	require.NoError(t, parseOrderBuilder().Compile(order, buf))
+	require.NoError(t, deviceV2Builder(ObjectFactory[deviceV2]()).Compile(device, buf))
+	require.NoError(t, settingsBuilder().Compile(conf, buf))
+
+	dst := []byte("frame:")
+	dst, err := parseOrderBuilder().Append(dst, order)
+	require.NoError(t, err)
+	dst, err = deviceV2Builder(ObjectFactory[deviceV2]()).Append(dst, device)
+	require.NoError(t, err)
+	dst, err = settingsBuilder().Append(dst, conf)
+	require.NoError(t, err)
+
+	assert.Equal(t, append([]byte("frame:"), buf.Bytes()...), dst)
+
+	// Failures leave dst as it was.
+	long := parseItem{SKU: strings.Repeat("a", 256)}
+	out, err := parseItemBuilder().Append(dst, long)
+	assert.ErrorIs(t, err, ErrOverflow)
+	assert.Equal(t, dst, out)
+}
+
+func BenchmarkCompiler_Append(b *testing.B) {
+	builder := parseOrderBuilder()
+	order := parseOrder{ID: 7, Items: []parseItem{{"ab", 1}, {"cd", 2}}, Labels: map[string]uint16{"x": 1}}
+	dst := make([]byte, 0, 1024)
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		dst, _ = builder.Append(dst[:0], order)
+	}
+}
+
+// compiledSize compiles value with tp and checks its Size against the bytes.
+func compiledSize[T any](t *testing.T, tp Type[T], value T) {
+	t.Helper()
+	buf := bytes.NewBuffer(nil)
+	require.NoError(t, tp.Compile(value, buf))
+
+	sized, ok := tp.(SizedType[T])
+	require.True(t, ok, "%T is not sized", tp)
+	size, err := sized.Size(value)
+	require.NoError(t, err)
+	assert.Equal(t, buf.Len(), size, "%T", tp)
+}
+
+func TestTypes_Size(t *testing.T) {
+	madrid, err := time.LoadLocation("Europe/Madrid")
+	require.NoError(t, err)
+
+	compiledSize(t, UInt32LE(), 7)
+	compiledSize(t, Bool(), true)
+	compiledSize(t, Float64(binary.LittleEndian), 1.5)
+	compiledSize(t, VarUInt64(), 0)
+	compiledSize(t, VarUInt64(), 1<<40)
+	compiledSize(t, VarInt32(), -70)
+	compiledSize(t, SmallVarchar(), "hello")
+	compiledSize(t, String(VarUIntHeader()), strings.Repeat("a", 300))
+	compiledSize(t, Blob(UInt16HeaderLE()), []byte{1, 2, 3})
+	compiledSize(t, TimeUTC(), time.Now())
+	compiledSize(t, TimeLocation(), time.Now().In(madrid))
+	compiledSize[*string](t, Option(SmallVarchar()), nil)
+	compiledSize(t, Option(SmallVarchar()), Ptr("abc"))
+	compiledSize[Iterable[string]](t, Slice(VarUIntHeader(), SmallVarchar()), SliceView[string]{"a", "bc"})
+	compiledSize[Iterable[uint16]](t, Slice(UInt8Header(), UInt16LE()), SliceView[uint16]{1, 2, 3})
+	compiledSize[Iterable[uint8]](t, Array(2, UInt8()), SliceView[uint8]{1, 2})
+	compiledSize[map[string]uint16](t, MapType(UInt8Header(), SmallVarchar(), UInt16LE()), map[string]uint16{"a": 1, "bcd": 2})
+	compiledSize[parseItem](t, Struct(parseItemBuilder()), parseItem{"sku", 3})
+
+	_, err = SmallVarchar().(SizedType[string]).Size(strings.Repeat("a", 256))
+	assert.ErrorIs(t, err, ErrOverflow)
+	_, err = Array(2, UInt8()).Size(SliceView[uint8]{1})
+	assert.ErrorIs(t, err, ErrInvalidLength)
+}
+
+func TestCompiler_Size(t *testing.T) {
+	sized := func(t *testing.T, size func() (int, error), compile func(*bytes.Buffer) error) {
+		t.Helper()
+		buf := bytes.NewBuffer(nil)
+		require.NoError(t, compile(buf))
+		n, err := size()
+		require.NoError(t, err)
+		assert.Equal(t, buf.Len(), n)
+	}
+
+	order := parseOrder{ID: 7, Items: []parseItem{{"ab", 1}}, Labels: map[string]uint16{"x": 1}, Note: &parseItem{"n", 2}}
+	sized(t, func() (int, error) { return parseOrderBuilder().Size(order) },
+		func(buf *bytes.Buffer) error { return parseOrderBuilder().Compile(order, buf) })
+
+	device := deviceV2{ID: 1, Name: strings.Repeat("d", 200), Tags: []string{"x"}}
+	v2 := deviceV2Builder(ObjectFactory[deviceV2]())
+	sized(t, func() (int, error) { return v2.Size(device) },
+		func(buf *bytes.Buffer) error { return v2.Compile(device, buf) })
+
+	conf := settings{Name: "db", Hosts: []string{"a"}, Seq: 3}
+	sized(t, func() (int, error) { return settingsBuilder().Size(conf) },
+		func(buf *bytes.Buffer) error { return settingsBuilder().Compile(conf, buf) })
+
+	rt := parseOrderRecordType()
+	record := Record{{"ID", 1}, {"Note", Record{{"SKU", "abc"}}}}
+	sized(t, func() (int, error) { return rt.Size(record) },
+		func(buf *bytes.Buffer) error { return rt.Compile(record, buf) })
+
+	_, err := parseItemBuilder().Size(parseItem{SKU: strings.Repeat("a", 256)})
+	assert.ErrorIs(t, err, ErrOverflow)
+}
+
+func BenchmarkCompiler_Size(b *testing.B) {
+	builder := parseOrderBuilder()
+	order := parseOrder{ID: 7, Items: []parseItem{{"ab", 1}, {"cd", 2}}, Labels: map[string]uint16{"x": 1}}
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_, _ = builder.Size(order)
+	}
+}
@@ -0,0 +1,713 @@
+package parco
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type (
+	// DynamicModel parses and compiles the messages of a model described by
+	// a Schema, e.g. one loaded from a schema file, without a Go type. Structs
+	// are held as map[string]any keyed by field name, slices and arrays as
+	// []any, maps as map[any]any and absent options as nil. Integers and
+	// floats parse to the Go type named by their schema.
+	DynamicModel struct {
+		model dynamicStruct[map[string]any]
+	}
+
+	// anyType adapts a Type[T] to the values of dynamic models.
+	anyType[T any] struct {
+		inner Type[T]
+		// to converts a dynamic value to T. nil converts to the zero value.
+		to func(any) (T, error)
+		// from converts a parsed T to a dynamic value, T itself when nil.
+		from func(T) any
+	}
+
+	// dynamicTypes builds the types of dynamic models, holding structs as
+	// Record when records is set, as map[string]any otherwise.
+	dynamicTypes struct {
+		records bool
+	}
+
+	// dynamicStruct is a model built from a KindStruct schema, holding its
+	// values as T.
+	dynamicStruct[T any] struct {
+		schema   Schema
+		parser   *Parser[T]
+		compiler *Compiler[T]
+		repr     structRepr[T]
+	}
+
+	// structRepr is how dynamic structs hold their fields in a T.
+	structRepr[T any] struct {
+		make func(fields int) T
+		get  func(value *T, name string) any
+		set  func(value *T, name string, field any)
+		// to converts a dynamic value to T, checking that it has no field s
+		// does not know.
+		to func(s Schema, value any) (T, error)
+	}
+)
+
+var mapRepr = structRepr[map[string]any]{
+	make: func(fields int) map[string]any { return make(map[string]any, fields) },
+	get:  func(m *map[string]any, name string) any { return (*m)[name] },
+	set:  func(m *map[string]any, name string, field any) { (*m)[name] = field },
+	to: func(s Schema, value any) (map[string]any, error) {
+		if _, err := structFields(s, value); err != nil {
+			return nil, err
+		}
+		fields, _ := value.(map[string]any)
+		if fields == nil {
+			fields = map[string]any{}
+		}
+		return fields, nil
+	},
+}
+
+// Dynamic builds the model described by s, which must be a KindStruct schema
+// made of built-in types. Fields are parsed and compiled by the same types
+// the builders use, so messages are byte for byte those of the Go models.
+func Dynamic(s Schema) (*DynamicModel, error) {
+	if s.Kind != KindStruct {
+		return nil, fmt.Errorf("%w: %s is not a struct", ErrInvalidSchema, s.TypeString())
+	}
+
+	model, err := newDynamicStruct(dynamicTypes{}, s, mapRepr)
+	if err != nil {
+		return nil, err
+	}
+	return &DynamicModel{model: model}, nil
+}
+
+func (m *DynamicModel) Parse(r io.Reader) (map[string]any, error) {
+	return m.model.parser.Parse(r)
+}
+
+func (m *DynamicModel) ParseBytes(data []byte) (map[string]any, error) {
+	return m.model.parser.ParseBytes(data)
+}
+
+// Compile writes value, whose keys must be field names of the model. Missing
+// fields are written as zero values.
+func (m *DynamicModel) Compile(value map[string]any, w io.Writer) error {
+	return m.model.Compile(value, w)
+}
+
+// Size returns the encoded size of value. See Compiler.Size.
+func (m *DynamicModel) Size(value map[string]any) (int, error) {
+	return m.model.Size(value)
+}
+
+func (m *DynamicModel) ParseAny(r io.Reader) (any, error) {
+	return m.Parse(r)
+}
+
+func (m *DynamicModel) CompileAny(value any, w io.Writer) error {
+	return m.model.Compile(value, w)
+}
+
+// Schema describes the model, as given to Dynamic.
+func (m *DynamicModel) Schema() Schema {
+	return m.model.Schema()
+}
+
+func (t anyType[T]) ByteLength() int {
+	return t.inner.ByteLength()
+}
+
+func (t anyType[T]) Schema() Schema {
+	return Describe(t.inner)
+}
+
+func (t anyType[T]) Parse(r io.Reader) (any, error) {
+	value, err := t.inner.Parse(r)
+	if err != nil {
+		return nil, err
+	}
+	if t.from != nil {
+		return t.from(value), nil
+	}
+	return value, nil
+}
+
+func (t anyType[T]) Size(value any) (int, error) {
+	x, err := t.to(value)
+	if err != nil {
+		return 0, err
+	}
+	return sizeOf(t.inner, x)
+}
+
+func (t anyType[T]) Compile(value any, w io.Writer) error {
+	x, err := t.to(value)
+	if err != nil {
+		return err
+	}
+	return t.inner.Compile(x, w)
+}
+
+func newDynamicStruct[T any](d dynamicTypes, s Schema, repr structRepr[T]) (dynamicStruct[T], error) {
+	parser := ParserModel[T](FuncFactory[T](func() T {
+		return repr.make(len(s.Fields))
+	}))
+	compiler := CompilerModel[T]()
+	parser.name, compiler.name = s.Type, s.Type
+
+	if s.Header != nil {
+		header, err := intType(*s.Header)
+		if err != nil {
+			return dynamicStruct[T]{}, fmt.Errorf("%s: %w", If(s.Type != "", s.Type, "struct"), err)
+		}
+		parser.Extensible(header)
+		compiler.Extensible(header)
+	}
+	if s.Tagged {
+		parser.Tagged()
+		compiler.Tagged()
+	}
+	if s.Checksum != "" {
+		sum, err := checksumNamed(s.Checksum)
+		if err != nil {
+			return dynamicStruct[T]{}, fmt.Errorf("%s: %w", If(s.Type != "", s.Type, "struct"), err)
+		}
+		parser.Checksum(sum)
+		compiler.Checksum(sum)
+	}
+
+	// Numbers are checked here, as Tag panics on duplicates.
+	tags := make(map[int]string, len(s.Fields))
+	last := 0
+	for i, f := range s.Fields {
+		name := schemaFieldName(f.Name, i)
+
+		tp, err := d.typ(f)
+		if err != nil {
+			return dynamicStruct[T]{}, fmt.Errorf("%s: %w", joinPath(s.Type, name), err)
+		}
+		field := FixedField[T, any]{
+			Id:     f.Name,
+			Type:   tp,
+			Setter: func(value *T, field any) { repr.set(value, name, field) },
+			Getter: func(value *T) any { return repr.get(value, name) },
+		}
+		if f.Kind == KindSkip {
+			field.Setter = func(*T, any) {}
+		}
+		parser.Field(field)
+		compiler.Field(field)
+
+		if !s.Tagged {
+			continue
+		}
+		num := If(f.Tag > 0, f.Tag, last+1)
+		if num < 1 || num > maxTagNumber {
+			return dynamicStruct[T]{}, fmt.Errorf("%w: %s: field number out of range: %d", ErrInvalidSchema, joinPath(s.Type, name), num)
+		}
+		if other, ok := tags[num]; ok {
+			return dynamicStruct[T]{}, fmt.Errorf("%w: %s: field number %d is used by %s", ErrInvalidSchema, joinPath(s.Type, name), num, other)
+		}
+		tags[num] = name
+		last = max(last, num)
+		parser.Tag(num)
+		compiler.Tag(num)
+	}
+
+	return dynamicStruct[T]{schema: s, parser: parser, compiler: compiler, repr: repr}, nil
+}
+
+// ByteLength returns the encoded width of the struct, or -1 if it varies.
+func (t dynamicStruct[T]) ByteLength() int {
+	return fixedWidth(t.schema)
+}
+
+func (t dynamicStruct[T]) Schema() Schema {
+	return t.parser.Schema()
+}
+
+func (t dynamicStruct[T]) Parse(r io.Reader) (any, error) {
+	return t.parser.Parse(r)
+}
+
+func (t dynamicStruct[T]) Size(value any) (int, error) {
+	fields, err := t.repr.to(t.schema, value)
+	if err != nil {
+		return 0, err
+	}
+	return t.compiler.Size(fields)
+}
+
+func (t dynamicStruct[T]) Compile(value any, w io.Writer) error {
+	fields, err := t.repr.to(t.schema, value)
+	if err != nil {
+		return err
+	}
+	return t.compiler.Compile(fields, w)
+}
+
+// typ returns the type described by s, holding dynamic values.
+func (d dynamicTypes) typ(s Schema) (Type[any], error) {
+	switch s.Kind {
+	case KindFixed:
+		return dynamicFixed(s)
+	case KindVarint:
+		return dynamicVarint(s)
+	case KindVarchar:
+		header, err := schemaHeader(s)
+		if err != nil {
+			return nil, err
+		}
+		if s.Type == "bytes" {
+			return anyType[[]byte]{inner: Blob(header), to: toBytes}, nil
+		}
+		return anyType[string]{inner: String(header), to: assertValue[string]("string")}, nil
+	case KindSlice, KindArray, KindChunked:
+		return d.list(s)
+	case KindMap:
+		return d.dict(s)
+	case KindOption:
+		return d.option(s)
+	case KindStruct:
+		if d.records {
+			t, err := newDynamicStruct(d, s, recordRepr)
+			return t, err
+		}
+		t, err := newDynamicStruct(d, s, mapRepr)
+		return t, err
+	case KindTime:
+		return anyType[time.Time]{inner: If(s.Location, TimeLocation(), TimeUTC()), to: assertValue[time.Time]("time")}, nil
+	case KindSkip:
+		return SkipType(s.ByteLength), nil
+	}
+	return nil, fmt.Errorf("%s: %w", s.TypeString(), ErrUnknownType)
+}
+
+func dynamicFixed(s Schema) (Type[any], error) {
+	order := s.Order
+	if order == nil {
+		order = binary.LittleEndian
+	}
+
+	switch s.Type {
+	case "bool":
+		return anyType[bool]{inner: Bool(), to: assertValue[bool]("bool")}, nil
+	case "float32":
+		return anyType[float32]{inner: Float32(order), to: func(value any) (float32, error) {
+			f, err := toFloat64(value)
+			return float32(f), err
+		}}, nil
+	case "float64":
+		return anyType[float64]{inner: Float64(order), to: toFloat64}, nil
+	case "uint8":
+		return dynamicInt(UInt8(), s.Type), nil
+	case "int8":
+		return dynamicInt(Int8(), s.Type), nil
+	case "uint16":
+		return dynamicInt(UInt16(order), s.Type), nil
+	case "int16":
+		return dynamicInt(Int16(order), s.Type), nil
+	case "uint32":
+		return dynamicInt(UInt32(order), s.Type), nil
+	case "int32":
+		return dynamicInt(Int32(order), s.Type), nil
+	case "uint64":
+		return dynamicInt(UInt64(order), s.Type), nil
+	case "int64":
+		return dynamicInt(Int64(order), s.Type), nil
+	case "uint":
+		return dynamicInt(UInt(order), s.Type), nil
+	case "int":
+		return dynamicInt(Int(order), s.Type), nil
+	}
+	return nil, fmt.Errorf("%s: %w", s.TypeString(), ErrUnknownType)
+}
+
+func dynamicVarint(s Schema) (Type[any], error) {
+	switch s.Type {
+	case "uint8":
+		return dynamicInt(VarUInt8(), s.Type), nil
+	case "int8":
+		return dynamicInt(VarInt8(), s.Type), nil
+	case "uint16":
+		return dynamicInt(VarUInt16(), s.Type), nil
+	case "int16":
+		return dynamicInt(VarInt16(), s.Type), nil
+	case "uint32":
+		return dynamicInt(VarUInt32(), s.Type), nil
+	case "int32":
+		return dynamicInt(VarInt32(), s.Type), nil
+	case "uint64":
+		return dynamicInt(VarUInt64(), s.Type), nil
+	case "int64":
+		return dynamicInt(VarInt64(), s.Type), nil
+	case "uint":
+		return dynamicInt(VarUInt(), s.Type), nil
+	case "int":
+		return dynamicInt(VarInt(), s.Type), nil
+	}
+	return nil, fmt.Errorf("%s: %w", s.TypeString(), ErrUnknownType)
+}
+
+// dynamicInt converts dynamic values to the integer type named tp, checking
+// that they fit.
+func dynamicInt[T integer](inner Type[T], tp string) Type[any] {
+	bits := typeBits(tp)
+	if strings.HasPrefix(tp, "int") {
+		return anyType[T]{inner: inner, to: func(value any) (T, error) {
+			i, err := toInt64(value, bits)
+			return T(i), err
+		}}
+	}
+	return anyType[T]{inner: inner, to: func(value any) (T, error) {
+		u, err := toUint64(value, bits)
+		return T(u), err
+	}}
+}
+
+func (d dynamicTypes) list(s Schema) (Type[any], error) {
+	if s.Elem == nil {
+		return nil, fmt.Errorf("%w: %s without elements", ErrInvalidSchema, s.Kind)
+	}
+	elem, err := d.typ(*s.Elem)
+	if err != nil {
+		return nil, err
+	}
+
+	to := func(value any) (Iterable[any], error) {
+		switch v := value.(type) {
+		case nil:
+			return SliceView[any]{}, nil
+		case []any:
+			return SliceView[any](v), nil
+		case Iterable[any]:
+			return v, nil
+		}
+		return nil, typeMismatch("list", value)
+	}
+	from := func(values Iterable[any]) any {
+		return []any(values.Unwrap())
+	}
+
+	if s.Kind == KindArray {
+		return anyType[Iterable[any]]{inner: Array(s.Length, elem), to: to, from: from}, nil
+	}
+
+	header, err := schemaHeader(s)
+	if err != nil {
+		return nil, err
+	}
+	if s.Kind == KindChunked {
+		return anyType[Iterable[any]]{inner: Chunked(header, DefaultChunkLength, elem), to: to, from: from}, nil
+	}
+	return anyType[Iterable[any]]{inner: Slice(header, elem), to: to, from: from}, nil
+}
+
+// comparableKey reports whether values of s parse to comparable values that
+// can key a map[any]any.
+func comparableKey(s Schema) bool {
+	switch {
+	case s.Kind == KindFixed, s.Kind == KindVarint, s.Kind == KindTime:
+		return true
+	case s.Kind == KindVarchar && s.Type != "bytes":
+		return true
+	}
+	return false
+}
+
+func (d dynamicTypes) dict(s Schema) (Type[any], error) {
+	if s.Key == nil || s.Elem == nil {
+		return nil, fmt.Errorf("%w: map without key or value", ErrInvalidSchema)
+	}
+	if !comparableKey(*s.Key) {
+		return nil, fmt.Errorf("%w: map keys of type %s", ErrInvalidSchema, s.Key.TypeString())
+	}
+
+	header, err := schemaHeader(s)
+	if err != nil {
+		return nil, err
+	}
+	key, err := d.typ(*s.Key)
+	if err != nil {
+		return nil, err
+	}
+	value, err := d.typ(*s.Elem)
+	if err != nil {
+		return nil, err
+	}
+
+	return anyType[map[any]any]{
+		inner: MapType(header, key, value),
+		to: func(value any) (map[any]any, error) {
+			switch v := value.(type) {
+			case nil:
+				return map[any]any{}, nil
+			case map[any]any:
+				return v, nil
+			case map[string]any:
+				m := make(map[any]any, len(v))
+				for k, x := range v {
+					m[k] = x
+				}
+				return m, nil
+			}
+			return nil, typeMismatch("map", value)
+		},
+	}, nil
+}
+
+func (d dynamicTypes) option(s Schema) (Type[any], error) {
+	if s.Elem == nil {
+		return nil, fmt.Errorf("%w: option without value", ErrInvalidSchema)
+	}
+	elem, err := d.typ(*s.Elem)
+	if err != nil {
+		return nil, err
+	}
+
+	return anyType[*any]{
+		inner: Option(elem),
+		to: func(value any) (*any, error) {
+			if value == nil {
+				return nil, nil
+			}
+			return &value, nil
+		},
+		from: func(value *any) any {
+			if value == nil {
+				return nil
+			}
+			return *value
+		},
+	}, nil
+}
+
+// schemaHeader returns the length header of s.
+func schemaHeader(s Schema) (IntType, error) {
+	if s.Header == nil {
+		return nil, fmt.Errorf("%w: %s without header", ErrInvalidSchema, s.Kind)
+	}
+	return intType(*s.Header)
+}
+
+// intType returns the integer type described by s, as used for headers.
+func intType(s Schema) (IntType, error) {
+	be := s.Order == binary.BigEndian
+
+	switch {
+	case s.Kind == KindVarint && s.Type == "uint":
+		return VarUIntHeader(), nil
+	case s.Kind == KindVarint && s.Type == "int":
+		return VarIntHeader(), nil
+	case s.Kind != KindFixed:
+	case s.Type == "uint8":
+		return UInt8Header(), nil
+	case s.Type == "int8":
+		return Int8Header(), nil
+	case s.Type == "uint16":
+		return If(be, UInt16HeaderBE(), UInt16HeaderLE()), nil
+	case s.Type == "int16":
+		return If(be, Int16BEHeader(), Int16LEHeader()), nil
+	case s.Type == "uint32":
+		return If(be, UInt32BEHeader(), UInt32LEHeader()), nil
+	case s.Type == "int32":
+		return If(be, Int32BEHeader(), Int32LEHeader()), nil
+	case s.Type == "uint64":
+		return If(be, UInt64BEHeader(), UInt64LEHeader()), nil
+	case s.Type == "int64":
+		return If(be, Int64BEHeader(), Int64LEHeader()), nil
+	case s.Type == "uint":
+		return If(be, UIntBEHeader(), UIntLEHeader()), nil
+	case s.Type == "int":
+		return If(be, IntBEHeader(), IntLEHeader()), nil
+	}
+
+	return nil, fmt.Errorf("%s: %w", s.TypeString(), ErrUnknownType)
+}
+
+// assertValue converts dynamic values holding a T, or nil for its zero value.
+func assertValue[T any](name string) func(any) (T, error) {
+	return func(value any) (T, error) {
+		var zero T
+		if value == nil {
+			return zero, nil
+		}
+		v, ok := value.(T)
+		if !ok {
+			return zero, typeMismatch(name, value)
+		}
+		return v, nil
+	}
+}
+
+// structFields returns a lookup of the fields of a struct value, checking
+// that it has no field s does not know.
+func structFields(s Schema, value any) (func(name string) any, error) {
+	known := func(name string) bool {
+		for i, f := range s.Fields {
+			if schemaFieldName(f.Name, i) == name {
+				return true
+			}
+		}
+		return false
+	}
+
+	switch v := value.(type) {
+	case nil:
+		return func(string) any { return nil }, nil
+	case map[string]any:
+		for name := range v {
+			if !known(name) {
+				return nil, NewErrFieldNotFoundError(name)
+			}
+		}
+		return func(name string) any { return v[name] }, nil
+	case jsonObject:
+		for _, m := range v {
+			if !known(m.key) {
+				return nil, NewErrFieldNotFoundError(m.key)
+			}
+		}
+		return v.get, nil
+	}
+	return nil, typeMismatch("struct", value)
+}
+
+func toInt64(value any, bits int) (int64, error) {
+	var i int64
+	switch v := value.(type) {
+	case nil:
+	case int8:
+		i = int64(v)
+	case int16:
+		i = int64(v)
+	case int32:
+		i = int64(v)
+	case int64:
+		i = v
+	case int:
+		i = int64(v)
+	case uint8, uint16, uint32, uint64, uint:
+		u, err := toUint64(v, 64)
+		if err != nil {
+			return 0, err
+		}
+		if u > math.MaxInt64 {
+			return 0, ErrOverflow
+		}
+		i = int64(u)
+	case float64:
+		if v != math.Trunc(v) || v < math.MinInt64 || v >= math.MaxInt64 {
+			return 0, ErrOverflow
+		}
+		i = int64(v)
+	case json.Number:
+		var err error
+		if i, err = strconv.ParseInt(string(v), 10, 64); err != nil {
+			return 0, fmt.Errorf("%s: %w", v, ErrOverflow)
+		}
+	default:
+		return 0, typeMismatch("integer", value)
+	}
+
+	if bits < 64 && (i < -1<<(bits-1) || i >= 1<<(bits-1)) {
+		return 0, ErrOverflow
+	}
+	return i, nil
+}
+
+func toUint64(value any, bits int) (uint64, error) {
+	var u uint64
+	switch v := value.(type) {
+	case nil:
+	case uint8:
+		u = uint64(v)
+	case uint16:
+		u = uint64(v)
+	case uint32:
+		u = uint64(v)
+	case uint64:
+		u = v
+	case uint:
+		u = uint64(v)
+	case int8, int16, int32, int64, int:
+		i, err := toInt64(v, 64)
+		if err != nil {
+			return 0, err
+		}
+		if i < 0 {
+			return 0, ErrOverflow
+		}
+		u = uint64(i)
+	case float64:
+		if v != math.Trunc(v) || v < 0 || v >= math.MaxUint64 {
+			return 0, ErrOverflow
+		}
+		u = uint64(v)
+	case json.Number:
+		var err error
+		if u, err = strconv.ParseUint(string(v), 10, 64); err != nil {
+			return 0, fmt.Errorf("%s: %w", v, ErrOverflow)
+		}
+	default:
+		return 0, typeMismatch("unsigned integer", value)
+	}
+
+	if bits < 64 && u >= 1<<bits {
+		return 0, ErrOverflow
+	}
+	return u, nil
+}
+
+func toFloat64(value any) (float64, error) {
+	switch v := value.(type) {
+	case nil:
+		return 0, nil
+	case float32:
+		return float64(v), nil
+	case float64:
+		return v, nil
+	case json.Number:
+		return v.Float64()
+	}
+
+	if i, err := toInt64(value, 64); err == nil {
+		return float64(i), nil
+	}
+	if u, err := toUint64(value, 64); err == nil {
+		return float64(u), nil
+	}
+	return 0, typeMismatch("number", value)
+}
+
+func toBytes(value any) ([]byte, error) {
+	switch v := value.(type) {
+	case nil:
+		return nil, nil
+	case []byte:
+		return v, nil
+	case string:
+		return base64.StdEncoding.DecodeString(v)
+	}
+	return nil, typeMismatch("bytes", value)
+}
+
+// typeBits is the bit size of the integer type named tp.
+func typeBits(tp string) int {
+	bits, err := strconv.Atoi(strings.TrimLeft(tp, "uint"))
+	if err != nil {
+		return 8 * uintBitSize
+	}
+	return bits
+}
+
+func typeMismatch(want string, have any) error {
+	return NewErrTypeAssertionError(want, fmt.Sprintf("%T", have))
+}
@@ -0,0 +1,186 @@
+package parco
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDynamic_MatchesBuilders(t *testing.T) {
+	order := parseOrder{
+		ID:     42,
+		Items:  []parseItem{{"ab", 7}, {"c", 8}},
+		Labels: map[string]uint16{"x": 1},
+		Note:   &parseItem{"n", 5},
+	}
+	data := compileParseOrder(t, order)
+
+	m, err := Dynamic(Describe(parseOrderBuilder()))
+	require.NoError(t, err)
+
+	value, err := m.ParseBytes(data)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{
+		"ID": uint16(42),
+		"Items": []any{
+			map[string]any{"SKU": "ab", "Price": uint32(7)},
+			map[string]any{"SKU": "c", "Price": uint32(8)},
+		},
+		"Labels": map[any]any{"x": uint16(1)},
+		"Note":   map[string]any{"SKU": "n", "Price": uint32(5)},
+	}, value)
+
+	buf := bytes.NewBuffer(nil)
+	require.NoError(t, m.Compile(value, buf))
+	assert.Equal(t, data, buf.Bytes())
+
+	assert.Equal(t, Describe(parseOrderBuilder()), m.Schema())
+}
+
+func TestDynamic_ByteLength(t *testing.T) {
+	fixed, err := Dynamic(Schema{Kind: KindStruct, Type: "Point", Fields: []Schema{
+		jsonField("X", Int32LE()),
+		jsonField("Y", Int32LE()),
+		jsonField("At", TimeUTC()),
+	}})
+	require.NoError(t, err)
+	assert.Equal(t, 8+timeByteLength, fixed.model.ByteLength())
+
+	checked, err := Dynamic(Schema{Kind: KindStruct, Type: "Point", Checksum: "crc32c", Fields: []Schema{
+		jsonField("X", Int32LE()),
+	}})
+	require.NoError(t, err)
+	assert.Equal(t, 4+checksumByteLength, checked.model.ByteLength())
+
+	varying, err := Dynamic(Describe(parseOrderBuilder()))
+	require.NoError(t, err)
+	assert.Equal(t, -1, varying.model.ByteLength())
+}
+
+func TestDynamic_TaggedAndExtensible(t *testing.T) {
+	for name, tc := range map[string]struct {
+		builder Describer
+		compile func(w *bytes.Buffer) error
+	}{
+		"tagged": {
+			builder: settingsBuilder(),
+			compile: func(w *bytes.Buffer) error {
+				return settingsBuilder().Compile(settings{Name: "db", Ratio: 0.5, Hosts: []string{"a"}, Limit: Ptr[int16](-3), Seq: 1}, w)
+			},
+		},
+		"extensible": {
+			builder: deviceV2Builder(ObjectFactory[deviceV2]()),
+			compile: func(w *bytes.Buffer) error {
+				return deviceV2Builder(ObjectFactory[deviceV2]()).Compile(deviceV2{ID: 1, Name: "a", Firmware: 3, Tags: []string{"x"}}, w)
+			},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			buf := bytes.NewBuffer(nil)
+			require.NoError(t, tc.compile(buf))
+
+			m, err := Dynamic(Describe(tc.builder))
+			require.NoError(t, err)
+
+			value, err := m.Parse(bytes.NewReader(buf.Bytes()))
+			require.NoError(t, err)
+
+			out := bytes.NewBuffer(nil)
+			require.NoError(t, m.Compile(value, out))
+			assert.Equal(t, buf.Bytes(), out.Bytes())
+		})
+	}
+}
+
+func TestDynamic_Values(t *testing.T) {
+	file, err := ParseSchemaFile([]byte(`
+message event {
+  At     time
+  Blob   bytes<uint8>
+  Delta  varint16
+  Ratio  float32 BE
+  Flags  array(2)[bool]
+  skip(1)
+  Counts map<uint8>[uint16]varuint32
+  Maybe  option[int8]
+}`))
+	require.NoError(t, err)
+
+	m, err := Dynamic(file.Models[0])
+	require.NoError(t, err)
+
+	at := time.Date(2024, 5, 1, 8, 0, 0, 0, time.UTC)
+
+	// Values of other Go types are converted when they fit.
+	buf := bytes.NewBuffer(nil)
+	require.NoError(t, m.Compile(map[string]any{
+		"At":     at,
+		"Blob":   []byte{1, 2},
+		"Delta":  -300,
+		"Ratio":  1.5,
+		"Flags":  []any{true, false},
+		"Counts": map[any]any{7: 70000},
+		"Maybe":  int8(-1),
+	}, buf))
+
+	value, err := m.ParseBytes(buf.Bytes())
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{
+		"At":     at,
+		"Blob":   []byte{1, 2},
+		"Delta":  int16(-300),
+		"Ratio":  float32(1.5),
+		"Flags":  []any{true, false},
+		"Counts": map[any]any{uint16(7): uint32(70000)},
+		"Maybe":  int8(-1),
+	}, value)
+
+	// Missing fields are zero.
+	buf.Reset()
+	require.NoError(t, m.Compile(map[string]any{"Flags": []any{false, true}}, buf))
+	value, err = m.ParseBytes(buf.Bytes())
+	require.NoError(t, err)
+	assert.Equal(t, int16(0), value["Delta"])
+	assert.Nil(t, value["Maybe"])
+}
+
+func TestDynamic_Errors(t *testing.T) {
+	m, err := Dynamic(Describe(parseOrderBuilder()))
+	require.NoError(t, err)
+
+	w := bytes.NewBuffer(nil)
+
+	var notFound ErrFieldNotFound
+	assert.ErrorAs(t, m.Compile(map[string]any{"Extra": 1}, w), &notFound)
+	assert.ErrorIs(t, m.Compile(map[string]any{"ID": 70000}, w), ErrOverflow)
+
+	var mismatch ErrTypeAssertion
+	assert.ErrorAs(t, m.Compile(map[string]any{"Items": []any{map[string]any{"SKU": 1}}}, w), &mismatch)
+	assert.ErrorAs(t, m.Compile(map[string]any{"Items": "a"}, w), &mismatch)
+
+	data := compileParseOrder(t, parseOrder{ID: 1, Note: &parseItem{"n", 5}})
+	_, err = m.ParseBytes(data[:len(data)-1])
+	var pe *ParseError
+	require.ErrorAs(t, err, &pe)
+	assert.Equal(t, "parseOrder.Note.Price", pe.Path)
+
+	_, err = Dynamic(Describe(UInt8()))
+	assert.ErrorIs(t, err, ErrInvalidSchema)
+
+	_, err = Dynamic(Schema{Kind: KindStruct, Fields: []Schema{{Name: "a", Kind: KindFixed, Type: "complex64"}}})
+	assert.ErrorIs(t, err, ErrUnknownType)
+
+	_, err = Dynamic(Schema{Kind: KindStruct, Fields: []Schema{{
+		Name: "a", Kind: KindMap, Header: describePtr(UInt8Header()), Key: describePtr(Blob(UInt8Header())), Elem: describePtr(Bool()),
+	}}})
+	assert.ErrorIs(t, err, ErrInvalidSchema)
+
+	_, err = Dynamic(Schema{Kind: KindStruct, Tagged: true, Fields: []Schema{
+		{Name: "a", Kind: KindFixed, Type: "bool", Tag: 2},
+		{Name: "b", Kind: KindFixed, Type: "bool", Tag: 2},
+	}})
+	assert.ErrorIs(t, err, ErrInvalidSchema)
+}
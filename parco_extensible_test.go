@@ -0,0 +1,181 @@
+package parco
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type (
+	deviceV1 struct {
+		ID   uint16
+		Name string
+	}
+
+	deviceV2 struct {
+		ID       uint16
+		Name     string
+		Firmware uint32
+		Tags     []string
+	}
+)
+
+func deviceV1Builder() ModelBuilder[deviceV1] {
+	return Builder[deviceV1](ObjectFactory[deviceV1]()).
+		Extensible(VarUIntHeader()).
+		UInt16LE(
+			func(d *deviceV1) uint16 { return d.ID },
+			func(d *deviceV1, v uint16) { d.ID = v },
+		).Named("ID").
+		SmallVarchar(
+			func(d *deviceV1) string { return d.Name },
+			func(d *deviceV1, v string) { d.Name = v },
+		).Named("Name")
+}
+
+func deviceV2Builder(factory Factory[deviceV2]) ModelBuilder[deviceV2] {
+	return Builder[deviceV2](factory).
+		Extensible(VarUIntHeader()).
+		UInt16LE(
+			func(d *deviceV2) uint16 { return d.ID },
+			func(d *deviceV2, v uint16) { d.ID = v },
+		).Named("ID").
+		SmallVarchar(
+			func(d *deviceV2) string { return d.Name },
+			func(d *deviceV2, v string) { d.Name = v },
+		).Named("Name").
+		UInt32(binary.LittleEndian,
+			func(d *deviceV2) uint32 { return d.Firmware },
+			func(d *deviceV2, v uint32) { d.Firmware = v },
+		).Named("Firmware").
+		Slice(SliceField[deviceV2, string](
+			UInt8Header(),
+			SmallVarchar(),
+			func(d *deviceV2, v SliceView[string]) { d.Tags = v },
+			func(d *deviceV2) SliceView[string] { return d.Tags },
+		)).Named("Tags")
+}
+
+func TestExtensible_OldParserSkipsNewFields(t *testing.T) {
+	buf := bytes.NewBuffer(nil)
+	v2 := deviceV2Builder(ObjectFactory[deviceV2]())
+
+	require.NoError(t, v2.Compile(deviceV2{ID: 1, Name: "a", Firmware: 9, Tags: []string{"x"}}, buf))
+	require.NoError(t, v2.Compile(deviceV2{ID: 2, Name: "b", Firmware: 10}, buf))
+
+	v1 := deviceV1Builder()
+
+	first, err := v1.Parse(buf)
+	require.NoError(t, err)
+	assert.Equal(t, deviceV1{ID: 1, Name: "a"}, first)
+
+	// The stream stays in sync: unknown bytes of the first message are gone.
+	second, err := v1.Parse(buf)
+	require.NoError(t, err)
+	assert.Equal(t, deviceV1{ID: 2, Name: "b"}, second)
+	assert.Zero(t, buf.Len())
+}
+
+func TestExtensible_NewParserDefaultsMissingFields(t *testing.T) {
+	buf := bytes.NewBuffer(nil)
+	require.NoError(t, deviceV1Builder().Compile(deviceV1{ID: 7, Name: "old"}, buf))
+
+	defaults := FuncFactory[deviceV2](func() deviceV2 { return deviceV2{Firmware: 1} })
+
+	device, err := deviceV2Builder(defaults).Parse(buf)
+	require.NoError(t, err)
+	assert.Equal(t, deviceV2{ID: 7, Name: "old", Firmware: 1}, device)
+}
+
+func TestExtensible_Wire(t *testing.T) {
+	buf := bytes.NewBuffer(nil)
+	require.NoError(t, deviceV1Builder().Compile(deviceV1{ID: 1, Name: "ab"}, buf))
+
+	assert.Equal(t, []byte{5, 1, 0, 2, 'a', 'b'}, buf.Bytes())
+}
+
+func TestExtensible_Nested(t *testing.T) {
+	type fleet struct {
+		Lead deviceV2
+		Size uint8
+	}
+
+	newFleet := func(device ModelBuilder[deviceV2]) ModelBuilder[fleet] {
+		return Builder[fleet](ObjectFactory[fleet]()).
+			Struct(StructField[fleet, deviceV2](
+				func(f *fleet) deviceV2 { return f.Lead },
+				func(f *fleet, v deviceV2) { f.Lead = v },
+				device,
+			)).
+			UInt8(
+				func(f *fleet) uint8 { return f.Size },
+				func(f *fleet, v uint8) { f.Size = v },
+			)
+	}
+
+	// The sender's device model is older than the receiver's.
+	v1 := Builder[deviceV2](ObjectFactory[deviceV2]()).
+		Extensible(VarUIntHeader()).
+		UInt16LE(
+			func(d *deviceV2) uint16 { return d.ID },
+			func(d *deviceV2, v uint16) { d.ID = v },
+		)
+
+	buf := bytes.NewBuffer(nil)
+	require.NoError(t, newFleet(v1).Compile(fleet{Lead: deviceV2{ID: 3, Name: "ignored"}, Size: 4}, buf))
+
+	res, err := newFleet(deviceV2Builder(ObjectFactory[deviceV2]())).Parse(buf)
+	require.NoError(t, err)
+	assert.Equal(t, fleet{Lead: deviceV2{ID: 3}, Size: 4}, res)
+}
+
+func TestExtensible_Errors(t *testing.T) {
+	v1 := deviceV1Builder()
+
+	// Length 3 cuts the varchar short.
+	_, err := v1.Parse(bytes.NewReader([]byte{3, 1, 0, 2}))
+	assert.ErrorIs(t, err, ErrCannotRead)
+
+	_, err = v1.Parse(bytes.NewReader([]byte{10, 1, 0}))
+	assert.ErrorIs(t, err, ErrCannotRead)
+
+	small := Builder[deviceV1](ObjectFactory[deviceV1]()).
+		Extensible(UInt8Header()).
+		Varchar(
+			func(d *deviceV1) string { return d.Name },
+			func(d *deviceV1, v string) { d.Name = v },
+		)
+	err = small.Compile(deviceV1{Name: string(make([]byte, 300))}, bytes.NewBuffer(nil))
+	assert.ErrorIs(t, err, ErrOverflow)
+}
+
+func TestExtensible_Compatibility(t *testing.T) {
+	v1 := deviceV1Builder().Schema()
+	v2 := deviceV2Builder(ObjectFactory[deviceV2]()).Schema()
+
+	assert.Equal(t, "struct<varuint> deviceV1", v1.TypeString())
+
+	report := CheckCompatibility(v1, v2)
+	assert.NoError(t, report.Err())
+	assert.Len(t, report.Changes, 2)
+
+	report = CheckCompatibility(v2, v1)
+	assert.NoError(t, report.Err())
+
+	positional := Builder[deviceV1](ObjectFactory[deviceV1]()).
+		UInt16LE(
+			func(d *deviceV1) uint16 { return d.ID },
+			func(d *deviceV1, v uint16) { d.ID = v },
+		).Named("ID").
+		SmallVarchar(
+			func(d *deviceV1) string { return d.Name },
+			func(d *deviceV1, v string) { d.Name = v },
+		).Named("Name")
+
+	report = CheckCompatibility(positional.Schema(), v1)
+	require.Len(t, report.Breaking(), 1)
+	assert.Equal(t, HeaderChanged, report.Breaking()[0].Type)
+}
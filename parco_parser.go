@@ -14,6 +14,17 @@ type (
 	Parser[T any] struct {
 		fields  []fieldParser[T]
 		factory Factory[T]
+		// envelope is the length header of extensible models, nil otherwise.
+		envelope IntType
+		// tags number the fields, which are only written in tagged models.
+		tags   []fieldTag
+		tagged bool
+		// name replaces the Go type name of models built at runtime.
+		name string
+		// checksum, if set, follows every message.
+		checksum Checksum
+		// limits, if set, bound the messages parsed.
+		limits *DecodeLimits
 	}
 )
 
@@ -27,20 +38,145 @@ func (p *Parser[T]) ParseBytes(data []byte) (T, error) {
 	return p.parse(&buf)
 }
 
+// ParseBytesZeroCopy is like ParseBytes, but strings and blobs of the model
+// alias data instead of copying it. See NewZeroCopyBufferCursor for how long
+// they remain valid.
+func (p *Parser[T]) ParseBytesZeroCopy(data []byte) (T, error) {
+	buf := NewZeroCopyBufferCursor(data, 0)
+
+	return p.parse(&buf)
+}
+
 func (p *Parser[T]) Parse(r io.Reader) (T, error) {
 	return p.parse(r)
 }
 
-func (p *Parser[T]) parse(r io.Reader) (T, error) {
-	model := p.factory.Get()
+// Scan reads the messages concatenated in r one by one. See Scanner.
+func (p *Parser[T]) Scan(r io.Reader) *Scanner[T] {
+	return newScanner(r, p.Parse)
+}
+
+// parse reads one message. Failures are reported as *ParseError, located
+// from the outermost model being parsed.
+func (p *Parser[T]) parse(r io.Reader) (model T, err error) {
+	m, ok := r.(*messageReader)
+	if !ok {
+		m = getMessageReader(r, 0)
+		defer putMessageReader(m)
+		if p.limits != nil {
+			m.limit(*p.limits)
+		}
+	}
+
+	if m.budget != nil {
+		defer m.budget.leave()
+		err = m.budget.enter()
+	}
+	if err == nil {
+		model, err = p.parseMessage(m)
+	}
+	if err != nil {
+		err = m.locate(within("", m.n, err).rooted(If(p.name != "", p.name, typeName[T]())))
+	}
+
+	return
+}
+
+func (p *Parser[T]) parseMessage(r *messageReader) (model T, err error) {
+	if p.checksum != nil {
+		return p.parseChecked(r)
+	}
+	return p.parseBody(r)
+}
+
+func (p *Parser[T]) parseBody(r *messageReader) (model T, err error) {
+	if p.envelope != nil {
+		return p.parseEnvelope(r)
+	}
+
+	model = p.factory.Get()
+
+	if p.tagged {
+		err = p.parseTagged(&model, r)
+		return
+	}
+
+	for i, f := range p.fields {
+		offset := r.n
+		if err = f.Parse(&model, r); err != nil {
+			err = p.fieldError(i, offset, err)
+			return
+		}
+	}
+
+	return
+}
+
+// parseEnvelope reads a whole extensible message before parsing its fields.
+// Fields missing at the end of the message keep the value the factory gave
+// them, and unknown trailing bytes written by newer peers are dropped.
+func (p *Parser[T]) parseEnvelope(r *messageReader) (model T, err error) {
+	body, offset, box, err := p.readEnvelope(r)
+	if box != nil {
+		defer SinglePool.Put(box)
+	}
+	if err != nil {
+		return
+	}
+	return p.parseEnvelopeBody(r, &body, offset)
+}
+
+// readEnvelope reads the length and the body of an extensible message, which
+// starts offset bytes into it. See messageReader.body for box.
+func (p *Parser[T]) readEnvelope(r *messageReader) (body BufferCursor, offset int, box *[]byte, err error) {
+	var size int
+	if size, err = p.envelope.Parse(r); err != nil {
+		return
+	}
+
+	if size < 0 || size > MaxReasonableVarSize {
+		err = ErrOverflow
+		return
+	}
+
+	offset = r.n
+	body, box, err = r.body(size)
+	return
+}
+
+// parseEnvelopeBody parses the fields of an extensible message from its
+// body, read by readEnvelope.
+func (p *Parser[T]) parseEnvelopeBody(r *messageReader, cursor *BufferCursor, offset int) (model T, err error) {
+	model = p.factory.Get()
+	body := r.nested(cursor, offset)
+	defer putMessageReader(body)
 
-	for _, f := range p.fields {
-		if err := f.Parse(&model, r); err != nil {
-			return model, nil
+	if p.tagged {
+		err = p.parseTagged(&model, body)
+		return
+	}
+
+	for i, f := range p.fields {
+		if cursor.cursor == len(cursor.data) {
+			break
+		}
+		fieldOffset := body.n
+		if err = f.Parse(&model, body); err != nil {
+			err = p.fieldError(i, fieldOffset, err)
+			return
 		}
 	}
 
-	return model, nil
+	return
+}
+
+// fieldError locates err, raised by the i-th field which started at offset.
+func (p *Parser[T]) fieldError(i, offset int, err error) error {
+	pe := within("."+fieldName(p.fields[i], i), offset, err)
+	if pe.Index < 0 {
+		pe.Index = i
+	}
+	return pe
 }
 
 func (p *Parser[T]) Struct(field fieldParser[T]) *Parser[T] {
@@ -115,6 +251,46 @@ func (p *Parser[T]) Int(order binary.ByteOrder, setter Setter[T, int]) *Parser[T
 	return p.register(IntFieldSetter[T](Int(order), setter))
 }
 
+func (p *Parser[T]) VarUInt8(setter Setter[T, uint8]) *Parser[T] {
+	return p.register(UInt8FieldSetter[T](VarUInt8(), setter))
+}
+
+func (p *Parser[T]) VarInt8(setter Setter[T, int8]) *Parser[T] {
+	return p.register(Int8FieldSetter[T](VarInt8(), setter))
+}
+
+func (p *Parser[T]) VarUInt16(setter Setter[T, uint16]) *Parser[T] {
+	return p.register(UInt16FieldSetter[T](VarUInt16(), setter))
+}
+
+func (p *Parser[T]) VarUInt32(setter Setter[T, uint32]) *Parser[T] {
+	return p.register(UInt32FieldSetter[T](VarUInt32(), setter))
+}
+
+func (p *Parser[T]) VarUInt64(setter Setter[T, uint64]) *Parser[T] {
+	return p.register(UInt64FieldSetter[T](VarUInt64(), setter))
+}
+
+func (p *Parser[T]) VarInt16(setter Setter[T, int16]) *Parser[T] {
+	return p.register(Int16FieldSetter[T](VarInt16(), setter))
+}
+
+func (p *Parser[T]) VarInt32(setter Setter[T, int32]) *Parser[T] {
+	return p.register(Int32FieldSetter[T](VarInt32(), setter))
+}
+
+func (p *Parser[T]) VarInt64(setter Setter[T, int64]) *Parser[T] {
+	return p.register(Int64FieldSetter[T](VarInt64(), setter))
+}
+
+func (p *Parser[T]) VarInt(setter Setter[T, int]) *Parser[T] {
+	return p.register(IntFieldSetter[T](VarInt(), setter))
+}
+
+func (p *Parser[T]) VarUInt(setter Setter[T, uint]) *Parser[T] {
+	return p.register(UIntFieldSetter[T](VarUInt(), setter))
+}
+
 func (p *Parser[T]) Float32(order binary.ByteOrder, setter Setter[T, float32]) *Parser[T] {
 	return p.register(Float32FieldSetter[T](Float32(order), setter))
 }
@@ -147,7 +323,73 @@ func (p *Parser[T]) Skip(pad int) *Parser[T] {
 	return p.register(DefaultSkipField[T](pad))
 }
 
+// Named names the last registered field. Names show up in the Schema and in
+// parse errors.
+func (p *Parser[T]) Named(name string) *Parser[T] {
+	if n := len(p.fields); n > 0 {
+		p.fields[n-1] = renamed(p.fields[n-1], name)
+	}
+	return p
+}
+
+// Extensible prefixes every message with its byte length, written with
+// header. Messages from older peers lacking trailing fields parse with those
+// fields left as the factory built them, and trailing fields added by newer
+// peers are skipped. Both peers must be extensible.
+func (p *Parser[T]) Extensible(header IntType) *Parser[T] {
+	p.envelope = header
+	return p
+}
+
+// Checksum verifies every message against the checksum following it,
+// reporting mismatches as ErrChecksumMismatch. Extensible messages are
+// verified before their fields are parsed; others once parsed, so truncated
+// or corrupted ones may fail to parse first. See Compiler.Checksum.
+func (p *Parser[T]) Checksum(sum Checksum) *Parser[T] {
+	p.checksum = sum
+	return p
+}
+
+// Limits bounds what parsing a message may read and allocate, in place of
+// the package defaults. Limits apply to messages parsed on their own: models
+// parsed as part of another, or by a ModelMultiBuilder, follow the limits of
+// the latter.
+func (p *Parser[T]) Limits(limits DecodeLimits) *Parser[T] {
+	p.limits = &limits
+	return p
+}
+
+// decodeLimits lets Explain follow the limits of the parser.
+func (p *Parser[T]) decodeLimits() *DecodeLimits {
+	return p.limits
+}
+
+// Tagged switches to the tagged layout, where each field is preceded by its
+// number and wire type, like protobuf does. Fields are matched by number in
+// any order, unknown fields are skipped and fields absent from the message
+// are parsed from zeros, since compilers omit those whose encoding is all
+// zero bytes.
+func (p *Parser[T]) Tagged() *Parser[T] {
+	p.tagged = true
+	return p
+}
+
+// Tag numbers the last registered field. Fields are numbered from 1 in
+// registration order unless told otherwise. It panics if num is in use.
+func (p *Parser[T]) Tag(num int) *Parser[T] {
+	if len(p.tags) > 0 {
+		retag(p.tags, num)
+	}
+	return p
+}
+
+// Schema describes the fields of the model in wire order.
+func (p *Parser[T]) Schema() Schema {
+	return modelSchema[T](p.fields).typed(p.name).enveloped(p.envelope).numbered(p.tagged, p.tags).checksummed(p.checksum)
+}
+
 func (p *Parser[T]) register(f fieldParser[T]) *Parser[T] {
+	p.tags = append(p.tags, nextTag(p.tags, f))
 	p.fields = append(p.fields, f)
 	return p
 }
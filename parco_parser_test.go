@@ -0,0 +1,203 @@
+package parco
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"testing"
+	"unsafe"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type (
+	parseItem struct {
+		SKU   string
+		Price uint32
+	}
+
+	parseOrder struct {
+		ID     uint16
+		Items  []parseItem
+		Labels map[string]uint16
+		Note   *parseItem
+	}
+)
+
+func parseItemBuilder() ModelBuilder[parseItem] {
+	return Builder[parseItem](ObjectFactory[parseItem]()).
+		SmallVarchar(
+			func(i *parseItem) string { return i.SKU },
+			func(i *parseItem, v string) { i.SKU = v },
+		).Named("SKU").
+		UInt32(binary.LittleEndian,
+			func(i *parseItem) uint32 { return i.Price },
+			func(i *parseItem, v uint32) { i.Price = v },
+		).Named("Price")
+}
+
+func parseOrderBuilder() ModelBuilder[parseOrder] {
+	return Builder[parseOrder](ObjectFactory[parseOrder]()).
+		UInt16LE(
+			func(o *parseOrder) uint16 { return o.ID },
+			func(o *parseOrder, v uint16) { o.ID = v },
+		).Named("ID").
+		Slice(SliceField[parseOrder, parseItem](
+			UInt8Header(),
+			Struct[parseItem](parseItemBuilder()),
+			func(o *parseOrder, v SliceView[parseItem]) { o.Items = v },
+			func(o *parseOrder) SliceView[parseItem] { return o.Items },
+		)).Named("Items").
+		Map(MapField[parseOrder, string, uint16](
+			UInt8Header(),
+			SmallVarchar(),
+			UInt16LE(),
+			func(o *parseOrder, v map[string]uint16) { o.Labels = v },
+			func(o *parseOrder) map[string]uint16 { return o.Labels },
+		)).Named("Labels").
+		Option(OptionField[parseOrder, parseItem](
+			Struct[parseItem](parseItemBuilder()),
+			func(o *parseOrder, v *parseItem) { o.Note = v },
+			func(o *parseOrder) *parseItem { return o.Note },
+		)).Named("Note")
+}
+
+func compileParseOrder(t *testing.T, order parseOrder) []byte {
+	t.Helper()
+	buf := bytes.NewBuffer(nil)
+	require.NoError(t, parseOrderBuilder().Compile(order, buf))
+	return buf.Bytes()
+}
+
+func TestParser_ParseError(t *testing.T) {
+	order := parseOrder{
+		ID:     1,
+		Items:  []parseItem{{"a", 1}, {"b", 2}, {"c", 3}, {"d", 4}},
+		Labels: map[string]uint16{"x": 1},
+		Note:   &parseItem{"n", 5},
+	}
+	data := compileParseOrder(t, order)
+
+	// ID: 2 bytes, items header: 1 byte, each item: 1 + 1 + 4 bytes.
+	itemsEnd := 2 + 1 + 4*6
+
+	tests := []struct {
+		name   string
+		data   []byte
+		path   string
+		index  int
+		offset int
+		err    error
+	}{
+		{
+			name:   "top level field",
+			data:   data[:1],
+			path:   "parseOrder.ID",
+			index:  0,
+			offset: 0,
+			err:    ErrCannotRead,
+		},
+		{
+			name:   "nested slice element field",
+			data:   data[:itemsEnd-2],
+			path:   "parseOrder.Items[3].Price",
+			index:  1,
+			offset: itemsEnd - 4,
+			err:    ErrCannotRead,
+		},
+		{
+			name:   "map value",
+			data:   data[:itemsEnd+1+2+1],
+			path:   "parseOrder.Labels[x]",
+			index:  2,
+			offset: itemsEnd + 3,
+			err:    ErrCannotRead,
+		},
+		{
+			name:   "option",
+			data:   data[:len(data)-1],
+			path:   "parseOrder.Note.Price",
+			index:  1,
+			offset: len(data) - 4,
+			err:    ErrCannotRead,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseOrderBuilder().Parse(bytes.NewReader(tt.data))
+			require.Error(t, err)
+			assert.ErrorIs(t, err, tt.err)
+
+			var pe *ParseError
+			require.True(t, errors.As(err, &pe))
+			assert.Equal(t, tt.path, pe.Path)
+			assert.Equal(t, tt.index, pe.Index)
+			assert.Equal(t, tt.offset, pe.Offset)
+		})
+	}
+}
+
+func TestParser_ParseError_Overflow(t *testing.T) {
+	p := ParserModel[parseOrder](ObjectFactory[parseOrder]()).
+		UInt16LE(func(o *parseOrder, v uint16) { o.ID = v }).
+		Slice(SliceFieldSetter[parseOrder, uint16](
+			VarUIntHeader(),
+			UInt16LE(),
+			func(o *parseOrder, v SliceView[uint16]) {},
+		))
+
+	// Unnamed fields are located by position.
+	_, err := p.ParseBytes([]byte{1, 0, 0xff, 0xff, 0xff, 0xff, 0x7f})
+	assert.ErrorIs(t, err, ErrOverflow)
+	assert.EqualError(t, err, "parse parseOrder.#1 (field 1, offset 2): bytes overflow")
+}
+
+func TestParser_ParseError_Message(t *testing.T) {
+	_, err := parseItemBuilder().Extensible(UInt8Header()).Parse(bytes.NewReader(nil))
+
+	var pe *ParseError
+	require.ErrorAs(t, err, &pe)
+	assert.Equal(t, "parseItem", pe.Path)
+	assert.Equal(t, -1, pe.Index)
+	assert.ErrorIs(t, err, ErrCannotRead)
+}
+
+func TestParser_ReturnsPartialModel(t *testing.T) {
+	data := compileParseOrder(t, parseOrder{ID: 7, Items: []parseItem{{"a", 1}}})
+
+	order, err := parseOrderBuilder().Parse(bytes.NewReader(data[:4]))
+	assert.Error(t, err)
+	assert.Equal(t, uint16(7), order.ID)
+}
+
+func TestParser_ParseBytesZeroCopy(t *testing.T) {
+	data := compileParseOrder(t, parseOrder{
+		ID:    1,
+		Items: []parseItem{{"ab", 7}},
+		Note:  &parseItem{"n", 5},
+	})
+
+	order, err := parseOrderBuilder().ParseBytesZeroCopy(data)
+	require.NoError(t, err)
+	assert.Equal(t, "ab", order.Items[0].SKU)
+	assert.Equal(t, "n", order.Note.SKU)
+
+	sku := unsafe.StringData(order.Items[0].SKU)
+	assert.Same(t, &data[bytes.Index(data, []byte("ab"))], sku)
+
+	// Extensible and tagged bodies alias the input too.
+	buf := bytes.NewBuffer(nil)
+	v2 := deviceV2Builder(ObjectFactory[deviceV2]())
+	require.NoError(t, v2.Compile(deviceV2{ID: 1, Name: "dev", Tags: []string{"x"}}, buf))
+	device, err := v2.ParseBytesZeroCopy(buf.Bytes())
+	require.NoError(t, err)
+	assert.Same(t, &buf.Bytes()[bytes.Index(buf.Bytes(), []byte("dev"))], unsafe.StringData(device.Name))
+
+	buf.Reset()
+	require.NoError(t, settingsBuilder().Compile(settings{Name: "db", Hosts: []string{"host"}}, buf))
+	s, err := settingsBuilder().ParseBytesZeroCopy(buf.Bytes())
+	require.NoError(t, err)
+	assert.Same(t, &buf.Bytes()[bytes.Index(buf.Bytes(), []byte("host"))], unsafe.StringData(s.Hosts[0]))
+}
@@ -0,0 +1,327 @@
+package parco
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+type (
+	// Value is a value held by a Record: an integer, float or bool of the
+	// Go type named by its schema, a string, []byte, time.Time, a Record for
+	// structs, []Value for slices and arrays, map[Value]Value for maps, and
+	// for options nil when absent.
+	Value = any
+
+	// Record is a message of a dynamic model: its fields in the order they
+	// were read, or are to be written. Fields missing from a record are
+	// written as zero values.
+	Record []RecordField
+
+	RecordField struct {
+		Name  string
+		Value Value
+	}
+
+	// RecordType parses and compiles the messages of a model described by a
+	// Schema as Records, so that code handling any message can reach its
+	// fields without the Go type of the model.
+	RecordType struct {
+		model dynamicStruct[Record]
+	}
+
+	// ModelDynamicBuilder declares a model at runtime, one field at a time,
+	// and builds its RecordType.
+	ModelDynamicBuilder struct {
+		schema Schema
+	}
+)
+
+var recordRepr = structRepr[Record]{
+	make: func(fields int) Record { return make(Record, 0, fields) },
+	get: func(r *Record, name string) any {
+		v, _ := r.Get(name)
+		return v
+	},
+	set: func(r *Record, name string, field any) { r.Set(name, field) },
+	to: func(s Schema, value any) (Record, error) {
+		r, ok := value.(Record)
+		if !ok && value != nil {
+			return nil, typeMismatch("record", value)
+		}
+		for _, f := range r {
+			if _, ok := s.Field(f.Name); !ok {
+				return nil, NewErrFieldNotFoundError(f.Name)
+			}
+		}
+		return r, nil
+	},
+}
+
+// Get returns the value of the field name.
+func (r Record) Get(name string) (Value, bool) {
+	for _, f := range r {
+		if f.Name == name {
+			return f.Value, true
+		}
+	}
+	return nil, false
+}
+
+// Lookup returns the value at path, descending into nested records, e.g.
+// Lookup("Note", "SKU").
+func (r Record) Lookup(path ...string) (Value, bool) {
+	var value Value = r
+	for _, name := range path {
+		record, ok := value.(Record)
+		if !ok {
+			return nil, false
+		}
+		if value, ok = record.Get(name); !ok {
+			return nil, false
+		}
+	}
+	return value, true
+}
+
+// Set sets the field name to value, appending the field if missing.
+func (r *Record) Set(name string, value Value) {
+	for i := range *r {
+		if (*r)[i].Name == name {
+			(*r)[i].Value = value
+			return
+		}
+	}
+	*r = append(*r, RecordField{Name: name, Value: value})
+}
+
+// Delete removes the field name, which is then written as a zero value.
+func (r *Record) Delete(name string) bool {
+	for i, f := range *r {
+		if f.Name == name {
+			*r = append((*r)[:i], (*r)[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// DynamicRecord builds the RecordType of the model described by s, which must
+// be a KindStruct schema made of built-in types, e.g. one loaded with
+// ParseSchemaFile. See Dynamic.
+func DynamicRecord(s Schema) (RecordType, error) {
+	if s.Kind != KindStruct {
+		return RecordType{}, fmt.Errorf("%w: %s is not a struct", ErrInvalidSchema, s.TypeString())
+	}
+
+	model, err := newDynamicStruct(dynamicTypes{records: true}, s, recordRepr)
+	if err != nil {
+		return RecordType{}, err
+	}
+	return RecordType{model: model}, nil
+}
+
+// ByteLength returns the encoded width of the records, or -1 if it varies.
+func (t RecordType) ByteLength() int {
+	return t.model.ByteLength()
+}
+
+func (t RecordType) Parse(r io.Reader) (Record, error) {
+	return t.model.parser.Parse(r)
+}
+
+func (t RecordType) ParseBytes(data []byte) (Record, error) {
+	return t.model.parser.ParseBytes(data)
+}
+
+// Compile writes value, whose fields must be fields of the model.
+func (t RecordType) Compile(value Record, w io.Writer) error {
+	return t.model.Compile(value, w)
+}
+
+// Size returns the encoded size of value. See Compiler.Size.
+func (t RecordType) Size(value Record) (int, error) {
+	return t.model.Size(value)
+}
+
+func (t RecordType) ParseAny(r io.Reader) (any, error) {
+	return t.Parse(r)
+}
+
+func (t RecordType) CompileAny(value any, w io.Writer) error {
+	return t.model.Compile(value, w)
+}
+
+// Schema describes the model.
+func (t RecordType) Schema() Schema {
+	return t.model.Schema()
+}
+
+// DynamicBuilder starts declaring the model name, e.g.
+//
+//	DynamicBuilder("User").
+//		UInt32("ID", binary.LittleEndian).
+//		SmallVarchar("Name").
+//		Slice("Tags", UInt8Header(), SmallVarchar())
+func DynamicBuilder(name string) *ModelDynamicBuilder {
+	return &ModelDynamicBuilder{schema: Schema{Kind: KindStruct, Type: name}}
+}
+
+// Build returns the RecordType of the model declared so far.
+func (b *ModelDynamicBuilder) Build() (RecordType, error) {
+	return DynamicRecord(b.Schema())
+}
+
+// MustBuild is like Build but panics on invalid models.
+func (b *ModelDynamicBuilder) MustBuild() RecordType {
+	t, err := b.Build()
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+// Schema describes the model declared so far.
+func (b *ModelDynamicBuilder) Schema() Schema {
+	s := b.schema
+	s.Fields = append([]Schema(nil), s.Fields...)
+	return s
+}
+
+// Field adds the field name of type tp, a built-in type or a model. Nested
+// models are held as Records.
+func (b *ModelDynamicBuilder) Field(name string, tp any) *ModelDynamicBuilder {
+	b.schema.Fields = append(b.schema.Fields, Describe(tp).named(name))
+	return b
+}
+
+// Extensible prefixes every message with its byte length. See
+// ModelBuilder.Extensible.
+func (b *ModelDynamicBuilder) Extensible(header IntType) *ModelDynamicBuilder {
+	b.schema.Header = describePtr(header)
+	return b
+}
+
+// Tagged switches to the tagged layout. See ModelBuilder.Tagged.
+func (b *ModelDynamicBuilder) Tagged() *ModelDynamicBuilder {
+	b.schema.Tagged = true
+	return b
+}
+
+// Checksum follows every message with its checksum. See
+// ModelBuilder.Checksum. Build fails for checksums other than those of this
+// package, as the schema only records their name.
+func (b *ModelDynamicBuilder) Checksum(sum Checksum) *ModelDynamicBuilder {
+	b.schema.Checksum = sum.Name()
+	return b
+}
+
+// Tag numbers the last added field. Build fails if num is in use.
+func (b *ModelDynamicBuilder) Tag(num int) *ModelDynamicBuilder {
+	if n := len(b.schema.Fields); n > 0 {
+		b.schema.Fields[n-1].Tag = num
+	}
+	return b
+}
+
+func (b *ModelDynamicBuilder) Skip(pad int) *ModelDynamicBuilder {
+	return b.Field("", SkipType(pad))
+}
+
+func (b *ModelDynamicBuilder) Bool(name string) *ModelDynamicBuilder {
+	return b.Field(name, Bool())
+}
+
+func (b *ModelDynamicBuilder) UInt8(name string) *ModelDynamicBuilder {
+	return b.Field(name, UInt8())
+}
+
+func (b *ModelDynamicBuilder) Int8(name string) *ModelDynamicBuilder {
+	return b.Field(name, Int8())
+}
+
+func (b *ModelDynamicBuilder) UInt16(name string, order binary.ByteOrder) *ModelDynamicBuilder {
+	return b.Field(name, UInt16(order))
+}
+
+func (b *ModelDynamicBuilder) Int16(name string, order binary.ByteOrder) *ModelDynamicBuilder {
+	return b.Field(name, Int16(order))
+}
+
+func (b *ModelDynamicBuilder) UInt32(name string, order binary.ByteOrder) *ModelDynamicBuilder {
+	return b.Field(name, UInt32(order))
+}
+
+func (b *ModelDynamicBuilder) Int32(name string, order binary.ByteOrder) *ModelDynamicBuilder {
+	return b.Field(name, Int32(order))
+}
+
+func (b *ModelDynamicBuilder) UInt64(name string, order binary.ByteOrder) *ModelDynamicBuilder {
+	return b.Field(name, UInt64(order))
+}
+
+func (b *ModelDynamicBuilder) Int64(name string, order binary.ByteOrder) *ModelDynamicBuilder {
+	return b.Field(name, Int64(order))
+}
+
+func (b *ModelDynamicBuilder) VarUInt(name string) *ModelDynamicBuilder {
+	return b.Field(name, VarUInt64())
+}
+
+func (b *ModelDynamicBuilder) VarInt(name string) *ModelDynamicBuilder {
+	return b.Field(name, VarInt64())
+}
+
+func (b *ModelDynamicBuilder) Float32(name string, order binary.ByteOrder) *ModelDynamicBuilder {
+	return b.Field(name, Float32(order))
+}
+
+func (b *ModelDynamicBuilder) Float64(name string, order binary.ByteOrder) *ModelDynamicBuilder {
+	return b.Field(name, Float64(order))
+}
+
+func (b *ModelDynamicBuilder) SmallVarchar(name string) *ModelDynamicBuilder {
+	return b.Field(name, SmallVarchar())
+}
+
+func (b *ModelDynamicBuilder) Varchar(name string) *ModelDynamicBuilder {
+	return b.Field(name, Varchar())
+}
+
+func (b *ModelDynamicBuilder) String(name string, header IntType) *ModelDynamicBuilder {
+	return b.Field(name, String(header))
+}
+
+func (b *ModelDynamicBuilder) Blob(name string, header IntType) *ModelDynamicBuilder {
+	return b.Field(name, Blob(header))
+}
+
+func (b *ModelDynamicBuilder) TimeUTC(name string) *ModelDynamicBuilder {
+	return b.Field(name, TimeUTC())
+}
+
+func (b *ModelDynamicBuilder) TimeLocation(name string) *ModelDynamicBuilder {
+	return b.Field(name, TimeLocation())
+}
+
+// Slice adds a slice of elem, which may be a built-in type or a model.
+func (b *ModelDynamicBuilder) Slice(name string, header IntType, elem any) *ModelDynamicBuilder {
+	return b.Field(name, Schema{Kind: KindSlice, Header: describePtr(header), Elem: describePtr(elem)})
+}
+
+func (b *ModelDynamicBuilder) Array(name string, length int, elem any) *ModelDynamicBuilder {
+	return b.Field(name, Schema{Kind: KindArray, Length: length, Elem: describePtr(elem)})
+}
+
+func (b *ModelDynamicBuilder) Map(name string, header IntType, key, value any) *ModelDynamicBuilder {
+	return b.Field(name, Schema{Kind: KindMap, Header: describePtr(header), Key: describePtr(key), Elem: describePtr(value)})
+}
+
+func (b *ModelDynamicBuilder) Option(name string, inner any) *ModelDynamicBuilder {
+	return b.Field(name, Schema{Kind: KindOption, Header: describePtr(Bool()), Elem: describePtr(inner)})
+}
+
+// Struct adds a nested model, e.g. another ModelDynamicBuilder.
+func (b *ModelDynamicBuilder) Struct(name string, model any) *ModelDynamicBuilder {
+	return b.Field(name, model)
+}
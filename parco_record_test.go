@@ -0,0 +1,169 @@
+package parco
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func parseOrderRecordType() RecordType {
+	item := DynamicBuilder("parseItem").
+		SmallVarchar("SKU").
+		UInt32("Price", binary.LittleEndian)
+
+	return DynamicBuilder("parseOrder").
+		UInt16("ID", binary.LittleEndian).
+		Slice("Items", UInt8Header(), item).
+		Map("Labels", UInt8Header(), SmallVarchar(), UInt16LE()).
+		Option("Note", item).
+		MustBuild()
+}
+
+func TestDynamicBuilder_MatchesBuilders(t *testing.T) {
+	rt := parseOrderRecordType()
+	assert.Equal(t, Describe(parseOrderBuilder()).String(), rt.Schema().String())
+
+	data := compileParseOrder(t, parseOrder{
+		ID:     42,
+		Items:  []parseItem{{"ab", 7}},
+		Labels: map[string]uint16{"x": 1},
+		Note:   &parseItem{"n", 5},
+	})
+
+	record, err := rt.ParseBytes(data)
+	require.NoError(t, err)
+	assert.Equal(t, Record{
+		{"ID", uint16(42)},
+		{"Items", []Value{Record{{"SKU", "ab"}, {"Price", uint32(7)}}}},
+		{"Labels", map[Value]Value{"x": uint16(1)}},
+		{"Note", Record{{"SKU", "n"}, {"Price", uint32(5)}}},
+	}, record)
+
+	buf := bytes.NewBuffer(nil)
+	require.NoError(t, rt.Compile(record, buf))
+	assert.Equal(t, data, buf.Bytes())
+}
+
+func TestRecordType_ByteLength(t *testing.T) {
+	point := DynamicBuilder("Point").
+		Int32("X", binary.LittleEndian).
+		Int32("Y", binary.LittleEndian).
+		MustBuild()
+	assert.Equal(t, 8, point.ByteLength())
+
+	assert.Equal(t, -1, parseOrderRecordType().ByteLength())
+}
+
+func TestRecord_Middleware(t *testing.T) {
+	rt := parseOrderRecordType()
+	data := compileParseOrder(t, parseOrder{ID: 42, Note: &parseItem{"secret", 5}})
+
+	record, err := rt.ParseBytes(data)
+	require.NoError(t, err)
+
+	// Route by a field value.
+	id, ok := record.Get("ID")
+	require.True(t, ok)
+	assert.Equal(t, uint16(42), id)
+
+	// Redact a nested field.
+	note, ok := record.Lookup("Note")
+	require.True(t, ok)
+	redacted := note.(Record)
+	redacted.Set("SKU", "***")
+	record.Set("Note", redacted)
+
+	buf := bytes.NewBuffer(nil)
+	require.NoError(t, rt.Compile(record, buf))
+
+	order, err := parseOrderBuilder().Parse(buf)
+	require.NoError(t, err)
+	assert.Equal(t, &parseItem{"***", 5}, order.Note)
+
+	sku, ok := record.Lookup("Note", "SKU")
+	assert.True(t, ok)
+	assert.Equal(t, "***", sku)
+
+	_, ok = record.Lookup("ID", "SKU")
+	assert.False(t, ok)
+	_, ok = record.Lookup("Missing")
+	assert.False(t, ok)
+}
+
+func TestRecord_SetDelete(t *testing.T) {
+	var r Record
+	r.Set("a", 1)
+	r.Set("b", 2)
+	r.Set("a", 3)
+	assert.Equal(t, Record{{"a", 3}, {"b", 2}}, r)
+
+	assert.True(t, r.Delete("a"))
+	assert.False(t, r.Delete("a"))
+	assert.Equal(t, Record{{"b", 2}}, r)
+}
+
+func TestDynamicBuilder_Layouts(t *testing.T) {
+	rt := DynamicBuilder("settings").
+		Tagged().
+		SmallVarchar("Name").
+		UInt8("Retries").
+		UInt32("Timeout", binary.LittleEndian).
+		Bool("Debug").
+		Float64("Ratio", binary.LittleEndian).
+		Slice("Hosts", UInt8Header(), SmallVarchar()).
+		Option("Limit", Int16LE()).
+		VarInt("Seq").Tag(20).
+		MustBuild()
+
+	buf := bytes.NewBuffer(nil)
+	require.NoError(t, settingsBuilder().Compile(settings{Name: "db", Hosts: []string{"a"}, Limit: Ptr[int16](-3), Seq: 9}, buf))
+
+	record, err := rt.ParseBytes(buf.Bytes())
+	require.NoError(t, err)
+	seq, _ := record.Get("Seq")
+	assert.Equal(t, int64(9), seq)
+
+	out := bytes.NewBuffer(nil)
+	require.NoError(t, rt.Compile(record, out))
+	assert.Equal(t, buf.Bytes(), out.Bytes())
+
+	// Missing fields are zero, unknown ones rejected.
+	out.Reset()
+	require.NoError(t, rt.Compile(Record{{"Seq", 9}, {"Name", "db"}}, out))
+	back, err := settingsBuilder().Parse(out)
+	require.NoError(t, err)
+	assert.Equal(t, "db", back.Name)
+	assert.Equal(t, int64(9), back.Seq)
+	assert.Nil(t, back.Limit)
+
+	var notFound ErrFieldNotFound
+	assert.ErrorAs(t, rt.Compile(Record{{"Extra", 1}}, out), &notFound)
+
+	// Registered in a multi builder like any model.
+	b := MultiBuilder[int](UInt8Header()).MustRegister(1, rt)
+	out.Reset()
+	require.NoError(t, b.CompileAny(1, Record{{"Seq", 1}}, out))
+	_, value, err := b.Parse(out)
+	require.NoError(t, err)
+	assert.IsType(t, Record{}, value)
+}
+
+func TestDynamicBuilder_Errors(t *testing.T) {
+	_, err := DynamicBuilder("a").Tagged().Bool("x").Tag(1).Bool("y").Tag(1).Build()
+	assert.ErrorIs(t, err, ErrInvalidSchema)
+
+	_, err = DynamicBuilder("a").Field("x", 42).Build()
+	assert.ErrorIs(t, err, ErrUnknownType)
+
+	assert.Panics(t, func() {
+		DynamicBuilder("a").Map("x", UInt8Header(), Blob(UInt8Header()), Bool()).MustBuild()
+	})
+
+	rt := DynamicBuilder("a").UInt8("x").MustBuild()
+	var mismatch ErrTypeAssertion
+	assert.ErrorAs(t, rt.CompileAny(map[string]any{"x": 1}, bytes.NewBuffer(nil)), &mismatch)
+	assert.ErrorIs(t, rt.Compile(Record{{"x", 256}}, bytes.NewBuffer(nil)), ErrOverflow)
+}
@@ -0,0 +1,334 @@
+package parco
+
+import (
+	"errors"
+	"fmt"
+	"io"
+)
+
+// WireType tells how a field of a tagged model is laid out, so that parsers
+// can skip the fields they do not know.
+type WireType uint8
+
+const (
+	// WireVarint is a LEB128 varint.
+	WireVarint WireType = iota
+	// WireFixed8 is a single byte.
+	WireFixed8
+	// WireFixed16 is two bytes.
+	WireFixed16
+	// WireFixed32 is four bytes.
+	WireFixed32
+	// WireFixed64 is eight bytes.
+	WireFixed64
+	// WireBytes is a varint byte length followed by that many bytes.
+	WireBytes
+)
+
+var wireTypeNames = [...]string{
+	WireVarint:  "varint",
+	WireFixed8:  "fixed8",
+	WireFixed16: "fixed16",
+	WireFixed32: "fixed32",
+	WireFixed64: "fixed64",
+	WireBytes:   "bytes",
+}
+
+func (w WireType) String() string {
+	if int(w) < len(wireTypeNames) {
+		return wireTypeNames[w]
+	}
+	return fmt.Sprintf("wiretype(%d)", uint8(w))
+}
+
+// width returns the byte length of fixed wire types, 0 otherwise.
+func (w WireType) width() int {
+	switch w {
+	case WireFixed8:
+		return 1
+	case WireFixed16:
+		return 2
+	case WireFixed32:
+		return 4
+	case WireFixed64:
+		return 8
+	}
+	return 0
+}
+
+const wireTypeBits = 3
+
+type fieldTag struct {
+	num  int
+	wire WireType
+}
+
+var (
+	tagKeyType    = VarUInt64()
+	tagLengthType = VarUIntHeader()
+)
+
+// wireTypeOf picks the wire type of a field from its schema. Anything that is
+// not a plain number travels length delimited.
+func wireTypeOf(s Schema) WireType {
+	switch s.Kind {
+	case KindVarint:
+		return WireVarint
+	case KindFixed, KindSkip:
+		switch s.ByteLength {
+		case 1:
+			return WireFixed8
+		case 2:
+			return WireFixed16
+		case 4:
+			return WireFixed32
+		case 8:
+			return WireFixed64
+		}
+	}
+	return WireBytes
+}
+
+// nextTag numbers a field registered after tags.
+func nextTag(tags []fieldTag, field any) fieldTag {
+	num := 1
+	for _, t := range tags {
+		num = max(num, t.num+1)
+	}
+	return fieldTag{num: num, wire: wireTypeOf(Describe(field))}
+}
+
+// retag gives the last field the number num.
+func retag(tags []fieldTag, num int) {
+	if num < 1 || num > maxTagNumber {
+		panic(fmt.Sprintf("field number out of range: %d", num))
+	}
+	for _, t := range tags[:len(tags)-1] {
+		if t.num == num {
+			panic(fmt.Sprintf("this field number is used already: %d", num))
+		}
+	}
+	tags[len(tags)-1].num = num
+}
+
+const maxTagNumber = 1<<(64-wireTypeBits) - 1
+
+func findTag(tags []fieldTag, num, hint int) int {
+	if hint < len(tags) && tags[hint].num == num {
+		return hint
+	}
+	for i, t := range tags {
+		if t.num == num {
+			return i
+		}
+	}
+	return -1
+}
+
+// compileTagged writes each field preceded by its number and wire type, and
+// ends the message with a zero key. Fields whose encoding is all zero bytes,
+// i.e. zero numbers, empty strings and collections, absent options, are
+// omitted.
+func compileTagged[T any](value *T, fields []fieldCompiler[T], tags []fieldTag, w io.Writer) error {
+	cw, ok := w.(*compileWriter)
+	if !ok {
+		cw = getCompileWriter(w)
+		defer putCompileWriter(cw)
+		if err := appendTagged(value, fields, tags, cw); err != nil {
+			return err
+		}
+		return cw.flush()
+	}
+	return appendTagged(value, fields, tags, cw)
+}
+
+// appendTagged compiles every field in place, then drops it if all zero or
+// moves its key in front of it.
+func appendTagged[T any](value *T, fields []fieldCompiler[T], tags []fieldTag, cw *compileWriter) error {
+	for i, f := range fields {
+		// Pinned while streamed fields compile; nothing flushes past them.
+		start := cw.pin()
+		err := f.Compile(value, cw)
+		cw.unpin()
+		if err != nil {
+			return err
+		}
+
+		body := cw.buf[start:]
+		if isZero(body) {
+			cw.buf = cw.buf[:start]
+			continue
+		}
+
+		tag := tags[i]
+		if width := tag.wire.width(); width > 0 && width != len(body) {
+			return NewErrCompile(fmt.Sprintf("field %d: %s written as %d bytes", tag.num, tag.wire, len(body)))
+		}
+
+		mid := len(cw.buf)
+		key := uint64(tag.num)<<wireTypeBits | uint64(tag.wire)
+		if err := tagKeyType.Compile(key, cw); err != nil {
+			return err
+		}
+
+		if tag.wire == WireBytes {
+			if err := tagLengthType.Compile(len(body), cw); err != nil {
+				return err
+			}
+		}
+
+		cw.moveFront(start, mid)
+	}
+
+	return tagKeyType.Compile(0, cw)
+}
+
+// parseTagged reads fields in any order until the zero key. Unknown fields
+// are skipped. Fields absent from the message were omitted for being all
+// zero bytes, so they are parsed from zeros rather than left as the factory
+// built them.
+func (p *Parser[T]) parseTagged(model *T, r *messageReader) error {
+	var small [64]bool
+	seen := small[:0]
+	if len(p.fields) <= len(small) {
+		seen = small[:len(p.fields)]
+	} else {
+		seen = make([]bool, len(p.fields))
+	}
+
+	for next := 0; ; next++ {
+		key, err := tagKeyType.Parse(r)
+		if err != nil {
+			return err
+		}
+		if key == 0 {
+			return p.parseOmitted(model, seen, r)
+		}
+
+		num, wire := int(key>>wireTypeBits), WireType(key&(1<<wireTypeBits-1))
+
+		i := findTag(p.tags, num, next)
+		if i < 0 {
+			if err := skipWire(r, wire); err != nil {
+				return err
+			}
+			continue
+		}
+		next = i
+		seen[i] = true
+
+		offset := r.n
+
+		if p.tags[i].wire != wire {
+			err = fmt.Errorf("%w: want %s, have %s", ErrWireType, p.tags[i].wire, wire)
+		} else if wire != WireBytes {
+			err = p.fields[i].Parse(model, r)
+		} else {
+			err = parseDelimited(model, p.fields[i], r)
+		}
+
+		if err != nil {
+			return p.fieldError(i, offset, err)
+		}
+	}
+}
+
+// parseOmitted parses the fields not seen in the message from zero bytes,
+// which is how they were written before being omitted.
+func (p *Parser[T]) parseOmitted(model *T, seen []bool, r *messageReader) error {
+	for i, ok := range seen {
+		if ok {
+			continue
+		}
+		// Zeros are not part of the message: they don't count against its
+		// limits nor move its offset.
+		z := getMessageReader(zeros{}, r.n)
+		z.start = r.start
+		err := p.fields[i].Parse(model, z)
+		putMessageReader(z)
+		if err != nil {
+			return p.fieldError(i, r.n, err)
+		}
+	}
+	return nil
+}
+
+// zeros reads zero bytes endlessly.
+type zeros struct{}
+
+func (zeros) Read(p []byte) (int, error) {
+	clear(p)
+	return len(p), nil
+}
+
+func (zeros) ReadByte() (byte, error) {
+	return 0, nil
+}
+
+func parseDelimited[T any](model *T, field fieldParser[T], r *messageReader) error {
+	size, err := readWireLength(r)
+	if err != nil {
+		return err
+	}
+
+	offset := r.n
+
+	cursor, box, err := r.body(size)
+	if box != nil {
+		defer SinglePool.Put(box)
+	}
+	if err != nil {
+		return err
+	}
+
+	// Bytes left behind belong to a newer version of the field's type, e.g.
+	// an extended nested model.
+	body := r.nested(&cursor, offset)
+	defer putMessageReader(body)
+
+	return field.Parse(model, body)
+}
+
+func readWireLength(r io.Reader) (int, error) {
+	size, err := tagLengthType.Parse(r)
+	if err != nil {
+		return 0, err
+	}
+	if size < 0 || size > MaxReasonableVarSize {
+		return 0, ErrOverflow
+	}
+	return size, nil
+}
+
+func skipWire(r io.Reader, wire WireType) error {
+	var size int
+	switch wire {
+	case WireVarint:
+		_, err := tagKeyType.Parse(r)
+		return err
+	case WireBytes:
+		var err error
+		if size, err = readWireLength(r); err != nil {
+			return err
+		}
+	default:
+		if size = wire.width(); size == 0 {
+			return fmt.Errorf("%w: %s", ErrWireType, wire)
+		}
+	}
+
+	n, err := io.CopyN(io.Discard, r, int64(size))
+	if errors.Is(err, io.EOF) || n < int64(size) {
+		return ErrCannotRead
+	}
+	return err
+}
+
+func isZero(data []byte) bool {
+	for _, b := range data {
+		if b != 0 {
+			return false
+		}
+	}
+	return true
+}
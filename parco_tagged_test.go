@@ -0,0 +1,245 @@
+package parco
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type settings struct {
+	Name    string
+	Retries uint8
+	Timeout uint32
+	Debug   bool
+	Ratio   float64
+	Hosts   []string
+	Limit   *int16
+	Seq     int64
+}
+
+func settingsBuilder() ModelBuilder[settings] {
+	return settingsBuilderWith(ObjectFactory[settings]())
+}
+
+func settingsBuilderWith(factory Factory[settings]) ModelBuilder[settings] {
+	return Builder[settings](factory).
+		Tagged().
+		SmallVarchar(
+			func(s *settings) string { return s.Name },
+			func(s *settings, v string) { s.Name = v },
+		).Named("Name").
+		UInt8(
+			func(s *settings) uint8 { return s.Retries },
+			func(s *settings, v uint8) { s.Retries = v },
+		).Named("Retries").
+		UInt32(binary.LittleEndian,
+			func(s *settings) uint32 { return s.Timeout },
+			func(s *settings, v uint32) { s.Timeout = v },
+		).Named("Timeout").
+		Bool(
+			func(s *settings) bool { return s.Debug },
+			func(s *settings, v bool) { s.Debug = v },
+		).Named("Debug").
+		Float64(binary.LittleEndian,
+			func(s *settings) float64 { return s.Ratio },
+			func(s *settings, v float64) { s.Ratio = v },
+		).Named("Ratio").
+		Slice(SliceField[settings, string](
+			UInt8Header(),
+			SmallVarchar(),
+			func(s *settings, v SliceView[string]) { s.Hosts = v },
+			func(s *settings) SliceView[string] { return s.Hosts },
+		)).Named("Hosts").
+		Option(OptionField[settings, int16](
+			Int16LE(),
+			func(s *settings, v *int16) { s.Limit = v },
+			func(s *settings) *int16 { return s.Limit },
+		)).Named("Limit").
+		VarInt64(
+			func(s *settings) int64 { return s.Seq },
+			func(s *settings, v int64) { s.Seq = v },
+		).Named("Seq").Tag(20)
+}
+
+func TestTagged_RoundTrip(t *testing.T) {
+	limit := int16(-5)
+	expected := settings{
+		Name:    "svc",
+		Retries: 3,
+		Timeout: 1000,
+		Debug:   true,
+		Ratio:   0.5,
+		Hosts:   []string{"a", "b"},
+		Limit:   &limit,
+		Seq:     -1,
+	}
+
+	buf := bytes.NewBuffer(nil)
+	b := settingsBuilder()
+	require.NoError(t, b.Compile(expected, buf))
+	require.NoError(t, b.Compile(settings{}, buf))
+
+	actual, err := b.Parse(buf)
+	require.NoError(t, err)
+	assert.Equal(t, expected, actual)
+
+	actual, err = b.Parse(buf)
+	require.NoError(t, err)
+	assert.Equal(t, settings{Hosts: []string{}}, actual)
+	assert.Zero(t, buf.Len())
+}
+
+func TestTagged_OmitsZeroFields(t *testing.T) {
+	buf := bytes.NewBuffer(nil)
+	require.NoError(t, settingsBuilder().Compile(settings{Retries: 2, Seq: 1}, buf))
+
+	expected := []byte{
+		2<<3 | byte(WireFixed8), 2,
+		0xa0, 0x01, 2, // field 20 as a varint: key 160 takes two bytes
+		0,
+	}
+	assert.Equal(t, expected, buf.Bytes())
+
+	buf.Reset()
+	require.NoError(t, settingsBuilder().Compile(settings{}, buf))
+	assert.Equal(t, []byte{0}, buf.Bytes())
+}
+
+func TestTagged_OmittedFieldsIgnoreFactory(t *testing.T) {
+	limit := int16(7)
+	b := settingsBuilderWith(FuncFactory[settings](func() settings {
+		return settings{Name: "default", Retries: 5, Hosts: []string{"h"}, Limit: &limit}
+	}))
+
+	data, err := b.Append(nil, settings{Seq: 1})
+	require.NoError(t, err)
+
+	actual, err := b.ParseBytes(data)
+	require.NoError(t, err)
+	assert.Equal(t, settings{Hosts: []string{}, Seq: 1}, actual)
+}
+
+func TestTagged_OmittedFieldsPooled(t *testing.T) {
+	factory := PooledFactory[settings](ObjectFactory[settings]())
+	b := settingsBuilderWith(factory)
+
+	limit := int16(7)
+	data, err := b.Append(nil, settings{Name: "a", Retries: 5, Limit: &limit})
+	require.NoError(t, err)
+	first, err := b.ParseBytes(data)
+	require.NoError(t, err)
+	factory.Put(first)
+
+	data, err = b.Append(nil, settings{Seq: 1})
+	require.NoError(t, err)
+	for range 10 {
+		// Whichever instance the pool hands out, nothing of the first
+		// message survives.
+		second, err := b.ParseBytes(data)
+		require.NoError(t, err)
+		assert.Equal(t, settings{Hosts: []string{}, Seq: 1}, second)
+		factory.Put(second)
+	}
+}
+
+func TestTagged_OrderAndUnknownFields(t *testing.T) {
+	// A newer peer reordered fields, renumbered nothing and added field 7,
+	// a string, and field 8, a fixed32.
+	newer := Builder[settings](ObjectFactory[settings]()).
+		Tagged().
+		UInt32(binary.LittleEndian,
+			func(s *settings) uint32 { return s.Timeout },
+			func(s *settings, v uint32) { s.Timeout = v },
+		).Tag(3).
+		SmallVarchar(
+			func(s *settings) string { return "unknown" },
+			func(s *settings, v string) {},
+		).Tag(30).
+		UInt32(binary.LittleEndian,
+			func(s *settings) uint32 { return 99 },
+			func(s *settings, v uint32) {},
+		).Tag(31).
+		VarUInt64(
+			func(s *settings) uint64 { return 1 << 40 },
+			func(s *settings, v uint64) {},
+		).Tag(32).
+		SmallVarchar(
+			func(s *settings) string { return s.Name },
+			func(s *settings, v string) { s.Name = v },
+		).Tag(1)
+
+	buf := bytes.NewBuffer(nil)
+	require.NoError(t, newer.Compile(settings{Name: "x", Timeout: 5}, buf))
+
+	actual, err := settingsBuilder().Parse(buf)
+	require.NoError(t, err)
+	assert.Equal(t, settings{Name: "x", Timeout: 5, Hosts: []string{}}, actual)
+	assert.Zero(t, buf.Len())
+}
+
+func TestTagged_Errors(t *testing.T) {
+	b := settingsBuilder()
+
+	// Field 2 is an uint8 but comes as a varint.
+	_, err := b.Parse(bytes.NewReader([]byte{2<<3 | byte(WireVarint), 1, 0}))
+	assert.ErrorIs(t, err, ErrWireType)
+
+	// Missing end of message.
+	_, err = b.Parse(bytes.NewReader([]byte{2<<3 | byte(WireFixed8), 1}))
+	assert.ErrorIs(t, err, ErrCannotRead)
+
+	// Unknown field cut short.
+	_, err = b.Parse(bytes.NewReader([]byte{9<<3 | byte(WireBytes), 5, 'a'}))
+	assert.ErrorIs(t, err, ErrCannotRead)
+
+	assert.Panics(t, func() { settingsBuilder().Tag(1) })
+	assert.Panics(t, func() { settingsBuilder().Tag(0) })
+}
+
+func TestTagged_Schema(t *testing.T) {
+	s := settingsBuilder().Schema()
+
+	assert.True(t, s.Tagged)
+	assert.Equal(t, 1, s.Fields[0].Tag)
+	assert.Equal(t, 7, s.Fields[6].Tag)
+	assert.Equal(t, 20, s.Fields[7].Tag)
+	assert.Contains(t, s.String(), "tagged struct settings\n  Name =1 string<uint8>")
+}
+
+func TestTagged_Compatibility(t *testing.T) {
+	v1 := Builder[settings](ObjectFactory[settings]()).
+		Tagged().
+		SmallVarchar(
+			func(s *settings) string { return s.Name },
+			func(s *settings, v string) { s.Name = v },
+		).Named("Name").
+		UInt8(
+			func(s *settings) uint8 { return s.Retries },
+			func(s *settings, v uint8) { s.Retries = v },
+		).Named("Retries").
+		UInt16LE(
+			func(s *settings) uint16 { return 0 },
+			func(s *settings, v uint16) {},
+		).Named("Legacy").Tag(50)
+
+	report := CheckCompatibility(v1.Schema(), settingsBuilder().Schema())
+	assert.NoError(t, report.Err())
+	assert.Contains(t, report.String(), "compatible: settings.Legacy: field removed")
+	assert.Contains(t, report.String(), "compatible: settings.Seq: field added")
+
+	renumbered := Builder[settings](ObjectFactory[settings]()).
+		Tagged().
+		SmallVarchar(
+			func(s *settings) string { return s.Name },
+			func(s *settings, v string) { s.Name = v },
+		).Named("Name").Tag(2)
+
+	report = CheckCompatibility(v1.Schema(), renumbered.Schema())
+	assert.ErrorIs(t, report.Err(), ErrIncompatibleSchema)
+
+	report = CheckCompatibility(v1.Schema(), settingsBuilder().Tagged().Schema())
+	assert.NoError(t, report.Err())
+}
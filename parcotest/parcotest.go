@@ -0,0 +1,261 @@
+// Package parcotest fuzzes parco models and types with native Go fuzzing.
+//
+//	func FuzzOrder(f *testing.F) {
+//		parcotest.FuzzRoundTrip(f, orderBuilder, []Order{{ID: 1}, {ID: 2, Items: items}})
+//	}
+//
+// Run it with go test -fuzz FuzzOrder. Without -fuzz, go test runs the seeds
+// only, so targets double as regular tests.
+package parcotest
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/sonirico/parco"
+)
+
+const (
+	// DefaultAllocBase and DefaultAllocPerByte bound the bytes a parse may
+	// allocate: DefaultAllocBase plus DefaultAllocPerByte per byte of input.
+	DefaultAllocBase    = 4 << 20
+	DefaultAllocPerByte = 1 << 10
+	// DefaultTimeout bounds a single parse.
+	DefaultTimeout = time.Second
+)
+
+type (
+	// Codec parses and compiles values of T, as ModelBuilder and the built-in
+	// types do.
+	Codec[T any] interface {
+		parco.ParserType[T]
+		parco.CompilerType[T]
+	}
+
+	// Options tunes FuzzRoundTripWith. Zero fields take the defaults.
+	Options struct {
+		// AllocBase and AllocPerByte bound the bytes a parse may allocate.
+		AllocBase    int
+		AllocPerByte int
+		// Timeout bounds a single parse.
+		Timeout time.Duration
+		// Equal compares round tripped values, Equal by default.
+		Equal func(a, b any) bool
+		// Lenient skips checking that values compile back to the very bytes
+		// they were parsed from, for codecs accepting several encodings of a
+		// value. See FuzzRoundTrip for those it is skipped for anyway.
+		Lenient bool
+	}
+
+	// sizer is implemented by codecs telling encoded sizes, see parco.SizedType.
+	sizer[T any] interface {
+		Size(T) (int, error)
+	}
+
+	// streamReader hides the fast paths of the readers it wraps.
+	streamReader struct {
+		io.Reader
+	}
+)
+
+// FuzzRoundTrip fuzzes the parser of c with seeds, compiled, as the initial
+// corpus, and checks that no input panics, hangs or allocates without bound.
+// Inputs that parse must compile back, and parse again into the same value:
+// Compile(Parse(x)) is x for valid x, byte for byte. Only the byte count is
+// checked for codecs accepting several encodings of a value: those not
+// describing their schema, and those holding bools and options, whose flags
+// read any byte but 1 as false, maps, written in any order, chunked
+// sequences, which may be chunked any way, padding, whose bytes are ignored,
+// times with their location, UTC being written several ways, or tagged or
+// extensible models, which skip what they do not know.
+func FuzzRoundTrip[T any](f *testing.F, c Codec[T], seeds []T) {
+	FuzzRoundTripWith(f, c, seeds, Options{})
+}
+
+// FuzzRoundTripWith is FuzzRoundTrip tuned by opts.
+func FuzzRoundTripWith[T any](f *testing.F, c Codec[T], seeds []T, opts Options) {
+	f.Helper()
+	opts = opts.withDefaults()
+
+	for i, seed := range seeds {
+		data, err := compile(c, seed)
+		if err != nil {
+			f.Fatalf("seed %d: compile: %v", i, err)
+		}
+		value, err := parseAll(c, data)
+		if err != nil {
+			f.Fatalf("seed %d: parse: %v", i, err)
+		}
+		if !opts.Equal(seed, value) {
+			f.Fatalf("seed %d: parsed %+v, want %+v", i, value, seed)
+		}
+		f.Add(data)
+	}
+
+	exact := !opts.Lenient && canonical(parco.Describe(c))
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		value, n, err := parseBounded(t, c, data, opts)
+
+		// Reading a stream takes the paths a cursor skips, to the same end.
+		streamed, streamErr := c.Parse(streamReader{bytes.NewReader(data)})
+		if (err == nil) != (streamErr == nil) {
+			t.Fatalf("input %x: parsing a cursor fails with %v, a stream with %v", data, err, streamErr)
+		}
+		if err != nil {
+			return
+		}
+		if !opts.Equal(value, streamed) {
+			t.Fatalf("input %x: parsing a cursor gives %+v, a stream %+v", data, value, streamed)
+		}
+
+		var parsed []byte
+		if exact {
+			parsed = data[:n]
+		}
+		roundTrip(t, c, value, parsed, opts)
+	})
+}
+
+// roundTrip checks that value, parsed from some input, compiles to bytes
+// parsing back into value and compiling back to as many bytes. If parsed is
+// not nil, value must compile back to it, the bytes value was parsed from.
+func roundTrip[T any](t *testing.T, c Codec[T], value T, parsed []byte, opts Options) {
+	t.Helper()
+
+	data, err := compile(c, value)
+	if err != nil {
+		t.Fatalf("compile %+v, as parsed: %v", value, err)
+	}
+	if parsed != nil && !bytes.Equal(data, parsed) {
+		t.Fatalf("compile %+v: %x, parsed from %x", value, data, parsed)
+	}
+	if s, ok := c.(sizer[T]); ok {
+		if size, err := s.Size(value); err != nil || size != len(data) {
+			t.Fatalf("size of %+v: %d, %v, want %d", value, size, err, len(data))
+		}
+	}
+
+	again, err := parseAll(c, data)
+	if err != nil {
+		t.Fatalf("parse %x, compiled from %+v: %v", data, value, err)
+	}
+	if !opts.Equal(value, again) {
+		t.Fatalf("parse %x: %+v, want %+v", data, again, value)
+	}
+
+	// Maps are written in any order, so bytes may differ, but not their count.
+	dataAgain, err := compile(c, again)
+	if err != nil || len(dataAgain) != len(data) {
+		t.Fatalf("compile %+v again: %x, %v, want %x", again, dataAgain, err, data)
+	}
+}
+
+// parseBounded parses data, failing t if it panics, takes longer than
+// opts.Timeout or allocates more than opts allow. It returns the number of
+// bytes read as well.
+func parseBounded[T any](t *testing.T, c Codec[T], data []byte, opts Options) (value T, n int, err error) {
+	t.Helper()
+
+	type result struct {
+		value T
+		n     int
+		err   error
+		alloc uint64
+		panic any
+	}
+
+	done := make(chan result, 1)
+	go func() {
+		var res result
+		defer func() {
+			res.panic = recover()
+			done <- res
+		}()
+
+		var before, after runtime.MemStats
+		runtime.ReadMemStats(&before)
+		cursor := parco.NewBufferCursor(data, 0)
+		res.value, res.err = c.Parse(&cursor)
+		res.n = cursor.Offset()
+		runtime.ReadMemStats(&after)
+		res.alloc = after.TotalAlloc - before.TotalAlloc
+	}()
+
+	select {
+	case res := <-done:
+		if res.panic != nil {
+			t.Fatalf("input %x: parse panicked: %v", data, res.panic)
+		}
+		if limit := uint64(opts.AllocBase + opts.AllocPerByte*len(data)); res.alloc > limit {
+			t.Fatalf("input %x: parse allocated %d bytes, more than %d", data, res.alloc, limit)
+		}
+		return res.value, res.n, res.err
+	case <-time.After(opts.Timeout):
+		t.Fatalf("input %x: parse still running after %v", data, opts.Timeout)
+	}
+	return
+}
+
+// parseAll parses data, which must be read to the end.
+func parseAll[T any](c Codec[T], data []byte) (T, error) {
+	cursor := parco.NewBufferCursor(data, 0)
+	value, err := c.Parse(&cursor)
+	if err == nil && cursor.Offset() != len(data) {
+		err = fmt.Errorf("%d bytes read out of %d", cursor.Offset(), len(data))
+	}
+	return value, err
+}
+
+// canonical reports whether values of s have a single encoding.
+func canonical(s parco.Schema) bool {
+	switch s.Kind {
+	case parco.KindUnknown, parco.KindOption, parco.KindMap, parco.KindChunked, parco.KindSkip:
+		return false
+	case parco.KindFixed:
+		if s.Type == "bool" {
+			return false
+		}
+	case parco.KindStruct:
+		if s.Tagged || s.Header != nil {
+			return false
+		}
+	case parco.KindTime:
+		if s.Location {
+			return false
+		}
+	}
+
+	for _, f := range s.Fields {
+		if !canonical(f) {
+			return false
+		}
+	}
+	return (s.Elem == nil || canonical(*s.Elem)) && (s.Key == nil || canonical(*s.Key))
+}
+
+func compile[T any](c Codec[T], value T) ([]byte, error) {
+	buf := bytes.NewBuffer(nil)
+	err := c.Compile(value, buf)
+	return buf.Bytes(), err
+}
+
+func (o Options) withDefaults() Options {
+	if o.AllocBase == 0 {
+		o.AllocBase = DefaultAllocBase
+	}
+	if o.AllocPerByte == 0 {
+		o.AllocPerByte = DefaultAllocPerByte
+	}
+	if o.Timeout == 0 {
+		o.Timeout = DefaultTimeout
+	}
+	if o.Equal == nil {
+		o.Equal = Equal
+	}
+	return o
+}
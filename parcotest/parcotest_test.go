@@ -0,0 +1,191 @@
+package parcotest
+
+import (
+	"encoding/binary"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/sonirico/parco"
+)
+
+type (
+	line struct {
+		SKU   string
+		Price float64
+	}
+
+	order struct {
+		ID     uint32
+		At     time.Time
+		Lines  []line
+		Labels map[string]int64
+		Note   *string
+	}
+)
+
+func lineBuilder() parco.ModelBuilder[line] {
+	return parco.Builder[line](parco.ObjectFactory[line]()).
+		SmallVarchar(
+			func(l *line) string { return l.SKU },
+			func(l *line, v string) { l.SKU = v },
+		).Named("SKU").
+		Float64(binary.LittleEndian,
+			func(l *line) float64 { return l.Price },
+			func(l *line, v float64) { l.Price = v },
+		).Named("Price")
+}
+
+func orderBuilder() parco.ModelBuilder[order] {
+	// Times are written as Unix nanoseconds, which the zero time overflows:
+	// fields missing from extensible messages must default to another.
+	factory := parco.FuncFactory[order](func() order { return order{At: time.Unix(0, 0)} })
+	return parco.Builder[order](factory).
+		Extensible(parco.VarUIntHeader()).
+		VarUInt32(
+			func(o *order) uint32 { return o.ID },
+			func(o *order, v uint32) { o.ID = v },
+		).Named("ID").
+		TimeUTC(
+			func(o *order) time.Time { return o.At },
+			func(o *order, v time.Time) { o.At = v },
+		).Named("At").
+		Slice(parco.SliceField[order, line](
+			parco.UInt8Header(),
+			parco.Struct[line](lineBuilder()),
+			func(o *order, v parco.SliceView[line]) { o.Lines = v },
+			func(o *order) parco.SliceView[line] { return o.Lines },
+		)).Named("Lines").
+		Map(parco.MapField[order, string, int64](
+			parco.VarUIntHeader(),
+			parco.SmallVarchar(),
+			parco.VarInt64(),
+			func(o *order, v map[string]int64) { o.Labels = v },
+			func(o *order) map[string]int64 { return o.Labels },
+		)).Named("Labels").
+		Option(parco.OptionField[order, string](
+			parco.Varchar(),
+			func(o *order, v *string) { o.Note = v },
+			func(o *order) *string { return o.Note },
+		)).Named("Note")
+}
+
+func FuzzModel(f *testing.F) {
+	note := "fragile"
+	FuzzRoundTrip(f, orderBuilder(), []order{
+		{At: time.Unix(0, 0)},
+		{ID: 1, At: time.Unix(1700000000, 5), Lines: []line{{"a", 1.5}, {"b", math.NaN()}}},
+		{ID: 300, At: time.Unix(0, 0), Labels: map[string]int64{"x": -1, "y": math.MaxInt64}, Note: &note},
+	})
+}
+
+func FuzzPlainModel(f *testing.F) {
+	FuzzRoundTrip(f, lineBuilder(), []line{{}, {"a", 2}, {"ñ", math.NaN()}})
+}
+
+func FuzzTaggedModel(f *testing.F) {
+	tagged := parco.Builder[line](parco.ObjectFactory[line]()).
+		Tagged().
+		SmallVarchar(
+			func(l *line) string { return l.SKU },
+			func(l *line, v string) { l.SKU = v },
+		).
+		Float64(binary.LittleEndian,
+			func(l *line) float64 { return l.Price },
+			func(l *line, v float64) { l.Price = v },
+		)
+	FuzzRoundTrip(f, tagged, []line{{}, {"a", 2}, {"", math.Inf(-1)}})
+}
+
+func FuzzVarInt(f *testing.F) {
+	FuzzRoundTrip(f, parco.VarInt64(), []int64{0, -1, 63, -64, math.MaxInt64, math.MinInt64})
+}
+
+func FuzzVarUInt(f *testing.F) {
+	FuzzRoundTrip(f, parco.VarUInt32(), []uint32{0, 127, 128, math.MaxUint32})
+}
+
+func FuzzUInt32(f *testing.F) {
+	FuzzRoundTrip(f, parco.UInt32BE(), []uint32{0, 1, math.MaxUint32})
+}
+
+func FuzzInt16(f *testing.F) {
+	FuzzRoundTrip(f, parco.Int16LE(), []int16{0, -1, math.MinInt16, math.MaxInt16})
+}
+
+func FuzzFloat64(f *testing.F) {
+	FuzzRoundTrip(f, parco.Float64LE(), []float64{0, math.Copysign(0, -1), math.NaN(), math.Inf(1), math.SmallestNonzeroFloat64})
+}
+
+func FuzzBool(f *testing.F) {
+	FuzzRoundTrip(f, parco.Bool(), []bool{false, true})
+}
+
+func FuzzArray(f *testing.F) {
+	FuzzRoundTrip[parco.Iterable[uint16]](f, parco.Array(3, parco.UInt16LE()), []parco.Iterable[uint16]{
+		parco.SliceView[uint16]{0, 0, 0},
+		parco.SliceView[uint16]{1, 2, math.MaxUint16},
+	})
+}
+
+func FuzzTime(f *testing.F) {
+	FuzzRoundTrip(f, parco.TimeUTC(), []time.Time{time.Unix(0, 0), time.Unix(1700000000, 123)})
+}
+
+func FuzzTimeLocation(f *testing.F) {
+	FuzzRoundTrip(f, parco.TimeLocation(), []time.Time{time.Unix(0, 0).UTC(), time.Unix(1700000000, 0).In(time.FixedZone("", 0))})
+}
+
+func FuzzVarchar(f *testing.F) {
+	FuzzRoundTrip(f, parco.SmallVarchar(), []string{"", "parco", "ñ"})
+}
+
+func FuzzBlob(f *testing.F) {
+	FuzzRoundTrip(f, parco.Blob(parco.VarUIntHeader()), [][]byte{nil, {0, 1, 2}})
+}
+
+func FuzzMap(f *testing.F) {
+	FuzzRoundTrip[map[string]int32](f, parco.MapType(parco.UInt8Header(), parco.SmallVarchar(), parco.VarInt32()), []map[string]int32{
+		nil,
+		{"a": 1, "b": -2, "c": math.MaxInt32},
+	})
+}
+
+func FuzzOptional(f *testing.F) {
+	some := uint16(7)
+	FuzzRoundTrip[*uint16](f, parco.Option(parco.UInt16LE()), []*uint16{nil, &some})
+}
+
+func FuzzSlice(f *testing.F) {
+	FuzzRoundTrip[parco.Iterable[float32]](f, parco.Slice(parco.VarUIntHeader(), parco.Float32LE()), []parco.Iterable[float32]{
+		parco.SliceView[float32]{},
+		parco.SliceView[float32]{1, float32(math.NaN()), float32(math.Inf(1))},
+	})
+}
+
+func FuzzChunked(f *testing.F) {
+	FuzzRoundTrip[parco.Iterable[string]](f, parco.Chunked(parco.UInt8Header(), 2, parco.SmallVarchar()), []parco.Iterable[string]{
+		parco.SliceView[string]{},
+		parco.SliceView[string]{"a", "bc", "d"},
+	})
+}
+
+func TestEqual(t *testing.T) {
+	nan := math.NaN()
+	a, b := "a", "a"
+
+	assert.True(t, Equal(nan, nan))
+	assert.True(t, Equal([]int(nil), []int{}))
+	assert.True(t, Equal(map[string]int{"x": 1, "y": 2}, map[string]int{"y": 2, "x": 1}))
+	assert.True(t, Equal(&a, &b))
+	assert.True(t, Equal(time.Unix(1, 0), time.Unix(1, 0).UTC()))
+	assert.True(t, Equal(line{"a", nan}, line{"a", nan}))
+
+	assert.False(t, Equal(1, 2))
+	assert.False(t, Equal(int32(1), int64(1)))
+	assert.False(t, Equal(&a, nil))
+	assert.False(t, Equal(map[string]int{"x": 1}, map[string]int{"x": 2}))
+	assert.False(t, Equal([]line{{"a", 1}}, []line{{"b", 1}}))
+}
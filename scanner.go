@@ -0,0 +1,111 @@
+package parco
+
+import (
+	"errors"
+	"io"
+)
+
+type (
+	// Scanner reads a stream of concatenated messages, e.g. a log file, one
+	// message at a time:
+	//
+	//	s := orderBuilder.Scan(file)
+	//	for s.Next() {
+	//		handle(s.Value())
+	//	}
+	//	if err := s.Err(); err != nil {
+	//		// a truncated or corrupted message
+	//	}
+	//
+	// The stream ending cleanly between two messages stops the scanner with no
+	// error, while ending within a message is reported by Err like any other
+	// failure, as ErrCannotRead.
+	Scanner[T any] struct {
+		r     OffsetReader
+		parse func(io.Reader) (T, error)
+		// pool, if set, takes back every value once the next one is asked for.
+		pool  PoolFactory[T]
+		value T
+		// held tells whether value was read, to be handed back to pool.
+		held bool
+		err  error
+		done bool
+	}
+
+	// Message is a message read by a ModelMultiBuilder along with its type id.
+	Message[T comparable] struct {
+		ID    T
+		Value any
+	}
+)
+
+// NewScanner reads the messages of r with p, e.g. a ModelBuilder. Parse
+// errors are located in the whole stream.
+func NewScanner[T any](r io.Reader, p ParserType[T]) *Scanner[T] {
+	return newScanner(r, p.Parse)
+}
+
+func newScanner[T any](r io.Reader, parse func(io.Reader) (T, error)) *Scanner[T] {
+	or, ok := r.(OffsetReader)
+	if !ok {
+		or = NewCountingReader(r)
+	}
+	return &Scanner[T]{r: or, parse: parse}
+}
+
+// Recycle hands every value back to pool, e.g. the PooledFactory of the
+// model, when the next one is asked for, so that scanning reuses the same
+// few values. Values must not be used past the following call to Next.
+func (s *Scanner[T]) Recycle(pool PoolFactory[T]) *Scanner[T] {
+	s.pool = pool
+	return s
+}
+
+// Next reads the next message, reporting whether there was one. It returns
+// false at the end of the stream and on the first failure, see Err.
+func (s *Scanner[T]) Next() bool {
+	if s.done {
+		return false
+	}
+
+	if s.held && s.pool != nil {
+		s.pool.Put(s.value)
+	}
+	var zero T
+	s.value, s.held = zero, false
+
+	start := s.r.Offset()
+	value, err := s.parse(s.r)
+	if err != nil {
+		s.done = true
+		if !atEOF(s.r, start, err) {
+			s.err = err
+		}
+		return false
+	}
+
+	s.value, s.held = value, true
+	return true
+}
+
+// Value returns the message read by the last call to Next.
+func (s *Scanner[T]) Value() T {
+	return s.value
+}
+
+// Err returns the failure that stopped the scanner, nil at the end of the
+// stream.
+func (s *Scanner[T]) Err() error {
+	return s.err
+}
+
+// Offset returns the number of bytes of the stream read so far.
+func (s *Scanner[T]) Offset() int {
+	return s.r.Offset()
+}
+
+// atEOF reports whether err, raised reading from r from start, is the clean
+// end of the stream: nothing was read.
+func atEOF(r OffsetReader, start int, err error) bool {
+	return r.Offset() == start && (errors.Is(err, ErrCannotRead) || errors.Is(err, io.EOF))
+}
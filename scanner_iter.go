@@ -0,0 +1,49 @@
+//go:build go1.23
+
+package parco
+
+import (
+	"io"
+	"iter"
+)
+
+// All returns an iterator over the remaining messages, e.g.
+//
+//	for order, err := range orderBuilder.Scan(file).All() {
+//		if err != nil {
+//			return err
+//		}
+//		handle(order)
+//	}
+//
+// A failure is yielded once, with the zero value, and ends the iteration.
+// The clean end of the stream ends it with no error.
+func (s *Scanner[T]) All() iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		for s.Next() {
+			if !yield(s.Value(), nil) {
+				return
+			}
+		}
+		if err := s.Err(); err != nil {
+			var zero T
+			yield(zero, err)
+		}
+	}
+}
+
+// All iterates over the messages concatenated in r. See Scanner.All.
+func (p *Parser[T]) All(r io.Reader) iter.Seq2[T, error] {
+	return p.Scan(r).All()
+}
+
+// All iterates over the messages concatenated in r. See Scanner.All.
+func (b ModelBuilder[T]) All(r io.Reader) iter.Seq2[T, error] {
+	return b.Scan(r).All()
+}
+
+// All iterates over the messages concatenated in r, whatever their type.
+// See Scanner.All.
+func (b *ModelMultiBuilder[T]) All(r io.Reader) iter.Seq2[Message[T], error] {
+	return b.Scan(r).All()
+}
@@ -0,0 +1,46 @@
+//go:build go1.23
+
+package parco
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScanner_All(t *testing.T) {
+	items := []parseItem{{"ab", 1}, {"cd", 2}}
+	data := compileParseItems(t, items...)
+
+	var scanned []parseItem
+	for item, err := range parseItemBuilder().All(bytes.NewReader(data)) {
+		require.NoError(t, err)
+		scanned = append(scanned, item)
+	}
+	assert.Equal(t, items, scanned)
+
+	// Failures are yielded last.
+	var errs []error
+	for _, err := range parseItemBuilder().All(bytes.NewReader(data[:len(data)-1])) {
+		errs = append(errs, err)
+	}
+	require.Len(t, errs, 2)
+	assert.NoError(t, errs[0])
+	assert.ErrorIs(t, errs[1], ErrCannotRead)
+
+	// Breaking out stops reading.
+	s := parseItemBuilder().Scan(bytes.NewReader(data))
+	for range s.All() {
+		break
+	}
+	assert.Equal(t, 7, s.Offset())
+
+	n := 0
+	for msg, err := range multiBuilder().All(bytes.NewReader(nil)) {
+		n++
+		_, _ = msg, err
+	}
+	assert.Zero(t, n)
+}
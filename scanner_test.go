@@ -0,0 +1,120 @@
+package parco
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// countingPool counts the values handed out and back.
+type countingPool struct {
+	gets, puts int
+}
+
+func (p *countingPool) Get() parseItem {
+	p.gets++
+	return parseItem{}
+}
+
+func (p *countingPool) Put(parseItem) {
+	p.puts++
+}
+
+func compileParseItems(t *testing.T, items ...parseItem) []byte {
+	t.Helper()
+	var data []byte
+	for _, item := range items {
+		var err error
+		data, err = parseItemBuilder().Append(data, item)
+		require.NoError(t, err)
+	}
+	return data
+}
+
+func TestScanner(t *testing.T) {
+	items := []parseItem{{"ab", 1}, {"cd", 2}, {"", 3}}
+	data := compileParseItems(t, items...)
+
+	// Streams and cursors alike.
+	cursor := NewBufferCursor(data, 0)
+	for name, s := range map[string]*Scanner[parseItem]{
+		"stream": parseItemBuilder().Scan(bytes.NewReader(data)),
+		"cursor": NewScanner[parseItem](&cursor, parseItemBuilder()),
+	} {
+		t.Run(name, func(t *testing.T) {
+			var scanned []parseItem
+			for s.Next() {
+				scanned = append(scanned, s.Value())
+			}
+			require.NoError(t, s.Err())
+			assert.Equal(t, items, scanned)
+			assert.Equal(t, len(data), s.Offset())
+			assert.False(t, s.Next())
+		})
+	}
+}
+
+func TestScanner_Truncated(t *testing.T) {
+	data := compileParseItems(t, parseItem{"ab", 1}, parseItem{"cd", 2})
+
+	s := parseItemBuilder().Scan(bytes.NewReader(data[:len(data)-2]))
+	require.True(t, s.Next())
+	assert.Equal(t, parseItem{"ab", 1}, s.Value())
+
+	assert.False(t, s.Next())
+	assert.ErrorIs(t, s.Err(), ErrCannotRead)
+	var parseErr *ParseError
+	require.ErrorAs(t, s.Err(), &parseErr)
+	assert.Equal(t, 7, parseErr.MessageStart)
+	assert.Equal(t, "parseItem.Price", parseErr.Path)
+
+	// An empty stream holds no message, and no error either.
+	s = parseItemBuilder().Scan(bytes.NewReader(nil))
+	assert.False(t, s.Next())
+	assert.NoError(t, s.Err())
+}
+
+func TestScanner_Recycle(t *testing.T) {
+	pool := &countingPool{}
+	builder := Builder[parseItem](pool).
+		SmallVarchar(
+			func(i *parseItem) string { return i.SKU },
+			func(i *parseItem, v string) { i.SKU = v },
+		).
+		UInt32(binary.LittleEndian,
+			func(i *parseItem) uint32 { return i.Price },
+			func(i *parseItem, v uint32) { i.Price = v },
+		)
+
+	data := compileParseItems(t, parseItem{"ab", 1}, parseItem{"cd", 2}, parseItem{"ef", 3})
+	s := builder.Scan(bytes.NewReader(data)).Recycle(pool)
+
+	n := 0
+	for s.Next() {
+		n++
+		assert.Equal(t, n-1, pool.puts)
+	}
+	require.NoError(t, s.Err())
+	assert.Equal(t, 3, pool.puts)
+	// The value got for the message past the end is never read.
+	assert.Equal(t, 4, pool.gets)
+}
+
+func TestModelMultiBuilder_Scan(t *testing.T) {
+	b := multiBuilder()
+	data, err := b.AppendAny(nil, multiItemType, parseItem{"ab", 1})
+	require.NoError(t, err)
+	data, err = b.AppendAny(data, multiOrderType, parseOrder{ID: 3})
+	require.NoError(t, err)
+
+	s := b.Scan(bytes.NewReader(data))
+	require.True(t, s.Next())
+	assert.Equal(t, Message[int]{ID: multiItemType, Value: parseItem{"ab", 1}}, s.Value())
+	require.True(t, s.Next())
+	assert.Equal(t, multiOrderType, s.Value().ID)
+	assert.False(t, s.Next())
+	assert.NoError(t, s.Err())
+}
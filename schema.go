@@ -0,0 +1,312 @@
+package parco
+
+import (
+	"encoding/binary"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Kind classifies how a type is laid out on the wire.
+type Kind uint8
+
+const (
+	// KindUnknown describes types that do not expose a schema, e.g. custom
+	// Type implementations.
+	KindUnknown Kind = iota
+	// KindFixed is a fixed width value: integers, floats, bools.
+	KindFixed
+	// KindVarint is a LEB128 (zigzag for signed) variable width integer.
+	KindVarint
+	// KindVarchar is a length prefixed string or blob.
+	KindVarchar
+	// KindSlice is a length prefixed sequence of elements.
+	KindSlice
+	// KindArray is a fixed length sequence of elements.
+	KindArray
+	// KindMap is a length prefixed sequence of key/value pairs.
+	KindMap
+	// KindStruct is a nested model.
+	KindStruct
+	// KindOption is a presence flag followed by the value when present.
+	KindOption
+	// KindTime is a time.Time, optionally followed by its location.
+	KindTime
+	// KindSkip is padding.
+	KindSkip
+	// KindChunked is a sequence of elements in count prefixed chunks, ended
+	// by an empty chunk.
+	KindChunked
+)
+
+var kindNames = [...]string{
+	KindUnknown: "unknown",
+	KindFixed:   "fixed",
+	KindVarint:  "varint",
+	KindVarchar: "varchar",
+	KindSlice:   "slice",
+	KindArray:   "array",
+	KindMap:     "map",
+	KindStruct:  "struct",
+	KindOption:  "option",
+	KindTime:    "time",
+	KindSkip:    "skip",
+	KindChunked: "chunked",
+}
+
+func (k Kind) String() string {
+	if int(k) < len(kindNames) {
+		return kindNames[k]
+	}
+	return "kind(" + strconv.Itoa(int(k)) + ")"
+}
+
+type (
+	// Schema describes the wire layout of a type or of a model field. Models
+	// are described as KindStruct schemas whose Fields follow wire order.
+	Schema struct {
+		// Name is the field name, if any. Types on their own are anonymous.
+		Name string
+		Kind Kind
+		// Type names the value domain: uint8..uint64, int8..int64, uint, int,
+		// float32, float64, bool, string, bytes and time. For structs it is the
+		// model's Go type name.
+		Type string
+		// ByteLength is the encoded width of fixed size values, 0 otherwise.
+		ByteLength int
+		// Order is the byte order of multi-byte values, nil if not relevant.
+		Order binary.ByteOrder
+		// Header describes the length prefix of varchars, slices, maps and
+		// extensible structs, the chunk counts of chunked sequences, and the
+		// presence flag of options.
+		Header *Schema
+		// Elem describes the elements of slices, chunked sequences, arrays and
+		// options, and the values of maps.
+		Elem *Schema
+		// Key describes the keys of maps.
+		Key *Schema
+		// Length is the element count of arrays.
+		Length int
+		// Location reports whether a time carries its location.
+		Location bool
+		// Fields lists the fields of a struct in wire order.
+		Fields []Schema
+		// Tagged reports whether a struct writes field numbers.
+		Tagged bool
+		// Tag is the field number in tagged structs, 0 otherwise.
+		Tag int
+		// Checksum names the checksum following the messages of a model, e.g.
+		// "crc32c", empty if none.
+		Checksum string
+	}
+
+	// Describer is implemented by types, fields and models that can report
+	// their wire layout.
+	Describer interface {
+		Schema() Schema
+	}
+)
+
+// Describe returns the schema of x, or a KindUnknown schema when x does not
+// implement Describer.
+func Describe(x any) Schema {
+	if d, ok := x.(Describer); ok {
+		return d.Schema()
+	}
+	return Schema{Kind: KindUnknown}
+}
+
+func describePtr(x any) *Schema {
+	s := Describe(x)
+	return &s
+}
+
+// Schema returns s itself, so that a Schema built or loaded at runtime is a
+// Describer on its own.
+func (s Schema) Schema() Schema {
+	return s
+}
+
+// Field returns the field with the given name.
+func (s Schema) Field(name string) (Schema, bool) {
+	for _, f := range s.Fields {
+		if f.Name == name {
+			return f, true
+		}
+	}
+	return Schema{}, false
+}
+
+// TypeString renders the type of s on a single line, e.g. "slice<uint8>[string<uint16 LE>]".
+func (s Schema) TypeString() string {
+	var b strings.Builder
+	s.writeType(&b)
+	return b.String()
+}
+
+func (s Schema) writeType(b *strings.Builder) {
+	switch s.Kind {
+	case KindFixed:
+		b.WriteString(If(s.Type != "", s.Type, "fixed("+strconv.Itoa(s.ByteLength)+")"))
+		writeOrder(b, s.Order)
+	case KindVarint:
+		b.WriteString("var" + s.Type)
+	case KindVarchar:
+		b.WriteString(s.Type)
+		writeHeader(b, s.Header)
+	case KindSlice:
+		b.WriteString("slice")
+		writeHeader(b, s.Header)
+		b.WriteByte('[')
+		s.Elem.writeType(b)
+		b.WriteByte(']')
+	case KindChunked:
+		b.WriteString("chunked")
+		writeHeader(b, s.Header)
+		b.WriteByte('[')
+		s.Elem.writeType(b)
+		b.WriteByte(']')
+	case KindArray:
+		b.WriteString("array(" + strconv.Itoa(s.Length) + ")[")
+		s.Elem.writeType(b)
+		b.WriteByte(']')
+	case KindMap:
+		b.WriteString("map")
+		writeHeader(b, s.Header)
+		b.WriteByte('[')
+		s.Key.writeType(b)
+		b.WriteString("]")
+		s.Elem.writeType(b)
+	case KindOption:
+		b.WriteString("option[")
+		s.Elem.writeType(b)
+		b.WriteByte(']')
+	case KindStruct:
+		b.WriteString(If(s.Tagged, "tagged struct", "struct"))
+		writeHeader(b, s.Header)
+		if s.Type != "" {
+			b.WriteString(" " + s.Type)
+		}
+		if s.Checksum != "" {
+			b.WriteString(" checksum " + s.Checksum)
+		}
+	case KindTime:
+		b.WriteString(If(s.Location, "time+location", "time"))
+	case KindSkip:
+		b.WriteString("skip(" + strconv.Itoa(s.ByteLength) + ")")
+	default:
+		b.WriteString(s.Kind.String())
+	}
+}
+
+func writeHeader(b *strings.Builder, header *Schema) {
+	if header == nil {
+		return
+	}
+	b.WriteByte('<')
+	header.writeType(b)
+	b.WriteByte('>')
+}
+
+func writeOrder(b *strings.Builder, order binary.ByteOrder) {
+	switch order {
+	case binary.LittleEndian:
+		b.WriteString(" LE")
+	case binary.BigEndian:
+		b.WriteString(" BE")
+	}
+}
+
+// String renders s as an indented tree, one field per line.
+func (s Schema) String() string {
+	var b strings.Builder
+	s.writeTree(&b, 0)
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+func (s Schema) writeTree(b *strings.Builder, depth int) {
+	b.WriteString(strings.Repeat("  ", depth))
+	if s.Name != "" {
+		b.WriteString(s.Name + " ")
+	}
+	if s.Tag > 0 {
+		b.WriteString("=" + strconv.Itoa(s.Tag) + " ")
+	}
+	s.writeType(b)
+	b.WriteByte('\n')
+
+	// Descend into the struct reachable from this node, if any.
+	inner := &s
+	for inner != nil && inner.Kind != KindStruct {
+		inner = inner.Elem
+	}
+	if inner != nil {
+		for _, f := range inner.Fields {
+			f.writeTree(b, depth+1)
+		}
+	}
+}
+
+// typeName returns the unqualified name of T.
+func typeName[T any]() string {
+	var zero T
+	name := fmt.Sprintf("%T", zero)
+	if i := strings.LastIndexByte(name, '.'); i >= 0 && !strings.ContainsAny(name[i:], "[]") {
+		return name[i+1:]
+	}
+	return name
+}
+
+func modelSchema[T, F any](fields []F) Schema {
+	s := Schema{
+		Kind:   KindStruct,
+		Type:   typeName[T](),
+		Fields: make([]Schema, len(fields)),
+	}
+	for i, f := range fields {
+		s.Fields[i] = Describe(f)
+	}
+	return s
+}
+
+// typed returns s with the type name name, if not empty.
+func (s Schema) typed(name string) Schema {
+	if name != "" {
+		s.Type = name
+	}
+	return s
+}
+
+// enveloped returns s carrying the length header of extensible models.
+func (s Schema) enveloped(header IntType) Schema {
+	if header != nil {
+		s.Header = describePtr(header)
+	}
+	return s
+}
+
+// checksummed returns s carrying the name of the checksum of its messages.
+func (s Schema) checksummed(sum Checksum) Schema {
+	if sum != nil {
+		s.Checksum = sum.Name()
+	}
+	return s
+}
+
+// numbered returns s carrying the field numbers of tagged models.
+func (s Schema) numbered(tagged bool, tags []fieldTag) Schema {
+	if !tagged {
+		return s
+	}
+	s.Tagged = true
+	for i := range s.Fields {
+		s.Fields[i].Tag = tags[i].num
+	}
+	return s
+}
+
+// named returns s carrying the field name.
+func (s Schema) named(name string) Schema {
+	s.Name = name
+	return s
+}
@@ -0,0 +1,458 @@
+package parco
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Severity tells whether a schema change keeps old and new peers able to talk.
+type Severity uint8
+
+const (
+	// Compatible changes leave the wire layout untouched, e.g. renames.
+	Compatible Severity = iota
+	// Breaking changes alter the wire layout: one side will misread the other.
+	Breaking
+)
+
+func (s Severity) String() string {
+	if s == Breaking {
+		return "breaking"
+	}
+	return "compatible"
+}
+
+// ChangeType classifies a difference between two schemas.
+type ChangeType uint8
+
+const (
+	FieldRenamed ChangeType = iota
+	FieldAdded
+	FieldRemoved
+	FieldReordered
+	KindChanged
+	TypeChanged
+	WidthChanged
+	ByteOrderChanged
+	HeaderChanged
+	LengthChanged
+	LocationChanged
+	TagChanged
+	ChecksumChanged
+)
+
+var changeTypeNames = [...]string{
+	FieldRenamed:     "field renamed",
+	FieldAdded:       "field added",
+	FieldRemoved:     "field removed",
+	FieldReordered:   "field reordered",
+	KindChanged:      "kind changed",
+	TypeChanged:      "type changed",
+	WidthChanged:     "width changed",
+	ByteOrderChanged: "byte order changed",
+	HeaderChanged:    "header changed",
+	LengthChanged:    "length changed",
+	LocationChanged:  "location changed",
+	TagChanged:       "field number changed",
+	ChecksumChanged:  "checksum changed",
+}
+
+func (c ChangeType) String() string {
+	if int(c) < len(changeTypeNames) {
+		return changeTypeNames[c]
+	}
+	return "change(" + strconv.Itoa(int(c)) + ")"
+}
+
+type (
+	// SchemaChange is a single difference found between two schemas.
+	SchemaChange struct {
+		// Path locates the change, e.g. "Order.Items[].Price".
+		Path     string
+		Type     ChangeType
+		Severity Severity
+		Old, New string
+	}
+
+	// CompatReport lists the differences between two schema versions.
+	CompatReport struct {
+		Changes []SchemaChange
+	}
+)
+
+func (c SchemaChange) String() string {
+	var b strings.Builder
+	b.WriteString(c.Severity.String() + ": " + c.Path + ": " + c.Type.String())
+	if c.Old != "" || c.New != "" {
+		b.WriteString(" (" + If(c.Old != "", c.Old, "-") + " -> " + If(c.New != "", c.New, "-") + ")")
+	}
+	return b.String()
+}
+
+// Compatible reports whether no breaking change was found.
+func (r CompatReport) Compatible() bool {
+	return len(r.Breaking()) == 0
+}
+
+// Breaking returns the changes that break wire compatibility.
+func (r CompatReport) Breaking() []SchemaChange {
+	var res []SchemaChange
+	for _, c := range r.Changes {
+		if c.Severity == Breaking {
+			res = append(res, c)
+		}
+	}
+	return res
+}
+
+// Err returns nil when the schemas are wire compatible, or an error listing
+// every breaking change otherwise. Meant to be used as a guard in tests:
+//
+//	require.NoError(t, parco.CheckCompatibility(v1.Schema(), v2.Schema()).Err())
+func (r CompatReport) Err() error {
+	breaking := r.Breaking()
+	if len(breaking) == 0 {
+		return nil
+	}
+	lines := make([]string, len(breaking))
+	for i, c := range breaking {
+		lines[i] = c.String()
+	}
+	return fmt.Errorf("%w:\n%s", ErrIncompatibleSchema, strings.Join(lines, "\n"))
+}
+
+func (r CompatReport) String() string {
+	lines := make([]string, len(r.Changes))
+	for i, c := range r.Changes {
+		lines[i] = c.String()
+	}
+	return strings.Join(lines, "\n")
+}
+
+// CheckCompatibility compares the schema an old peer uses with the schema of
+// a new one, as returned by ModelBuilder.Schema, and reports every difference.
+//
+// Fields are matched by name when every field is named (see
+// ModelBuilder.Named), by position otherwise. Parco's layout is positional:
+// any change of kind, width, byte order, header or checksum, and any added,
+// removed or moved field breaks the wire format, and so does any type change
+// but integers changing signedness at the same width (e.g. uint32 to int32):
+// uint32 to float32 keeps the bytes, not the values. Renames are compatible,
+// and so are fields added or removed at the end of extensible models (see
+// ModelBuilder.Extensible). Fields of tagged models (see ModelBuilder.Tagged)
+// are matched by number instead: they can be added, removed and moved.
+func CheckCompatibility(old, new Schema) CompatReport {
+	c := compatChecker{}
+	root := If(new.Type != "", new.Type, old.Type)
+	c.compare(root, old, new)
+	return CompatReport{Changes: c.changes}
+}
+
+type compatChecker struct {
+	changes []SchemaChange
+}
+
+func (c *compatChecker) add(path string, tp ChangeType, severity Severity, old, new string) {
+	c.changes = append(c.changes, SchemaChange{
+		Path:     path,
+		Type:     tp,
+		Severity: severity,
+		Old:      old,
+		New:      new,
+	})
+}
+
+func (c *compatChecker) compare(path string, old, new Schema) {
+	if old.Kind != new.Kind {
+		c.add(path, KindChanged, Breaking, old.TypeString(), new.TypeString())
+		return
+	}
+
+	switch old.Kind {
+	case KindFixed:
+		c.compareFixed(path, old, new)
+	case KindVarint:
+		if signed(old.Type) != signed(new.Type) {
+			c.add(path, TypeChanged, Breaking, old.TypeString(), new.TypeString())
+		} else if old.Type != new.Type {
+			// Varints of different widths share their encoding, but values
+			// beyond the narrower range fail to parse.
+			c.add(path, WidthChanged, Breaking, old.TypeString(), new.TypeString())
+		}
+	case KindVarchar:
+		if old.Type != new.Type {
+			c.add(path, TypeChanged, Compatible, old.Type, new.Type)
+		}
+		c.compareHeader(path, old.Header, new.Header)
+	case KindSlice, KindChunked:
+		c.compareHeader(path, old.Header, new.Header)
+		c.compareElem(path+"[]", old.Elem, new.Elem)
+	case KindArray:
+		if old.Length != new.Length {
+			c.add(path, LengthChanged, Breaking, strconv.Itoa(old.Length), strconv.Itoa(new.Length))
+		}
+		c.compareElem(path+"[]", old.Elem, new.Elem)
+	case KindMap:
+		c.compareHeader(path, old.Header, new.Header)
+		c.compareElem(path+"{key}", old.Key, new.Key)
+		c.compareElem(path+"{}", old.Elem, new.Elem)
+	case KindOption:
+		c.compareElem(path, old.Elem, new.Elem)
+	case KindTime:
+		if old.Location != new.Location {
+			c.add(path, LocationChanged, Breaking, old.TypeString(), new.TypeString())
+		}
+	case KindSkip:
+		if old.ByteLength != new.ByteLength {
+			c.add(path, WidthChanged, Breaking, old.TypeString(), new.TypeString())
+		}
+	case KindStruct:
+		c.compareHeader(path, old.Header, new.Header)
+		if old.Checksum != new.Checksum {
+			c.add(path, ChecksumChanged, Breaking, old.Checksum, new.Checksum)
+		}
+		if old.Tagged || new.Tagged {
+			c.compareTagged(path, old, new)
+			return
+		}
+		c.compareFields(path, old.Fields, new.Fields, old.Header != nil && new.Header != nil)
+	}
+}
+
+func (c *compatChecker) compareFixed(path string, old, new Schema) {
+	if old.ByteLength != new.ByteLength {
+		c.add(path, WidthChanged, Breaking, old.TypeString(), new.TypeString())
+		return
+	}
+	if old.ByteLength > 1 && old.Order != new.Order {
+		c.add(path, ByteOrderChanged, Breaking, old.TypeString(), new.TypeString())
+		return
+	}
+	if old.Type == new.Type {
+		return
+	}
+	// Same width and order: the bytes are identical, only their meaning
+	// changes. Integers changing signedness keep small values and wrap the
+	// others, which is deemed acceptable; anything else, such as an integer
+	// read as a float or a bool, reinterprets every value.
+	severity := Breaking
+	if isInteger(old.Type) && isInteger(new.Type) {
+		severity = Compatible
+	}
+	c.add(path, TypeChanged, severity, old.TypeString(), new.TypeString())
+}
+
+func (c *compatChecker) compareHeader(path string, old, new *Schema) {
+	if old == nil || new == nil {
+		if old != new {
+			c.add(path, HeaderChanged, Breaking, "", "")
+		}
+		return
+	}
+
+	sub := compatChecker{}
+	sub.compare(path, *old, *new)
+	for _, change := range sub.changes {
+		if change.Severity == Breaking {
+			c.add(path, HeaderChanged, Breaking, old.TypeString(), new.TypeString())
+			return
+		}
+	}
+}
+
+func (c *compatChecker) compareElem(path string, old, new *Schema) {
+	if old == nil || new == nil {
+		if old != new {
+			c.add(path, KindChanged, Breaking, "", "")
+		}
+		return
+	}
+	c.compare(path, *old, *new)
+}
+
+// compareFields compares the fields of two structs. Extensible structs
+// tolerate fields added or removed at their end.
+func (c *compatChecker) compareFields(path string, old, new []Schema, extensible bool) {
+	trailing := If(extensible, Compatible, Breaking)
+
+	if !allNamed(old) || !allNamed(new) {
+		c.comparePositional(path, old, new, trailing)
+		return
+	}
+
+	// A position whose old and new names appear nowhere else is a rename.
+	renames := make(map[string]string)
+	for i := 0; i < min(len(old), len(new)); i++ {
+		o, n := old[i].Name, new[i].Name
+		if o != n && fieldIndex(new, o) < 0 && fieldIndex(old, n) < 0 {
+			renames[o] = n
+		}
+	}
+
+	newName := func(name string) string {
+		if n, ok := renames[name]; ok {
+			return n
+		}
+		return name
+	}
+	oldName := func(newName string) string {
+		for o, n := range renames {
+			if n == newName {
+				return o
+			}
+		}
+		return newName
+	}
+
+	// Fields present in both versions, in old and in new wire order, and the
+	// position of the last of them on each side.
+	var kept, keptNew []string
+	lastOld, lastNew := -1, -1
+	for i, f := range old {
+		if fieldIndex(new, newName(f.Name)) >= 0 {
+			kept = append(kept, f.Name)
+			lastOld = i
+		}
+	}
+	for i, f := range new {
+		if fieldIndex(old, oldName(f.Name)) >= 0 {
+			keptNew = append(keptNew, oldName(f.Name))
+			lastNew = i
+		}
+	}
+
+	for i, f := range old {
+		if fieldIndex(new, newName(f.Name)) < 0 {
+			c.add(fieldPath(path, f, i), FieldRemoved, If(i > lastOld, trailing, Breaking), f.TypeString(), "")
+		}
+	}
+
+	for i, f := range new {
+		if fieldIndex(old, oldName(f.Name)) >= 0 {
+			continue
+		}
+		if i > lastNew {
+			c.add(fieldPath(path, f, i), FieldAdded, trailing, "", f.TypeString())
+			continue
+		}
+		// Any added field shifts or extends the layout old peers expect. An
+		// option inserted mid-struct is no exception: its presence flag is
+		// still read as part of whatever field used to be there.
+		c.add(fieldPath(path, f, i), FieldAdded, Breaking, "", f.TypeString()+" before "+new[i+1].Name)
+	}
+
+	for i, name := range kept {
+		if keptNew[i] != name {
+			c.add(path+"."+name, FieldReordered, Breaking,
+				"#"+strconv.Itoa(fieldIndex(old, name)),
+				"#"+strconv.Itoa(fieldIndex(new, newName(name))))
+		}
+	}
+
+	for _, name := range kept {
+		o := old[fieldIndex(old, name)]
+		renamed, ok := renames[name]
+		n := new[fieldIndex(new, If(ok, renamed, name))]
+		if ok {
+			c.add(fieldPath(path, n, 0), FieldRenamed, Compatible, o.Name, n.Name)
+		}
+		c.compare(fieldPath(path, n, 0), o, n)
+	}
+}
+
+// compareTagged matches the fields of tagged structs by number: adding,
+// removing and moving fields is fine, changing what a number stands for is not.
+func (c *compatChecker) compareTagged(path string, old, new Schema) {
+	if old.Tagged != new.Tagged {
+		c.add(path, KindChanged, Breaking, old.TypeString(), new.TypeString())
+		return
+	}
+
+	for i, o := range old.Fields {
+		j := tagIndex(new.Fields, o.Tag)
+		if j < 0 {
+			if k := fieldIndex(new.Fields, o.Name); o.Name != "" && k >= 0 {
+				// Same field, new number: old peers will skip it.
+				c.add(fieldPath(path, o, i), TagChanged, Breaking,
+					strconv.Itoa(o.Tag), strconv.Itoa(new.Fields[k].Tag))
+				continue
+			}
+			c.add(fieldPath(path, o, i), FieldRemoved, Compatible, o.TypeString(), "")
+			continue
+		}
+
+		n := new.Fields[j]
+		if o.Name != n.Name && o.Name != "" && n.Name != "" {
+			c.add(fieldPath(path, n, j), FieldRenamed, Compatible, o.Name, n.Name)
+		}
+		if i != j {
+			c.add(fieldPath(path, n, j), FieldReordered, Compatible, "#"+strconv.Itoa(i), "#"+strconv.Itoa(j))
+		}
+		c.compare(fieldPath(path, n, j), o, n)
+	}
+
+	for j, n := range new.Fields {
+		if tagIndex(old.Fields, n.Tag) >= 0 {
+			continue
+		}
+		if n.Name != "" && fieldIndex(old.Fields, n.Name) >= 0 {
+			continue // reported as a number change
+		}
+		c.add(fieldPath(path, n, j), FieldAdded, Compatible, "", n.TypeString())
+	}
+}
+
+func tagIndex(fields []Schema, tag int) int {
+	for i, f := range fields {
+		if f.Tag == tag {
+			return i
+		}
+	}
+	return -1
+}
+
+// comparePositional compares fields lacking names, which can only be matched
+// by their position.
+func (c *compatChecker) comparePositional(path string, old, new []Schema, trailing Severity) {
+	for i := 0; i < max(len(old), len(new)); i++ {
+		switch {
+		case i >= len(old):
+			c.add(fieldPath(path, new[i], i), FieldAdded, trailing, "", new[i].TypeString())
+		case i >= len(new):
+			c.add(fieldPath(path, old[i], i), FieldRemoved, trailing, old[i].TypeString(), "")
+		default:
+			c.compare(fieldPath(path, new[i], i), old[i], new[i])
+		}
+	}
+}
+
+func allNamed(fields []Schema) bool {
+	for _, f := range fields {
+		if f.Name == "" {
+			return false
+		}
+	}
+	return true
+}
+
+func fieldIndex(fields []Schema, name string) int {
+	for i, f := range fields {
+		if f.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+func fieldPath(parent string, f Schema, i int) string {
+	return joinPath(parent, schemaFieldName(f.Name, i))
+}
+
+func isInteger(tp string) bool {
+	return strings.HasPrefix(strings.TrimPrefix(tp, "u"), "int")
+}
+
+func signed(tp string) bool {
+	return strings.HasPrefix(tp, "int")
+}
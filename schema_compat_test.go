@@ -0,0 +1,183 @@
+package parco
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func compatField(name string, tp any) Schema {
+	return Describe(tp).named(name)
+}
+
+func compatModel(fields ...Schema) Schema {
+	return Schema{Kind: KindStruct, Type: "Model", Fields: fields}
+}
+
+func TestCheckCompatibility_SameBuilder(t *testing.T) {
+	report := CheckCompatibility(schemaOwnerBuilder().Schema(), schemaOwnerBuilder().Schema())
+
+	assert.Empty(t, report.Changes)
+	assert.True(t, report.Compatible())
+	assert.NoError(t, report.Err())
+}
+
+func TestCheckCompatibility(t *testing.T) {
+	tests := []struct {
+		name     string
+		old, new Schema
+		expected []SchemaChange
+	}{
+		{
+			name: "rename",
+			old:  compatModel(compatField("ID", UInt32LE()), compatField("Name", SmallVarchar())),
+			new:  compatModel(compatField("ID", UInt32LE()), compatField("Nick", SmallVarchar())),
+			expected: []SchemaChange{
+				{Path: "Model.Nick", Type: FieldRenamed, Severity: Compatible, Old: "Name", New: "Nick"},
+			},
+		},
+		{
+			name: "same width signedness",
+			old:  compatModel(compatField("ID", UInt32LE())),
+			new:  compatModel(compatField("ID", Int32LE())),
+			expected: []SchemaChange{
+				{Path: "Model.ID", Type: TypeChanged, Severity: Compatible, Old: "uint32 LE", New: "int32 LE"},
+			},
+		},
+		{
+			name: "same width integer to float",
+			old:  compatModel(compatField("ID", UInt32LE())),
+			new:  compatModel(compatField("ID", Float32(binary.LittleEndian))),
+			expected: []SchemaChange{
+				{Path: "Model.ID", Type: TypeChanged, Severity: Breaking, Old: "uint32 LE", New: "float32 LE"},
+			},
+		},
+		{
+			name: "same width bool to integer",
+			old:  compatModel(compatField("On", Bool())),
+			new:  compatModel(compatField("On", UInt8())),
+			expected: []SchemaChange{
+				{Path: "Model.On", Type: TypeChanged, Severity: Breaking, Old: "bool", New: "uint8"},
+			},
+		},
+		{
+			name: "width",
+			old:  compatModel(compatField("ID", UInt32LE())),
+			new:  compatModel(compatField("ID", UInt64LE())),
+			expected: []SchemaChange{
+				{Path: "Model.ID", Type: WidthChanged, Severity: Breaking, Old: "uint32 LE", New: "uint64 LE"},
+			},
+		},
+		{
+			name: "byte order",
+			old:  compatModel(compatField("ID", UInt16LE())),
+			new:  compatModel(compatField("ID", UInt16BE())),
+			expected: []SchemaChange{
+				{Path: "Model.ID", Type: ByteOrderChanged, Severity: Breaking, Old: "uint16 LE", New: "uint16 BE"},
+			},
+		},
+		{
+			name: "header",
+			old:  compatModel(compatField("Name", SmallVarchar())),
+			new:  compatModel(compatField("Name", String(UInt16HeaderLE()))),
+			expected: []SchemaChange{
+				{Path: "Model.Name", Type: HeaderChanged, Severity: Breaking, Old: "uint8", New: "uint16 LE"},
+			},
+		},
+		{
+			name: "reorder",
+			old:  compatModel(compatField("A", UInt8()), compatField("B", UInt8())),
+			new:  compatModel(compatField("B", UInt8()), compatField("A", UInt8())),
+			expected: []SchemaChange{
+				{Path: "Model.A", Type: FieldReordered, Severity: Breaking, Old: "#0", New: "#1"},
+				{Path: "Model.B", Type: FieldReordered, Severity: Breaking, Old: "#1", New: "#0"},
+			},
+		},
+		{
+			name: "optional added mid struct",
+			old:  compatModel(compatField("A", UInt8()), compatField("B", UInt8())),
+			new: compatModel(
+				compatField("A", UInt8()),
+				compatField("C", Option[uint8](UInt8())),
+				compatField("B", UInt8()),
+			),
+			expected: []SchemaChange{
+				{Path: "Model.C", Type: FieldAdded, Severity: Breaking, New: "option[uint8] before B"},
+			},
+		},
+		{
+			name: "field removed",
+			old:  compatModel(compatField("A", UInt8()), compatField("B", UInt8())),
+			new:  compatModel(compatField("A", UInt8())),
+			expected: []SchemaChange{
+				{Path: "Model.B", Type: FieldRemoved, Severity: Breaking, Old: "uint8"},
+			},
+		},
+		{
+			name: "varint width",
+			old:  compatModel(compatField("N", VarInt32())),
+			new:  compatModel(compatField("N", VarInt64())),
+			expected: []SchemaChange{
+				{Path: "Model.N", Type: WidthChanged, Severity: Breaking, Old: "varint32", New: "varint64"},
+			},
+		},
+		{
+			name: "kind",
+			old:  compatModel(compatField("N", UInt64LE())),
+			new:  compatModel(compatField("N", VarUInt64())),
+			expected: []SchemaChange{
+				{Path: "Model.N", Type: KindChanged, Severity: Breaking, Old: "uint64 LE", New: "varuint64"},
+			},
+		},
+		{
+			name: "nested element",
+			old:  compatModel(compatField("L", Slice[uint16](UInt8Header(), UInt16LE()))),
+			new:  compatModel(compatField("L", Slice[uint16](UInt8Header(), UInt16BE()))),
+			expected: []SchemaChange{
+				{Path: "Model.L[]", Type: ByteOrderChanged, Severity: Breaking, Old: "uint16 LE", New: "uint16 BE"},
+			},
+		},
+		{
+			name: "checksum added",
+			old:  Describe(parseItemBuilder()),
+			new:  Describe(parseItemBuilder().Checksum(CRC32C())),
+			expected: []SchemaChange{
+				{Path: "parseItem", Type: ChecksumChanged, Severity: Breaking, New: "crc32c"},
+			},
+		},
+		{
+			name: "unnamed fields are positional",
+			old:  compatModel(Describe(UInt8()), Describe(UInt16LE())),
+			new:  compatModel(Describe(UInt8())),
+			expected: []SchemaChange{
+				{Path: "Model.#1", Type: FieldRemoved, Severity: Breaking, Old: "uint16 LE"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, CheckCompatibility(tt.old, tt.new).Changes)
+		})
+	}
+}
+
+func TestCheckCompatibility_Builders(t *testing.T) {
+	v2 := schemaOwnerBuilder().
+		UInt16(binary.LittleEndian,
+			func(o *schemaOwner) uint16 { return 0 },
+			func(o *schemaOwner, v uint16) {},
+		).Named("Extra")
+
+	report := CheckCompatibility(schemaOwnerBuilder().Schema(), v2.Schema())
+
+	assert.False(t, report.Compatible())
+	require.Len(t, report.Breaking(), 1)
+	assert.Equal(t, "schemaOwner.Extra", report.Breaking()[0].Path)
+
+	err := report.Err()
+	require.ErrorIs(t, err, ErrIncompatibleSchema)
+	assert.Contains(t, err.Error(), "breaking: schemaOwner.Extra: field added (- -> uint16 LE)")
+}
@@ -0,0 +1,528 @@
+package parco
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"unsafe"
+)
+
+type (
+	// schemaEvent reports a span of the message read by a schemaDecoder.
+	schemaEvent struct {
+		start, end int
+		bytes      []byte
+		path       string
+		schema     Schema
+		// header is the length, presence flag or field key read, if any.
+		header string
+		value  any
+		err    error
+	}
+
+	// schemaDecoder parses a message driven by its Schema alone, without the
+	// model type. Values come out as:
+	//
+	//	fixed, varint  their Go type: uint8..uint64, int8..int64, uint, int,
+	//	               float32, float64, bool; custom fixed types as []byte
+	//	varchar        string or []byte
+	//	slice, array   []any
+	//	map            map[any]any
+	//	option         nil or the value
+	//	struct         map[string]any keyed by field name, or #index
+	//	time           time.Time
+	//	skip           nil
+	//
+	// Values are read by the parsers of the types themselves, so that the
+	// decoder agrees with them on what is valid.
+	schemaDecoder struct {
+		cursor *BufferCursor
+		// visit, if set, is told about every span read.
+		visit func(schemaEvent)
+		// origin is the offset of the message in the cursor, and budget
+		// tracks its DecodeLimits, nil if unlimited.
+		origin int
+		budget *decodeBudget
+	}
+)
+
+// anySize is the size of the values a schemaDecoder holds.
+const anySize = int(unsafe.Sizeof(any(nil)))
+
+var optionFlagType = Bool()
+
+func (d *schemaDecoder) emit(e schemaEvent) {
+	if d.visit != nil {
+		e.bytes = d.cursor.data[e.start:e.end]
+		d.visit(e)
+	}
+}
+
+// fail reports err, raised decoding s from start on.
+func (d *schemaDecoder) fail(start int, path string, s Schema, err error) error {
+	d.emit(schemaEvent{start: start, end: len(d.cursor.data), path: path, schema: s, err: err})
+	return &ParseError{Path: path, Index: -1, Offset: start, MessageStart: -1, StreamOffset: -1, Err: err}
+}
+
+// reader returns a reader of the message at the cursor, subject to its
+// limits. It is given back with putMessageReader.
+func (d *schemaDecoder) reader() *messageReader {
+	m := getMessageReader(d.cursor, d.cursor.cursor-d.origin)
+	m.budget = d.budget
+	return m
+}
+
+// decodeWith reads a value with parse, one of the parsers of the types
+// themselves, so that both read the wire alike.
+func decodeWith[T any](d *schemaDecoder, parse func(io.Reader) (T, error)) (T, error) {
+	m := d.reader()
+	defer putMessageReader(m)
+	return parse(m)
+}
+
+func (d *schemaDecoder) decode(s Schema, path string) (any, error) {
+	start := d.cursor.cursor
+
+	switch s.Kind {
+	case KindStruct:
+		return d.decodeStruct(s, path)
+	case KindSlice, KindArray:
+		return d.decodeList(s, path)
+	case KindChunked:
+		return d.decodeChunked(s, path)
+	case KindMap:
+		return d.decodeMap(s, path)
+	case KindOption:
+		return d.decodeOption(s, path)
+	}
+
+	value, header, err := d.leaf(s)
+	if err != nil {
+		return nil, d.fail(start, path, s, err)
+	}
+
+	d.emit(schemaEvent{start: start, end: d.cursor.cursor, path: path, schema: s, header: header, value: value})
+	return value, nil
+}
+
+// leaf reads a value without inner structure with the type s describes.
+func (d *schemaDecoder) leaf(s Schema) (value any, header string, err error) {
+	tp, err := dynamicTypes{}.typ(s)
+	if err != nil {
+		if s.Kind != KindFixed || !errors.Is(err, ErrUnknownType) {
+			return nil, "", err
+		}
+		// Custom fixed width types come out as their bytes.
+		var data []byte
+		if data, err = d.read(s.ByteLength); err != nil {
+			return nil, "", err
+		}
+		return append([]byte(nil), data...), "", nil
+	}
+
+	if value, err = decodeWith(d, tp.Parse); err != nil {
+		return nil, "", err
+	}
+
+	if s.Kind == KindVarchar {
+		switch v := value.(type) {
+		case string:
+			header = "len " + strconv.Itoa(len(v))
+		case []byte:
+			header = "len " + strconv.Itoa(len(v))
+		}
+	}
+	return value, header, nil
+}
+
+// read returns the next n bytes, for types the decoder does not know.
+func (d *schemaDecoder) read(n int) ([]byte, error) {
+	if d.budget != nil {
+		if err := d.budget.readable(d.cursor.cursor-d.origin, n); err != nil {
+			return nil, err
+		}
+	}
+	to := d.cursor.cursor + n
+	if n < 0 || to > len(d.cursor.data) {
+		return nil, ErrCannotRead
+	}
+	data := d.cursor.data[d.cursor.cursor:to]
+	d.cursor.cursor = to
+	return data, nil
+}
+
+// uvarint reads the key of a field of a tagged struct.
+func (d *schemaDecoder) uvarint() (uint64, error) {
+	return decodeWith(d, tagKeyType.Parse)
+}
+
+// flag reads the presence flag of an option.
+func (d *schemaDecoder) flag() (bool, error) {
+	return decodeWith(d, optionFlagType.Parse)
+}
+
+func (d *schemaDecoder) varint(s Schema) (any, error) {
+	tp, err := dynamicVarint(s)
+	if err != nil {
+		return nil, err
+	}
+	return decodeWith(d, tp.Parse)
+}
+
+// length reads the value of a length header.
+func (d *schemaDecoder) length(header *Schema) (int, error) {
+	if header == nil {
+		return 0, fmt.Errorf("missing header: %w", ErrUnknownType)
+	}
+	tp, err := intType(*header)
+	if err != nil {
+		return 0, err
+	}
+	size, err := decodeWith(d, tp.Parse)
+	if err == nil && size < 0 {
+		err = ErrOverflow
+	}
+	return size, err
+}
+
+// checkLength checks the element count of a collection against the limits
+// of the message, and charges the values it is about to hold.
+func (d *schemaDecoder) checkLength(size, fallback, elem int) error {
+	m := d.reader()
+	defer putMessageReader(m)
+	if err := checkLength(m, size, fallback); err != nil {
+		return err
+	}
+	return allocate(m, size*elem)
+}
+
+// header reads a length header, reporting it as a span of its own.
+func (d *schemaDecoder) header(s Schema, path string) (int, error) {
+	start := d.cursor.cursor
+	size, err := d.length(s.Header)
+	if err != nil {
+		return 0, d.fail(start, path, s, err)
+	}
+	d.emit(schemaEvent{start: start, end: d.cursor.cursor, path: path, schema: s, header: "len " + strconv.Itoa(size)})
+	return size, nil
+}
+
+func (d *schemaDecoder) decodeList(s Schema, path string) (any, error) {
+	size := s.Length
+	if s.Kind == KindSlice {
+		var err error
+		if size, err = d.header(s, path); err != nil {
+			return nil, err
+		}
+	}
+
+	if s.Elem == nil {
+		return nil, d.fail(d.cursor.cursor, path, s, ErrUnknownType)
+	}
+	if err := d.checkLength(size, MaxReasonableSliceLength, anySize); err != nil {
+		return nil, d.fail(d.cursor.cursor, path, s, err)
+	}
+
+	values := make([]any, 0, min(size, maxInitialCapacity))
+	for i := range size {
+		value, err := d.decode(*s.Elem, path+"["+strconv.Itoa(i)+"]")
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+	}
+	return values, nil
+}
+
+// decodeChunked reads chunks until the empty one, each count reported as a
+// span of its own.
+func (d *schemaDecoder) decodeChunked(s Schema, path string) (any, error) {
+	if s.Elem == nil {
+		return nil, d.fail(d.cursor.cursor, path, s, ErrUnknownType)
+	}
+
+	var values []any
+	for {
+		size, err := d.header(s, path)
+		if err != nil {
+			return nil, err
+		}
+		if size == 0 {
+			return If(values != nil, values, []any{}), nil
+		}
+		if err = d.checkLength(len(values)+size, MaxReasonableSliceLength, 0); err == nil {
+			err = d.checkLength(size, 0, anySize)
+		}
+		if err != nil {
+			return nil, d.fail(d.cursor.cursor, path, s, err)
+		}
+
+		for range size {
+			value, err := d.decode(*s.Elem, path+"["+strconv.Itoa(len(values))+"]")
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, value)
+		}
+	}
+}
+
+func (d *schemaDecoder) decodeMap(s Schema, path string) (any, error) {
+	size, err := d.header(s, path)
+	if err != nil {
+		return nil, err
+	}
+	if s.Key == nil || s.Elem == nil || !comparableKey(*s.Key) {
+		return nil, d.fail(d.cursor.cursor, path, s, ErrUnknownType)
+	}
+	if err = d.checkLength(size, MaxReasonableMapLength, 2*anySize); err != nil {
+		return nil, d.fail(d.cursor.cursor, path, s, err)
+	}
+
+	values := make(map[any]any, min(size, maxInitialCapacity))
+	for i := range size {
+		k, err := d.decode(*s.Key, path+"[#"+strconv.Itoa(i)+"]")
+		if err != nil {
+			return nil, err
+		}
+		if !isHashable(k) {
+			return nil, d.fail(d.cursor.cursor, path, s, ErrUnknownType)
+		}
+		v, err := d.decode(*s.Elem, fmt.Sprintf("%s[%v]", path, k))
+		if err != nil {
+			return nil, err
+		}
+		values[k] = v
+	}
+	return values, nil
+}
+
+func (d *schemaDecoder) decodeOption(s Schema, path string) (any, error) {
+	start := d.cursor.cursor
+	some, err := d.flag()
+	if err != nil {
+		return nil, d.fail(start, path, s, err)
+	}
+
+	d.emit(schemaEvent{start: start, end: d.cursor.cursor, path: path, schema: s, header: If(some, "some", "none")})
+	if !some || s.Elem == nil {
+		return nil, nil
+	}
+	return d.decode(*s.Elem, path)
+}
+
+func (d *schemaDecoder) decodeStruct(s Schema, path string) (any, error) {
+	if d.budget != nil {
+		defer d.budget.leave()
+		if err := d.budget.enter(); err != nil {
+			return nil, d.fail(d.cursor.cursor, path, s, err)
+		}
+	}
+
+	if s.Checksum == "" {
+		return d.decodeBody(s, path)
+	}
+
+	sum, err := checksumNamed(s.Checksum)
+	if err != nil {
+		return nil, d.fail(d.cursor.cursor, path, s, err)
+	}
+	start := d.cursor.cursor
+	value, err := d.decodeBody(s, path)
+	if err != nil {
+		return nil, err
+	}
+	return value, d.checksum(sum, start, s, path)
+}
+
+// decodeBody decodes a struct, less its checksum.
+func (d *schemaDecoder) decodeBody(s Schema, path string) (any, error) {
+	if s.Header == nil {
+		return d.decodeFields(s, path)
+	}
+
+	start := d.cursor.cursor
+	size, err := d.header(s, path)
+	if err != nil {
+		return nil, err
+	}
+	if size > MaxReasonableVarSize {
+		return nil, d.fail(d.cursor.cursor, path, s, ErrOverflow)
+	}
+
+	if s.Checksum != "" {
+		// Like parsers, verify extensible messages before their fields.
+		if end := d.cursor.cursor + size; end+checksumByteLength <= len(d.cursor.data) {
+			sum, _ := checksumNamed(s.Checksum)
+			if sum.Sum(d.cursor.data[start:end]) != binary.LittleEndian.Uint32(d.cursor.data[end:]) {
+				return nil, d.fail(end, path, s, ErrChecksumMismatch)
+			}
+		}
+	}
+
+	return d.bounded(size, s, path, func() (any, error) {
+		return d.decodeFields(s, path)
+	})
+}
+
+// checksum reads the checksum of a struct which started at start, and
+// verifies it.
+func (d *schemaDecoder) checksum(sum Checksum, start int, s Schema, path string) error {
+	end := d.cursor.cursor
+	data, err := d.read(checksumByteLength)
+	if err != nil {
+		return d.fail(end, path, s, err)
+	}
+	if sum.Sum(d.cursor.data[start:end]) != binary.LittleEndian.Uint32(data) {
+		return d.fail(end, path, s, ErrChecksumMismatch)
+	}
+	d.emit(schemaEvent{start: end, end: d.cursor.cursor, path: path, schema: s, header: "checksum " + s.Checksum})
+	return nil
+}
+
+// bounded runs decode over the next size bytes only, so that it cannot
+// overrun them, then skips whatever it left unread.
+func (d *schemaDecoder) bounded(size int, s Schema, path string, decode func() (any, error)) (any, error) {
+	outer := d.cursor
+	if d.budget != nil {
+		if err := d.budget.readable(outer.cursor-d.origin, size); err != nil {
+			return nil, d.fail(outer.cursor, path, s, err)
+		}
+	}
+	end := outer.cursor + size
+	if end > len(outer.data) {
+		return nil, d.fail(outer.cursor, path, s, ErrCannotRead)
+	}
+
+	body := NewBufferCursor(outer.data[:end], outer.cursor)
+	d.cursor = &body
+	res, err := decode()
+	d.cursor = outer
+	if err != nil {
+		return nil, err
+	}
+
+	if body.cursor < end {
+		d.emit(schemaEvent{start: body.cursor, end: end, path: path, schema: s, header: "unknown bytes"})
+	}
+	outer.cursor = end
+	return res, nil
+}
+
+func (d *schemaDecoder) decodeFields(s Schema, path string) (any, error) {
+	values := make(map[string]any, len(s.Fields))
+
+	if s.Tagged {
+		return values, d.decodeTagged(s, path, values)
+	}
+
+	for i, f := range s.Fields {
+		if s.Header != nil && d.cursor.cursor == len(d.cursor.data) {
+			// Extensible models may end before their trailing fields.
+			break
+		}
+		name := schemaFieldName(f.Name, i)
+		value, err := d.decode(f, joinPath(path, name))
+		if err != nil {
+			return nil, err
+		}
+		values[name] = value
+	}
+
+	return values, nil
+}
+
+func (d *schemaDecoder) decodeTagged(s Schema, path string, values map[string]any) error {
+	for {
+		start := d.cursor.cursor
+		key, err := d.uvarint()
+		if err != nil {
+			return d.fail(start, path, s, err)
+		}
+		if key == 0 {
+			d.emit(schemaEvent{start: start, end: d.cursor.cursor, path: path, schema: s, header: "end"})
+			return nil
+		}
+
+		num, wire := int(key>>wireTypeBits), WireType(key&(1<<wireTypeBits-1))
+		i := tagIndex(s.Fields, num)
+
+		fpath := joinPath(path, "#"+strconv.Itoa(num))
+		if i >= 0 {
+			fpath = joinPath(path, schemaFieldName(s.Fields[i].Name, i))
+		}
+
+		keyEvent := schemaEvent{start: start, path: fpath, schema: s,
+			header: "field " + strconv.Itoa(num) + " " + wire.String()}
+
+		if i < 0 {
+			if err := d.skipWire(wire); err != nil {
+				return d.fail(start, fpath, s, err)
+			}
+			keyEvent.end = d.cursor.cursor
+			keyEvent.header += " unknown"
+			d.emit(keyEvent)
+			continue
+		}
+
+		f := s.Fields[i]
+		if want := wireTypeOf(f); want != wire {
+			return d.fail(start, fpath, f, fmt.Errorf("%w: want %s, have %s", ErrWireType, want, wire))
+		}
+
+		if wire != WireBytes {
+			keyEvent.end = d.cursor.cursor
+			d.emit(keyEvent)
+			value, err := d.decode(f, fpath)
+			if err != nil {
+				return err
+			}
+			values[schemaFieldName(f.Name, i)] = value
+			continue
+		}
+
+		size, err := decodeWith(d, readWireLength)
+		if err != nil {
+			return d.fail(start, fpath, f, err)
+		}
+		keyEvent.header += " len " + strconv.Itoa(size)
+		keyEvent.end = d.cursor.cursor
+		d.emit(keyEvent)
+
+		value, err := d.bounded(size, f, fpath, func() (any, error) {
+			return d.decode(f, fpath)
+		})
+		if err != nil {
+			return err
+		}
+		values[schemaFieldName(f.Name, i)] = value
+	}
+}
+
+func (d *schemaDecoder) skipWire(wire WireType) error {
+	_, err := decodeWith(d, func(r io.Reader) (any, error) {
+		return nil, skipWire(r, wire)
+	})
+	return err
+}
+
+func isHashable(value any) bool {
+	_, ok := value.([]byte)
+	return !ok
+}
+
+func schemaFieldName(name string, i int) string {
+	if name == "" {
+		return "#" + strconv.Itoa(i)
+	}
+	return name
+}
+
+func joinPath(parent, name string) string {
+	if parent == "" {
+		return name
+	}
+	return parent + "." + name
+}
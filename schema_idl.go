@@ -0,0 +1,625 @@
+package parco
+
+import (
+	"encoding/binary"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+type (
+	// SchemaFile is a schema file: the models it declares, in declaration
+	// order, and the stream they make up, if declared.
+	SchemaFile struct {
+		Models []Schema
+		Stream *StreamSchema
+	}
+
+	// StreamSchema describes a multi-model stream, as written by a
+	// ModelMultiBuilder[int]: the type id header, the frame header of framed
+	// streams and the models keyed by type id.
+	StreamSchema struct {
+		// Header is the type id header, uint8 when nil.
+		Header *Schema        `json:"header,omitempty"`
+		Frame  *Schema        `json:"frame,omitempty"`
+		Models map[int]Schema `json:"models"`
+	}
+
+	idlToken struct {
+		text string
+		line int
+	}
+
+	// idlParser reads the text form of schema files.
+	idlParser struct {
+		tokens []idlToken
+		pos    int
+		// models are the declared models by name, whose references to other
+		// models are KindUnknown schemas typed with the model name until
+		// resolved.
+		models   map[string]*Schema
+		declared []string
+		// refs are the model references met, checked once all models are
+		// declared.
+		refs []idlToken
+	}
+)
+
+// ParseSchemaFile reads a schema file written in the parco schema language,
+// which declares models with the type syntax of Schema.TypeString:
+//
+//	// Comments start with // or #.
+//	message Item {
+//	  SKU   string<uint8>
+//	  Price uint16 LE
+//	}
+//
+//	message Order extensible<varuint> {
+//	  ID     uint32 BE
+//	  Items  slice<uint8>[Item]
+//	  Events chunked<varuint>[string<uint8>]
+//	  Labels map<uint8>[string<uint8>]uint16 LE
+//	  Note   option[Item]
+//	  skip(2)
+//	  At     time
+//	}
+//
+//	message Settings tagged checksum crc32c {
+//	  Name  string<uint8> = 1
+//	  Limit option[varint16] = 3
+//	}
+//
+//	stream uint8 framed<varuint> {
+//	  1 Item
+//	  2 Order
+//	}
+//
+// Fields are a name followed by a type and, in tagged models, optionally by
+// "= number". Multi-byte fixed types are little endian unless followed by BE.
+// Models are referenced by name, in any order, and must not contain
+// themselves. Padding is declared by an unnamed skip(n). Messages followed by
+// a checksum name it: crc32, crc32c or adler32. The optional stream
+// block declares the type id header, the frame header of framed streams and
+// the type id of each model.
+func ParseSchemaFile(src []byte) (SchemaFile, error) {
+	tokens, err := idlTokens(string(src))
+	if err != nil {
+		return SchemaFile{}, err
+	}
+
+	p := idlParser{tokens: tokens, models: make(map[string]*Schema)}
+	var (
+		file   SchemaFile
+		stream []idlToken
+	)
+
+	for !p.done() {
+		tok := p.next()
+		switch tok.text {
+		case "message":
+			if err = p.message(); err != nil {
+				return SchemaFile{}, err
+			}
+		case "stream":
+			if file.Stream != nil {
+				return SchemaFile{}, tok.errorf("stream declared twice")
+			}
+			if file.Stream, stream, err = p.stream(); err != nil {
+				return SchemaFile{}, err
+			}
+		default:
+			return SchemaFile{}, tok.errorf("want message or stream, have %q", tok.text)
+		}
+	}
+
+	for _, ref := range p.refs {
+		if _, ok := p.models[ref.text]; !ok {
+			return SchemaFile{}, ref.errorf("unknown type %q", ref.text)
+		}
+	}
+
+	resolved := make(map[string]Schema, len(p.models))
+	for _, name := range p.declared {
+		s, err := p.resolve(Schema{Kind: KindUnknown, Type: name}, resolved, nil)
+		if err != nil {
+			return SchemaFile{}, err
+		}
+		file.Models = append(file.Models, s)
+	}
+
+	if file.Stream != nil {
+		// Stream entries name their models.
+		for i, id := range sortedIDs(file.Stream.Models) {
+			s, ok := resolved[file.Stream.Models[id].Type]
+			if !ok {
+				return SchemaFile{}, stream[i].errorf("unknown model %q", file.Stream.Models[id].Type)
+			}
+			file.Stream.Models[id] = s
+		}
+	}
+
+	return file, nil
+}
+
+// Model returns the model with the given name.
+func (f SchemaFile) Model(name string) (Schema, bool) {
+	i := slices.IndexFunc(f.Models, func(s Schema) bool { return s.Type == name })
+	if i < 0 {
+		return Schema{}, false
+	}
+	return f.Models[i], true
+}
+
+// Builder returns a multi builder reading and writing the stream, whose
+// models are DynamicModel.
+func (s StreamSchema) Builder() (*ModelMultiBuilder[int], error) {
+	header := UInt8Header()
+	if s.Header != nil {
+		var err error
+		if header, err = intType(*s.Header); err != nil {
+			return nil, fmt.Errorf("header: %w", err)
+		}
+	}
+
+	b := MultiBuilder[int](header)
+
+	if s.Frame != nil {
+		frame, err := intType(*s.Frame)
+		if err != nil {
+			return nil, fmt.Errorf("frame: %w", err)
+		}
+		b.Framed(frame)
+	}
+
+	for id, model := range s.Models {
+		m, err := Dynamic(model)
+		if err != nil {
+			return nil, fmt.Errorf("model %d: %w", id, err)
+		}
+		if _, err = b.Register(id, m); err != nil {
+			return nil, err
+		}
+	}
+
+	return b, nil
+}
+
+func sortedIDs[V any](m map[int]V) []int {
+	ids := make([]int, 0, len(m))
+	for id := range m {
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+	return ids
+}
+
+// idlTokens splits src into words and punctuation, dropping comments.
+func idlTokens(src string) ([]idlToken, error) {
+	var tokens []idlToken
+
+	for n, line := range strings.Split(src, "\n") {
+		if i := strings.Index(line, "//"); i >= 0 {
+			line = line[:i]
+		}
+		if i := strings.IndexByte(line, '#'); i >= 0 {
+			line = line[:i]
+		}
+
+		for i := 0; i < len(line); {
+			c := rune(line[i])
+			switch {
+			case unicode.IsSpace(c):
+				i++
+			case strings.ContainsRune("{}[]<>()=", c):
+				tokens = append(tokens, idlToken{text: line[i : i+1], line: n + 1})
+				i++
+			case isIdentRune(c):
+				j := i
+				for j < len(line) && isIdentRune(rune(line[j])) {
+					j++
+				}
+				tokens = append(tokens, idlToken{text: line[i:j], line: n + 1})
+				i = j
+			default:
+				return nil, idlToken{line: n + 1}.errorf("unexpected %q", c)
+			}
+		}
+	}
+
+	return tokens, nil
+}
+
+func isIdentRune(c rune) bool {
+	return c == '_' || c == '+' || c < unicode.MaxASCII && (unicode.IsLetter(c) || unicode.IsDigit(c))
+}
+
+func (t idlToken) errorf(format string, args ...any) error {
+	return fmt.Errorf("%w: line %d: %s", ErrInvalidSchema, t.line, fmt.Sprintf(format, args...))
+}
+
+func (p *idlParser) done() bool {
+	return p.pos >= len(p.tokens)
+}
+
+// peek returns the next token, with an empty text at the end of the file.
+func (p *idlParser) peek() idlToken {
+	if p.done() {
+		line := 0
+		if n := len(p.tokens); n > 0 {
+			line = p.tokens[n-1].line
+		}
+		return idlToken{line: line}
+	}
+	return p.tokens[p.pos]
+}
+
+func (p *idlParser) next() idlToken {
+	tok := p.peek()
+	if !p.done() {
+		p.pos++
+	}
+	return tok
+}
+
+// accept consumes the next token if it is text.
+func (p *idlParser) accept(text string) bool {
+	if p.peek().text != text || p.done() {
+		return false
+	}
+	p.pos++
+	return true
+}
+
+func (p *idlParser) expect(text string) error {
+	if tok := p.next(); tok.text != text {
+		return tok.errorf("want %q, have %q", text, tok.text)
+	}
+	return nil
+}
+
+func (p *idlParser) ident() (idlToken, error) {
+	tok := p.next()
+	if tok.text == "" || !isIdentRune(rune(tok.text[0])) {
+		return tok, tok.errorf("want a name, have %q", tok.text)
+	}
+	return tok, nil
+}
+
+func (p *idlParser) number() (int, error) {
+	tok := p.next()
+	n, err := strconv.Atoi(tok.text)
+	if err != nil || n < 0 {
+		return 0, tok.errorf("want a number, have %q", tok.text)
+	}
+	return n, nil
+}
+
+// message reads a model declaration, after the message keyword.
+func (p *idlParser) message() error {
+	name, err := p.ident()
+	if err != nil {
+		return err
+	}
+	if _, ok := p.models[name.text]; ok {
+		return name.errorf("message %s declared twice", name.text)
+	}
+
+	s := &Schema{Kind: KindStruct, Type: name.text}
+	last := 0
+	for !p.accept("{") {
+		switch tok := p.next(); tok.text {
+		case "extensible":
+			if s.Header, err = p.header(); err != nil {
+				return err
+			}
+		case "tagged":
+			s.Tagged = true
+		case "checksum":
+			sum, err := p.ident()
+			if err != nil {
+				return err
+			}
+			if _, err = checksumNamed(sum.text); err != nil {
+				return sum.errorf("unknown checksum %q", sum.text)
+			}
+			s.Checksum = sum.text
+		default:
+			return tok.errorf("want extensible, tagged, checksum or {, have %q", tok.text)
+		}
+	}
+
+	for !p.accept("}") {
+		if p.done() {
+			return p.peek().errorf("message %s is not closed", name.text)
+		}
+
+		start := p.peek()
+		var field Schema
+		if start.text == "skip" && p.pos+1 < len(p.tokens) && p.tokens[p.pos+1].text == "(" {
+			field, err = p.typ()
+		} else {
+			var fieldName idlToken
+			if fieldName, err = p.ident(); err != nil {
+				return err
+			}
+			if fieldName.text == "LE" || fieldName.text == "BE" {
+				return fieldName.errorf("%s follows a multi-byte type", fieldName.text)
+			}
+			if _, ok := s.Field(fieldName.text); ok {
+				return fieldName.errorf("field %s declared twice", fieldName.text)
+			}
+			if field, err = p.typ(); err != nil {
+				return err
+			}
+			field.Name = fieldName.text
+		}
+		if err != nil {
+			return err
+		}
+
+		tok := start
+		if p.accept("=") {
+			if !s.Tagged {
+				return tok.errorf("field numbers are only allowed in tagged messages")
+			}
+			tok = p.peek()
+			if field.Tag, err = p.number(); err != nil {
+				return err
+			}
+		}
+		if s.Tagged {
+			// Fields are numbered as the builders number them.
+			if field.Tag == 0 {
+				field.Tag = last + 1
+			}
+			if field.Tag < 1 || field.Tag > maxTagNumber {
+				return tok.errorf("field number out of range: %d", field.Tag)
+			}
+			if slices.ContainsFunc(s.Fields, func(f Schema) bool { return f.Tag == field.Tag }) {
+				return tok.errorf("field number %d is used already", field.Tag)
+			}
+			last = max(last, field.Tag)
+		}
+
+		s.Fields = append(s.Fields, field)
+	}
+
+	p.models[name.text] = s
+	p.declared = append(p.declared, name.text)
+	return nil
+}
+
+// stream reads a stream declaration, after the stream keyword. Its models
+// are KindUnknown schemas typed with their names, whose tokens are returned
+// in type id order.
+func (p *idlParser) stream() (*StreamSchema, []idlToken, error) {
+	header, err := p.intType()
+	if err != nil {
+		return nil, nil, err
+	}
+	s := &StreamSchema{Header: header, Models: make(map[int]Schema)}
+
+	if p.accept("framed") {
+		if s.Frame, err = p.header(); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	if err = p.expect("{"); err != nil {
+		return nil, nil, err
+	}
+
+	names := make(map[int]idlToken)
+	for !p.accept("}") {
+		tok := p.peek()
+		id, err := p.number()
+		if err != nil {
+			return nil, nil, err
+		}
+		if _, ok := s.Models[id]; ok {
+			return nil, nil, tok.errorf("type id %d declared twice", id)
+		}
+		name, err := p.ident()
+		if err != nil {
+			return nil, nil, err
+		}
+		s.Models[id] = Schema{Kind: KindUnknown, Type: name.text}
+		names[id] = name
+	}
+
+	tokens := make([]idlToken, 0, len(names))
+	for _, id := range sortedIDs(names) {
+		tokens = append(tokens, names[id])
+	}
+	return s, tokens, nil
+}
+
+// header reads a length header in angle brackets.
+func (p *idlParser) header() (*Schema, error) {
+	if err := p.expect("<"); err != nil {
+		return nil, err
+	}
+	s, err := p.intType()
+	if err != nil {
+		return nil, err
+	}
+	return s, p.expect(">")
+}
+
+// intType reads an integer type usable as a header.
+func (p *idlParser) intType() (*Schema, error) {
+	tok := p.peek()
+	s, err := p.typ()
+	if err != nil {
+		return nil, err
+	}
+	if _, err = intType(s); err != nil {
+		return nil, tok.errorf("%s is not a header type", s.TypeString())
+	}
+	return &s, nil
+}
+
+// typ reads a type.
+func (p *idlParser) typ() (Schema, error) {
+	tok, err := p.ident()
+	if err != nil {
+		return Schema{}, err
+	}
+
+	switch name := tok.text; {
+	case fixedByteLength(name) > 0 && name != "time":
+		s := Schema{Kind: KindFixed, Type: name, ByteLength: fixedByteLength(name)}
+		if s.ByteLength > 1 {
+			s.Order = p.order()
+		}
+		return s, nil
+	case strings.HasPrefix(name, "var") && slices.Contains(varintTypes, name[3:]):
+		return Schema{Kind: KindVarint, Type: name[3:]}, nil
+	case name == "string" || name == "bytes":
+		header, err := p.header()
+		return Schema{Kind: KindVarchar, Type: name, Header: header}, err
+	case name == "time" || name == "time+location":
+		withLocation := name != "time"
+		return Schema{
+			Kind:       KindTime,
+			Type:       "time",
+			ByteLength: If(withLocation, 0, 8),
+			Order:      binary.LittleEndian,
+			Location:   withLocation,
+		}, nil
+	case name == "slice" || name == "chunked":
+		header, err := p.header()
+		if err != nil {
+			return Schema{}, err
+		}
+		elem, err := p.elem()
+		return Schema{Kind: If(name == "slice", KindSlice, KindChunked), Header: header, Elem: elem}, err
+	case name == "array":
+		length, err := p.count()
+		if err != nil {
+			return Schema{}, err
+		}
+		elem, err := p.elem()
+		return Schema{Kind: KindArray, Length: length, Elem: elem}, err
+	case name == "map":
+		header, err := p.header()
+		if err != nil {
+			return Schema{}, err
+		}
+		key, err := p.elem()
+		if err != nil {
+			return Schema{}, err
+		}
+		value, err := p.typ()
+		return Schema{Kind: KindMap, Header: header, Key: key, Elem: &value}, err
+	case name == "option":
+		elem, err := p.elem()
+		return Schema{Kind: KindOption, Header: describePtr(Bool()), Elem: elem}, err
+	case name == "skip":
+		pad, err := p.count()
+		return Schema{Kind: KindSkip, ByteLength: pad}, err
+	case name == "tagged" || name == "struct":
+		// The layout of structs is that of the message they reference.
+		if name == "tagged" {
+			if err = p.expect("struct"); err != nil {
+				return Schema{}, err
+			}
+		}
+		if p.peek().text == "<" {
+			if _, err = p.header(); err != nil {
+				return Schema{}, err
+			}
+		}
+		if tok, err = p.ident(); err != nil {
+			return Schema{}, err
+		}
+	case name == "LE" || name == "BE":
+		return Schema{}, tok.errorf("%s follows a multi-byte type", name)
+	}
+
+	p.refs = append(p.refs, tok)
+	return Schema{Kind: KindUnknown, Type: tok.text}, nil
+}
+
+var varintTypes = []string{"uint8", "int8", "uint16", "int16", "uint32", "int32", "uint64", "int64", "uint", "int"}
+
+// order reads an optional byte order, little endian by default.
+func (p *idlParser) order() binary.ByteOrder {
+	if p.accept("BE") {
+		return binary.BigEndian
+	}
+	p.accept("LE")
+	return binary.LittleEndian
+}
+
+// elem reads a type in square brackets.
+func (p *idlParser) elem() (*Schema, error) {
+	if err := p.expect("["); err != nil {
+		return nil, err
+	}
+	s, err := p.typ()
+	if err != nil {
+		return nil, err
+	}
+	return &s, p.expect("]")
+}
+
+// count reads a number in parentheses.
+func (p *idlParser) count() (int, error) {
+	if err := p.expect("("); err != nil {
+		return 0, err
+	}
+	n, err := p.number()
+	if err != nil {
+		return 0, err
+	}
+	return n, p.expect(")")
+}
+
+// resolve replaces the model references within s by the models, resolving
+// the models met along the way into resolved. stack lists the models being
+// resolved, which s must not reference. References are known to be declared.
+func (p *idlParser) resolve(s Schema, resolved map[string]Schema, stack []string) (Schema, error) {
+	if s.Kind == KindUnknown {
+		model, ok := resolved[s.Type]
+		if !ok {
+			if slices.Contains(stack, s.Type) {
+				return Schema{}, fmt.Errorf("%w: message %s contains itself: %s", ErrInvalidSchema, s.Type, strings.Join(append(stack, s.Type), " > "))
+			}
+			var err error
+			if model, err = p.resolve(*p.models[s.Type], resolved, append(stack, s.Type)); err != nil {
+				return Schema{}, err
+			}
+			resolved[s.Type] = model
+		}
+		model.Name, model.Tag = s.Name, s.Tag
+		return model, nil
+	}
+
+	for _, child := range []**Schema{&s.Elem, &s.Key} {
+		if *child == nil {
+			continue
+		}
+		r, err := p.resolve(**child, resolved, stack)
+		if err != nil {
+			return Schema{}, err
+		}
+		*child = &r
+	}
+
+	if s.Fields != nil {
+		fields := make([]Schema, len(s.Fields))
+		for i, f := range s.Fields {
+			r, err := p.resolve(f, resolved, stack)
+			if err != nil {
+				return Schema{}, err
+			}
+			fields[i] = r
+		}
+		s.Fields = fields
+	}
+
+	return s, nil
+}
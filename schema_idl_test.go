@@ -0,0 +1,175 @@
+package parco
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const ordersIDL = `
+# Orders, as written by parseOrderBuilder.
+message parseOrder {
+  ID     uint16 LE
+  Items  slice<uint8>[parseItem]
+  Labels map<uint8>[string<uint8>]uint16
+  Note   option[struct parseItem]
+}
+
+message parseItem {
+  SKU   string<uint8> // Stock keeping unit.
+  Price uint32 LE
+}
+
+message settings tagged {
+  Name    string<uint8>
+  Retries uint8
+  Timeout uint32
+  Debug   bool
+  Ratio   float64
+  Hosts   slice<uint8>[string<uint8>]
+  Limit   option[int16 LE]
+  Seq     varint64 = 20
+}
+
+stream uint16 BE framed<varuint> {
+  1 parseItem
+  2 parseOrder
+}
+`
+
+func TestParseSchemaFile(t *testing.T) {
+	file, err := ParseSchemaFile([]byte(ordersIDL))
+	require.NoError(t, err)
+
+	require.Len(t, file.Models, 3)
+	assert.Equal(t, []string{"parseOrder", "parseItem", "settings"},
+		[]string{file.Models[0].Type, file.Models[1].Type, file.Models[2].Type})
+
+	// The same layout as the builders describe.
+	for name, d := range map[string]Describer{
+		"parseOrder": parseOrderBuilder(),
+		"parseItem":  parseItemBuilder(),
+		"settings":   settingsBuilder(),
+	} {
+		model, ok := file.Model(name)
+		require.True(t, ok, name)
+		assert.Equal(t, Describe(d).String(), model.String(), name)
+	}
+
+	note, _ := file.Models[0].Field("Note")
+	assert.Equal(t, "Note", note.Name)
+	assert.Equal(t, "parseItem", note.Elem.Type)
+
+	require.NotNil(t, file.Stream)
+	assert.Equal(t, "uint16 BE", file.Stream.Header.TypeString())
+	assert.Equal(t, "varuint", file.Stream.Frame.TypeString())
+	assert.Equal(t, "parseItem", file.Stream.Models[1].Type)
+	assert.Equal(t, "parseOrder", file.Stream.Models[2].Type)
+
+	_, ok := file.Model("missing")
+	assert.False(t, ok)
+}
+
+func TestParseSchemaFile_Types(t *testing.T) {
+	file, err := ParseSchemaFile([]byte(`
+message event extensible<uint16 BE> checksum crc32 {
+  At      time
+  Local   time+location
+  Payload bytes<varuint>
+  Delta   varint
+  Ratio   float32 BE
+  Flags   array(2)[bool]
+  skip(2)
+  Counts  map<uint8>[uint16]varuint32
+  Size    uint
+}`))
+	require.NoError(t, err)
+
+	model := file.Models[0]
+	assert.Equal(t, "struct<uint16 BE> event checksum crc32", model.TypeString())
+	assert.Nil(t, file.Stream)
+
+	types := make([]string, len(model.Fields))
+	for i, f := range model.Fields {
+		types[i] = f.TypeString()
+	}
+	assert.Equal(t, []string{
+		"time", "time+location", "bytes<varuint>", "varint", "float32 BE",
+		"array(2)[bool]", "skip(2)", "map<uint8>[uint16 LE]varuint32", "uint LE",
+	}, types)
+	assert.Equal(t, Describe(TimeUTC()), model.Fields[0].named(""))
+	assert.Equal(t, binary.BigEndian, model.Fields[4].Order)
+	assert.Equal(t, "", model.Fields[6].Name)
+}
+
+func TestParseSchemaFile_Errors(t *testing.T) {
+	for name, tc := range map[string]struct {
+		src  string
+		want string
+	}{
+		"unknown type":     {"message a {\n x foo\n}", "line 2: unknown type \"foo\""},
+		"unknown model":    {"message a {}\nstream uint8 {\n 1 b\n}", "line 3: unknown model \"b\""},
+		"recursive":        {"message a { b option[b] }\nmessage b { a a }", "message a contains itself: a > b > a"},
+		"duplicate model":  {"message a {}\nmessage a {}", "line 2: message a declared twice"},
+		"duplicate field":  {"message a {\n x bool\n x bool\n}", "line 3: field x declared twice"},
+		"untagged number":  {"message a { x bool = 1 }", "field numbers are only allowed in tagged messages"},
+		"bad header":       {"message a { x string<float32> }", "float32 LE is not a header type"},
+		"missing bracket":  {"message a { x slice<uint8> bool }", "want \"[\", have \"bool\""},
+		"not closed":       {"message a {\n x bool", "line 2: message a is not closed"},
+		"duplicate id":     {"message a {}\nstream uint8 { 1 a 1 a }", "type id 1 declared twice"},
+		"unexpected":       {"message a { x bool; }", "unexpected ';'"},
+		"top level":        {"model a {}", "want message or stream, have \"model\""},
+		"misplaced order":  {"message a { x bool LE }", "LE follows a multi-byte type"},
+		"duplicate number": {"message a tagged {\n x bool = 2\n y bool\n z bool = 3\n}", "line 4: field number 3 is used already"},
+		"duplicate stream": {"message a {}\nstream uint8 {}\nstream uint8 {}", "line 3: stream declared twice"},
+		"unknown checksum": {"message a checksum md5 {}", "line 1: unknown checksum \"md5\""},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := ParseSchemaFile([]byte(tc.src))
+			assert.ErrorIs(t, err, ErrInvalidSchema)
+			assert.ErrorContains(t, err, tc.want)
+		})
+	}
+}
+
+func TestStreamSchema_Builder(t *testing.T) {
+	file, err := ParseSchemaFile([]byte(ordersIDL))
+	require.NoError(t, err)
+
+	b, err := file.Stream.Builder()
+	require.NoError(t, err)
+
+	// A stream written by the Go models reads back with the dynamic ones.
+	typed := MultiBuilder[int](UInt16HeaderBE()).Framed(VarUIntHeader())
+	typed.MustRegister(1, parseItemBuilder())
+	typed.MustRegister(2, parseOrderBuilder())
+
+	buf := bytes.NewBuffer(nil)
+	require.NoError(t, typed.CompileAny(2, parseOrder{ID: 7, Items: []parseItem{{"a", 1}}}, buf))
+	require.NoError(t, typed.CompileAny(1, parseItem{"b", 2}, buf))
+	stream := buf.Bytes()
+
+	r := bytes.NewReader(stream)
+	id, order, err := b.Parse(r)
+	require.NoError(t, err)
+	assert.Equal(t, 2, id)
+	assert.Equal(t, map[string]any{
+		"ID":     uint16(7),
+		"Items":  []any{map[string]any{"SKU": "a", "Price": uint32(1)}},
+		"Labels": map[any]any{},
+		"Note":   nil,
+	}, order)
+
+	id, item, err := b.Parse(r)
+	require.NoError(t, err)
+	assert.Equal(t, 1, id)
+	assert.Equal(t, map[string]any{"SKU": "b", "Price": uint32(2)}, item)
+
+	out := bytes.NewBuffer(nil)
+	require.NoError(t, b.CompileAny(2, order, out))
+	require.NoError(t, b.CompileAny(1, item, out))
+	assert.Equal(t, stream, out.Bytes())
+}
@@ -0,0 +1,144 @@
+package parco
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// schemaJSON is the JSON form of a Schema, as found in schema files:
+//
+//	{"kind": "struct", "type": "Item", "fields": [
+//	  {"name": "SKU", "kind": "varchar", "type": "string",
+//	   "header": {"kind": "fixed", "type": "uint8"}},
+//	  {"name": "Price", "kind": "fixed", "type": "uint32", "order": "le"}
+//	]}
+type schemaJSON struct {
+	Name       string   `json:"name,omitempty"`
+	Kind       Kind     `json:"kind"`
+	Type       string   `json:"type,omitempty"`
+	ByteLength int      `json:"byteLength,omitempty"`
+	Order      string   `json:"order,omitempty"`
+	Header     *Schema  `json:"header,omitempty"`
+	Elem       *Schema  `json:"elem,omitempty"`
+	Key        *Schema  `json:"key,omitempty"`
+	Length     int      `json:"length,omitempty"`
+	Location   bool     `json:"location,omitempty"`
+	Fields     []Schema `json:"fields,omitempty"`
+	Tagged     bool     `json:"tagged,omitempty"`
+	Tag        int      `json:"tag,omitempty"`
+	Checksum   string   `json:"checksum,omitempty"`
+}
+
+// MarshalJSON writes s in the JSON form UnmarshalJSON reads. Byte orders are
+// written as "le" or "be".
+func (s Schema) MarshalJSON() ([]byte, error) {
+	order := ""
+	switch s.Order {
+	case binary.LittleEndian:
+		order = "le"
+	case binary.BigEndian:
+		order = "be"
+	}
+
+	return json.Marshal(schemaJSON{
+		Name:       s.Name,
+		Kind:       s.Kind,
+		Type:       s.Type,
+		ByteLength: s.ByteLength,
+		Order:      order,
+		Header:     s.Header,
+		Elem:       s.Elem,
+		Key:        s.Key,
+		Length:     s.Length,
+		Location:   s.Location,
+		Fields:     s.Fields,
+		Tagged:     s.Tagged,
+		Tag:        s.Tag,
+		Checksum:   s.Checksum,
+	})
+}
+
+// UnmarshalJSON reads a schema written by MarshalJSON or by hand. The byte
+// length of fixed width built-in types may be left out, and multi-byte
+// values default to little endian.
+func (s *Schema) UnmarshalJSON(data []byte) error {
+	var v schemaJSON
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+
+	*s = Schema{
+		Name:       v.Name,
+		Kind:       v.Kind,
+		Type:       v.Type,
+		ByteLength: v.ByteLength,
+		Header:     v.Header,
+		Elem:       v.Elem,
+		Key:        v.Key,
+		Length:     v.Length,
+		Location:   v.Location,
+		Fields:     v.Fields,
+		Tagged:     v.Tagged,
+		Tag:        v.Tag,
+		Checksum:   v.Checksum,
+	}
+
+	switch strings.ToLower(v.Order) {
+	case "le":
+		s.Order = binary.LittleEndian
+	case "be":
+		s.Order = binary.BigEndian
+	case "":
+	default:
+		return fmt.Errorf("schema %s: byte order %q: %w", s.Name, v.Order, ErrUnknownType)
+	}
+
+	if s.Kind == KindFixed && s.ByteLength == 0 {
+		s.ByteLength = fixedByteLength(s.Type)
+		if s.ByteLength == 0 {
+			return fmt.Errorf("schema %s: width of %q: %w", s.Name, s.Type, ErrInvalidLength)
+		}
+	}
+
+	if s.Order == nil && (s.Kind == KindFixed && s.ByteLength > 1 || s.Kind == KindTime) {
+		s.Order = binary.LittleEndian
+	}
+
+	return nil
+}
+
+// MarshalText writes the name of k.
+func (k Kind) MarshalText() ([]byte, error) {
+	return []byte(k.String()), nil
+}
+
+// UnmarshalText reads the name of a kind.
+func (k *Kind) UnmarshalText(text []byte) error {
+	i := slices.Index(kindNames[:], string(text))
+	if i < 0 {
+		return fmt.Errorf("kind %q: %w", text, ErrUnknownType)
+	}
+	*k = Kind(i)
+	return nil
+}
+
+// fixedByteLength is the width of the fixed width built-in type named tp,
+// 0 if unknown.
+func fixedByteLength(tp string) int {
+	switch tp {
+	case "bool", "uint8", "int8":
+		return 1
+	case "uint16", "int16":
+		return 2
+	case "uint32", "int32", "float32":
+		return 4
+	case "uint64", "int64", "float64", "time":
+		return 8
+	case "uint", "int":
+		return uintBitSize
+	}
+	return 0
+}
@@ -0,0 +1,63 @@
+package parco
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSchema_JSONRoundTrip(t *testing.T) {
+	for name, s := range map[string]Schema{
+		"plain":      parseOrderBuilder().Schema(),
+		"tagged":     settingsBuilder().Schema(),
+		"extensible": deviceV2Builder(ObjectFactory[deviceV2]()).Schema(),
+		"checksum":   parseItemBuilder().Checksum(Adler32()).Schema(),
+	} {
+		t.Run(name, func(t *testing.T) {
+			data, err := json.Marshal(s)
+			require.NoError(t, err)
+
+			var back Schema
+			require.NoError(t, json.Unmarshal(data, &back))
+			assert.Equal(t, s, back)
+		})
+	}
+}
+
+func TestSchema_UnmarshalJSON(t *testing.T) {
+	var s Schema
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"kind": "struct", "type": "Item", "fields": [
+			{"name": "SKU", "kind": "varchar", "type": "string", "header": {"kind": "fixed", "type": "uint8"}},
+			{"name": "Price", "kind": "fixed", "type": "uint32"},
+			{"name": "Stock", "kind": "fixed", "type": "int16", "order": "BE"}
+		]
+	}`), &s))
+
+	price, _ := s.Field("Price")
+	assert.Equal(t, 4, price.ByteLength)
+	assert.Equal(t, binary.LittleEndian, price.Order)
+
+	stock, _ := s.Field("Stock")
+	assert.Equal(t, binary.BigEndian, stock.Order)
+
+	// The hand written schema decodes what the builder compiles.
+	buf := bytes.NewBuffer(nil)
+	require.NoError(t, parseItemBuilder().Compile(parseItem{"ab", 7}, buf))
+	buf.Write([]byte{0xff, 0xfe})
+
+	doc, err := ToJSON(s, buf.Bytes())
+	require.NoError(t, err)
+	assert.Equal(t, `{"SKU":"ab","Price":7,"Stock":-2}`, string(doc))
+}
+
+func TestSchema_UnmarshalJSON_Errors(t *testing.T) {
+	var s Schema
+	assert.ErrorIs(t, json.Unmarshal([]byte(`{"kind": "blob"}`), &s), ErrUnknownType)
+	assert.ErrorIs(t, json.Unmarshal([]byte(`{"kind": "fixed", "type": "uint16", "order": "middle"}`), &s), ErrUnknownType)
+	assert.ErrorIs(t, json.Unmarshal([]byte(`{"kind": "fixed", "type": "uint24"}`), &s), ErrInvalidLength)
+}
@@ -0,0 +1,178 @@
+package parco
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type schemaPet struct {
+	Name string
+	Age  uint8
+}
+
+type schemaOwner struct {
+	ID     uint32
+	Nick   string
+	Pets   []schemaPet
+	Scores map[string]int64
+	Lucky  *int16
+	Best   schemaPet
+}
+
+func schemaPetBuilder() ModelBuilder[schemaPet] {
+	return Builder[schemaPet](ObjectFactory[schemaPet]()).
+		SmallVarchar(
+			func(p *schemaPet) string { return p.Name },
+			func(p *schemaPet, v string) { p.Name = v },
+		).Named("Name").
+		UInt8(
+			func(p *schemaPet) uint8 { return p.Age },
+			func(p *schemaPet, v uint8) { p.Age = v },
+		).Named("Age")
+}
+
+func schemaOwnerBuilder() ModelBuilder[schemaOwner] {
+	return Builder[schemaOwner](ObjectFactory[schemaOwner]()).
+		UInt32(binary.BigEndian,
+			func(o *schemaOwner) uint32 { return o.ID },
+			func(o *schemaOwner, v uint32) { o.ID = v },
+		).Named("ID").
+		Varchar(
+			func(o *schemaOwner) string { return o.Nick },
+			func(o *schemaOwner, v string) { o.Nick = v },
+		).Named("Nick").
+		Slice(SliceField[schemaOwner, schemaPet](
+			UInt8Header(),
+			Struct[schemaPet](schemaPetBuilder()),
+			func(o *schemaOwner, v SliceView[schemaPet]) { o.Pets = v },
+			func(o *schemaOwner) SliceView[schemaPet] { return o.Pets },
+		)).Named("Pets").
+		Map(MapField[schemaOwner, string, int64](
+			VarUIntHeader(),
+			SmallVarchar(),
+			VarInt64(),
+			func(o *schemaOwner, v map[string]int64) { o.Scores = v },
+			func(o *schemaOwner) map[string]int64 { return o.Scores },
+		)).Named("Scores").
+		Option(OptionField[schemaOwner, int16](
+			Int16LE(),
+			func(o *schemaOwner, v *int16) { o.Lucky = v },
+			func(o *schemaOwner) *int16 { return o.Lucky },
+		)).Named("Lucky").
+		Struct(StructField[schemaOwner, schemaPet](
+			func(o *schemaOwner) schemaPet { return o.Best },
+			func(o *schemaOwner, v schemaPet) { o.Best = v },
+			schemaPetBuilder(),
+		)).Named("Best")
+}
+
+func TestModelBuilder_Schema(t *testing.T) {
+	s := schemaOwnerBuilder().Schema()
+
+	assert.Equal(t, KindStruct, s.Kind)
+	assert.Equal(t, "schemaOwner", s.Type)
+	require.Len(t, s.Fields, 6)
+
+	id := s.Fields[0]
+	assert.Equal(t, "ID", id.Name)
+	assert.Equal(t, KindFixed, id.Kind)
+	assert.Equal(t, "uint32", id.Type)
+	assert.Equal(t, 4, id.ByteLength)
+	assert.Equal(t, binary.BigEndian, id.Order)
+
+	nick := s.Fields[1]
+	assert.Equal(t, KindVarchar, nick.Kind)
+	assert.Equal(t, "string", nick.Type)
+	require.NotNil(t, nick.Header)
+	assert.Equal(t, "uint16", nick.Header.Type)
+	assert.Equal(t, binary.LittleEndian, nick.Header.Order)
+
+	pets, ok := s.Field("Pets")
+	require.True(t, ok)
+	assert.Equal(t, KindSlice, pets.Kind)
+	assert.Equal(t, "uint8", pets.Header.Type)
+	assert.Equal(t, KindStruct, pets.Elem.Kind)
+	assert.Equal(t, "schemaPet", pets.Elem.Type)
+	require.Len(t, pets.Elem.Fields, 2)
+	assert.Equal(t, "Name", pets.Elem.Fields[0].Name)
+	assert.Equal(t, "Age", pets.Elem.Fields[1].Name)
+
+	scores := s.Fields[3]
+	assert.Equal(t, KindMap, scores.Kind)
+	assert.Equal(t, KindVarint, scores.Header.Kind)
+	assert.Equal(t, "string", scores.Key.Type)
+	assert.Equal(t, KindVarint, scores.Elem.Kind)
+	assert.Equal(t, "int64", scores.Elem.Type)
+
+	lucky := s.Fields[4]
+	assert.Equal(t, KindOption, lucky.Kind)
+	assert.Equal(t, "int16", lucky.Elem.Type)
+
+	best := s.Fields[5]
+	assert.Equal(t, KindStruct, best.Kind)
+	assert.Equal(t, "Best", best.Name)
+	assert.Len(t, best.Fields, 2)
+}
+
+func TestParserAndCompiler_Schema(t *testing.T) {
+	parser, compiler := schemaPetBuilder().Parco()
+
+	assert.Equal(t, parser.Schema(), compiler.Schema())
+	assert.Equal(t, "Age", compiler.Schema().Fields[1].Name)
+}
+
+func TestSchema_String(t *testing.T) {
+	expected := `struct schemaOwner
+  ID uint32 BE
+  Nick string<uint16 LE>
+  Pets slice<uint8>[struct schemaPet]
+    Name string<uint8>
+    Age uint8
+  Scores map<varuint>[string<uint8>]varint64
+  Lucky option[int16 LE]
+  Best struct schemaPet
+    Name string<uint8>
+    Age uint8`
+
+	assert.Equal(t, expected, schemaOwnerBuilder().Schema().String())
+}
+
+func TestSchema_Types(t *testing.T) {
+	tests := []struct {
+		name     string
+		tp       any
+		expected string
+	}{
+		{"bool", Bool(), "bool"},
+		{"int", IntBE(), "int BE"},
+		{"float64", Float64LE(), "float64 LE"},
+		{"blob", Blob(UInt32LEHeader()), "bytes<uint32 LE>"},
+		{"time utc", TimeUTC(), "time"},
+		{"time location", TimeLocation(), "time+location"},
+		{"array", Array[uint16](3, UInt16BE()), "array(3)[uint16 BE]"},
+		{"skip", SkipType(4), "skip(4)"},
+		{"custom fixed", NewFixedType[uint8](1, ParseUInt8, CompileUInt8), "fixed(1)"},
+		{"not a describer", struct{}{}, "unknown"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, Describe(tt.tp).TypeString())
+		})
+	}
+}
+
+func TestField_ID(t *testing.T) {
+	builder := schemaPetBuilder()
+	parser, _ := builder.Parco()
+
+	ids := make([]string, 0, len(parser.fields))
+	for _, f := range parser.fields {
+		ids = append(ids, f.(interface{ ID() string }).ID())
+	}
+
+	assert.Equal(t, []string{"Name", "Age"}, ids)
+}
@@ -2,6 +2,7 @@ package parco
 
 import (
 	"io"
+	"strconv"
 )
 
 const (
@@ -22,12 +23,57 @@ func (t ArrayType[T]) ByteLength() int {
 	return t.length * t.inner.ByteLength()
 }
 
+func (t ArrayType[T]) Size(x Iterable[T]) (int, error) {
+	if x.Len() != t.length {
+		return 0, ErrInvalidLength
+	}
+	return sizeElems(t.inner, x.Unwrap())
+}
+
+// sizeElems returns the encoded size of values, at once if inner has a fixed
+// width.
+func sizeElems[T any](inner Type[T], values []T) (int, error) {
+	if fixed, ok := inner.(fixedType[T]); ok {
+		return len(values) * fixed.byteLength, nil
+	}
+
+	p, byPtr := inner.(ptrSizer[T])
+
+	total := 0
+	for i := range values {
+		var (
+			size int
+			err  error
+		)
+		if byPtr {
+			size, err = p.sizePtr(&values[i])
+		} else {
+			size, err = sizeOf(inner, values[i])
+		}
+		if err != nil {
+			return 0, err
+		}
+		total += size
+	}
+	return total, nil
+}
+
+func (t ArrayType[T]) Schema() Schema {
+	return Schema{
+		Kind:   KindArray,
+		Length: t.length,
+		Elem:   describePtr(t.inner),
+	}
+}
+
 func (t ArrayType[T]) Parse(r io.Reader) (res Iterable[T], err error) {
 	values := make([]T, 0, min(t.length, maxInitialCapacity))
 
-	for range t.length {
+	for i := range t.length {
 		var value T
+		offset := readOffset(r)
 		if value, err = t.inner.Parse(r); err != nil {
+			err = within("["+strconv.Itoa(i)+"]", offset, err)
 			return
 		}
 		values = append(values, value)
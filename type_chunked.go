@@ -0,0 +1,197 @@
+package parco
+
+import (
+	"io"
+	"math"
+	"strconv"
+)
+
+const (
+	// DefaultChunkLength is the element count of the chunks written by
+	// chunked types built from schemas.
+	DefaultChunkLength = 1024
+)
+
+type (
+	// ChunkedType is a sequence written in chunks, each one preceded by its
+	// element count, and ended by an empty chunk. Unlike slices, the element
+	// count need not be known when writing starts.
+	ChunkedType[T any] struct {
+		header IntType
+		chunk  int
+		inner  Type[T]
+	}
+)
+
+func (t ChunkedType[T]) ByteLength() int {
+	return t.header.ByteLength()
+}
+
+func (t ChunkedType[T]) Schema() Schema {
+	return Schema{
+		Kind:   KindChunked,
+		Header: describePtr(t.header),
+		Elem:   describePtr(t.inner),
+	}
+}
+
+func (t ChunkedType[T]) Size(x Iterable[T]) (int, error) {
+	return t.sizeValues(x.Unwrap())
+}
+
+func (t ChunkedType[T]) sizeValues(values []T) (int, error) {
+	size, err := sizeElems(t.inner, values)
+	if err != nil {
+		return 0, err
+	}
+	headers, err := t.headersSize(len(values))
+	return size + headers, err
+}
+
+// headersSize returns the encoded size of the chunk headers of length
+// elements: full chunks, then the last one if partial, then the empty one.
+func (t ChunkedType[T]) headersSize(length int) (int, error) {
+	full, err := sizeOf(t.header, t.chunk)
+	if err != nil {
+		return 0, err
+	}
+	end, err := sizeOf(t.header, 0)
+	if err != nil {
+		return 0, err
+	}
+
+	size := length/t.chunk*full + end
+	if rest := length % t.chunk; rest > 0 {
+		partial, err := sizeOf(t.header, rest)
+		if err != nil {
+			return 0, err
+		}
+		size += partial
+	}
+	return size, nil
+}
+
+func (t ChunkedType[T]) Parse(r io.Reader) (Iterable[T], error) {
+	values := make([]T, 0, min(t.chunk, maxInitialCapacity))
+
+	err := t.parseEach(r, true, func(value T) error {
+		values = append(values, value)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return SliceView[T](values), nil
+}
+
+// parseEach hands the elements to each as they are parsed, until the empty
+// chunk. Past MaxReasonableSliceLength elements in total, it fails with
+// ErrOverflow. Elements kept are charged to the DecodeLimits of the message.
+func (t ChunkedType[T]) parseEach(r io.Reader, keep bool, each func(T) error) error {
+	total := 0
+
+	for {
+		length, err := t.header.Parse(r)
+		if err != nil {
+			return err
+		}
+		if length == 0 {
+			return nil
+		}
+		if length < 0 || length > math.MaxInt-total {
+			return ErrOverflow
+		}
+		if err = checkLength(r, total+length, MaxReasonableSliceLength); err != nil {
+			return err
+		}
+		if keep {
+			if err = allocateElems[T](r, length); err != nil {
+				return err
+			}
+		}
+
+		for range length {
+			offset := readOffset(r)
+			value, err := t.inner.Parse(r)
+			if err == nil {
+				err = each(value)
+			}
+			if err != nil {
+				return within("["+strconv.Itoa(total)+"]", offset, err)
+			}
+			total++
+		}
+	}
+}
+
+func (t ChunkedType[T]) Compile(x Iterable[T], w io.Writer) error {
+	return t.compileEach(func(emit func(T) error) error {
+		return x.Range(ranger[T](emit))
+	}, w)
+}
+
+// compileEach writes the elements produced as they come, closing a chunk
+// every chunk length elements and once produce returns. Closed chunks go out
+// to the underlying writer, see compileWriter.stream.
+func (t ChunkedType[T]) compileEach(produce func(emit func(T) error) error, w io.Writer) error {
+	cw, ok := w.(*compileWriter)
+	if !ok {
+		cw = getCompileWriter(w)
+		defer putCompileWriter(cw)
+		if err := t.compileEach(produce, cw); err != nil {
+			return err
+		}
+		return cw.flush()
+	}
+
+	// The open chunk is pinned until its count is written in front of it.
+	start, length := cw.pin(), 0
+	defer cw.unpin()
+
+	// closeChunk writes the count of the chunk in front of its elements,
+	// writes the chunk out and opens the next one.
+	closeChunk := func() error {
+		mid := len(cw.buf)
+		if err := t.header.Compile(length, cw); err != nil {
+			return err
+		}
+		cw.moveFront(start, mid)
+		cw.unpin()
+		err := cw.stream()
+		start, length = cw.pin(), 0
+		return err
+	}
+
+	err := produce(func(value T) error {
+		if err := t.inner.Compile(value, cw); err != nil {
+			return err
+		}
+		if length++; length == t.chunk {
+			return closeChunk()
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if length > 0 {
+		if err = closeChunk(); err != nil {
+			return err
+		}
+	}
+	return t.header.Compile(0, cw)
+}
+
+// Chunked returns a sequence written in chunks of up to chunk elements, and
+// at most as many as header holds. It panics if chunk is not positive.
+func Chunked[T any](header IntType, chunk int, inner Type[T]) ChunkedType[T] {
+	if chunk < 1 {
+		panic("parco: chunk length must be positive, have " + strconv.Itoa(chunk))
+	}
+	return ChunkedType[T]{
+		header: header,
+		chunk:  min(chunk, headerCapacity(header)),
+		inner:  inner,
+	}
+}
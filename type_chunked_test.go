@@ -0,0 +1,177 @@
+package parco
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChunkedType(t *testing.T) {
+	tp := Chunked[uint8](UInt8Header(), 2, UInt8())
+
+	tests := []struct {
+		Name     string
+		Payload  []uint8
+		Expected []byte
+	}{
+		{"empty", []uint8{}, []byte{0}},
+		{"partial chunk", []uint8{1}, []byte{1, 1, 0}},
+		{"full chunks", []uint8{1, 2, 3, 4}, []byte{2, 1, 2, 2, 3, 4, 0}},
+		{"full and partial chunks", []uint8{1, 2, 3}, []byte{2, 1, 2, 1, 3, 0}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			buf := bytes.NewBuffer(nil)
+			require.NoError(t, tp.Compile(SliceView[uint8](test.Payload), buf))
+			assert.Equal(t, test.Expected, buf.Bytes())
+
+			size, err := tp.Size(SliceView[uint8](test.Payload))
+			require.NoError(t, err)
+			assert.Equal(t, len(test.Expected), size)
+
+			values, err := tp.Parse(bytes.NewReader(test.Expected))
+			require.NoError(t, err)
+			assert.Equal(t, test.Payload, []uint8(values.Unwrap()))
+		})
+	}
+
+	// Chunks of any length parse alike.
+	values, err := tp.Parse(bytes.NewReader([]byte{3, 1, 2, 3, 1, 4, 0}))
+	require.NoError(t, err)
+	assert.Equal(t, []uint8{1, 2, 3, 4}, []uint8(values.Unwrap()))
+
+	// Chunks are as long as the header holds at most.
+	buf := bytes.NewBuffer(nil)
+	require.NoError(t, Chunked[uint8](UInt8Header(), 1000, UInt8()).Compile(SliceView[uint8](createSlice(uint8(1), 300)), buf))
+	assert.Equal(t, byte(255), buf.Bytes()[0])
+	assert.Equal(t, byte(45), buf.Bytes()[256])
+	assert.Equal(t, 300+3, buf.Len())
+
+	assert.Panics(t, func() { Chunked[uint8](UInt8Header(), 0, UInt8()) })
+}
+
+func TestChunkedType_Failures(t *testing.T) {
+	tp := Chunked[uint8](UInt32LEHeader(), 16, UInt8())
+
+	// Missing terminator.
+	_, err := tp.Parse(bytes.NewReader([]byte{1, 0, 0, 0, 7}))
+	assert.ErrorIs(t, err, ErrCannotRead)
+
+	// Chunks adding up past MaxReasonableSliceLength, whatever they hold.
+	half := MaxReasonableSliceLength/2 + 1
+	data := []byte{byte(half), byte(half >> 8), byte(half >> 16), byte(half >> 24)}
+	_, err = tp.Parse(bytes.NewReader(append(data, data...)))
+	assert.ErrorIs(t, err, ErrCannotRead)
+
+	cursor := NewBufferCursor(append(append(data, make([]byte, half)...), data...), 0)
+	_, err = tp.Parse(&cursor)
+	assert.ErrorIs(t, err, ErrOverflow)
+}
+
+func TestChunkedType_WritesThrough(t *testing.T) {
+	w := bytes.NewBuffer(nil)
+	var written []int
+	builder := Builder[export](ObjectFactory[export]()).
+		Slice(StreamSliceFieldChunked(UInt8Header(), 2, UInt8(), nil,
+			func(e *export, emit func(uint8) error) error {
+				for i := range e.Rows {
+					written = append(written, w.Len())
+					if err := emit(uint8(i + 1)); err != nil {
+						return err
+					}
+				}
+				return nil
+			},
+		))
+
+	require.NoError(t, builder.Compile(export{Rows: 5}, w))
+	assert.Equal(t, []byte{2, 1, 2, 2, 3, 4, 1, 5, 0}, w.Bytes())
+	// Chunks reach the writer as soon as they are closed.
+	assert.Equal(t, []int{0, 0, 3, 3, 6}, written)
+}
+
+type feed struct {
+	Events []string
+	Count  int
+}
+
+func TestChunkedField(t *testing.T) {
+	events := []string{"a", "bc", "d"}
+
+	sliced := Builder[feed](ObjectFactory[feed]()).
+		Slice(ChunkedField[feed, string](
+			VarUIntHeader(),
+			2,
+			SmallVarchar(),
+			func(f *feed, v SliceView[string]) { f.Events = v },
+			func(f *feed) SliceView[string] { return f.Events },
+		)).Named("Events")
+
+	streamed := Builder[feed](ObjectFactory[feed]()).
+		Slice(StreamSliceFieldChunked[feed, string](
+			VarUIntHeader(),
+			2,
+			SmallVarchar(),
+			func(f *feed, v string) error {
+				f.Count++
+				return nil
+			},
+			func(f *feed, emit func(string) error) error {
+				for _, event := range f.Events {
+					if err := emit(event); err != nil {
+						return err
+					}
+				}
+				return nil
+			},
+		)).Named("Events")
+
+	data, err := sliced.Append(nil, feed{Events: events})
+	require.NoError(t, err)
+	assert.Equal(t, []byte{2, 1, 'a', 2, 'b', 'c', 1, 1, 'd', 0}, data)
+
+	streamedData, err := streamed.Append(nil, feed{Events: events})
+	require.NoError(t, err)
+	assert.Equal(t, data, streamedData)
+
+	size, err := streamed.Size(feed{Events: events})
+	require.NoError(t, err)
+	assert.Equal(t, len(data), size)
+
+	parsed, err := sliced.ParseBytes(data)
+	require.NoError(t, err)
+	assert.Equal(t, events, parsed.Events)
+
+	counted, err := streamed.ParseBytes(data)
+	require.NoError(t, err)
+	assert.Equal(t, 3, counted.Count)
+
+	// Schema driven tools read chunks alike.
+	schema := Describe(sliced)
+	assert.Equal(t, "chunked<varuint>[string<uint8>]", schema.Fields[0].TypeString())
+
+	doc, err := ToJSON(schema, data)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"Events":["a","bc","d"]}`, string(doc))
+
+	view, err := NewView(schema)
+	require.NoError(t, err)
+	raw, err := view.Bytes(data, "Events")
+	require.NoError(t, err)
+	assert.Equal(t, data, raw)
+
+	m, err := Dynamic(schema)
+	require.NoError(t, err)
+	buf := bytes.NewBuffer(nil)
+	require.NoError(t, m.Compile(map[string]any{"Events": []any{"a", "bc", "d"}}, buf))
+	value, err := m.ParseBytes(buf.Bytes())
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"Events": []any{"a", "bc", "d"}}, value)
+
+	file, err := ParseSchemaFile([]byte("message feed {\n  Events chunked<varuint>[string<uint8>]\n}"))
+	require.NoError(t, err)
+	assert.Equal(t, schema.Fields[0], file.Models[0].Fields[0])
+}
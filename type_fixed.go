@@ -1,6 +1,9 @@
 package parco
 
-import "io"
+import (
+	"encoding/binary"
+	"io"
+)
 
 type (
 	fixedType[T any] struct {
@@ -8,6 +11,7 @@ type (
 		parser     ParserFunc[T]
 		compiler   CompilerFunc[T]
 		pool       Pooler
+		schema     Schema
 	}
 )
 
@@ -15,6 +19,14 @@ func (i fixedType[T]) ByteLength() int {
 	return i.byteLength
 }
 
+func (i fixedType[T]) Size(T) (int, error) {
+	return i.byteLength, nil
+}
+
+func (i fixedType[T]) Schema() Schema {
+	return i.schema
+}
+
 func (i fixedType[T]) ParseBytes(data []byte) (res T, err error) {
 	return i.parser(data)
 }
@@ -66,11 +78,29 @@ func NewFixedType[T any](
 	byteLength int,
 	parserFunc ParserFunc[T],
 	compilerFunc CompilerFunc[T],
+) Type[T] {
+	return newFixedType(fixedSchema("", byteLength, nil), parserFunc, compilerFunc)
+}
+
+func newFixedType[T any](
+	schema Schema,
+	parserFunc ParserFunc[T],
+	compilerFunc CompilerFunc[T],
 ) Type[T] {
 	return fixedType[T]{
-		byteLength: byteLength,
+		byteLength: schema.ByteLength,
 		parser:     parserFunc,
 		compiler:   compilerFunc,
 		pool:       SinglePool,
+		schema:     schema,
+	}
+}
+
+func fixedSchema(name string, byteLength int, order binary.ByteOrder) Schema {
+	return Schema{
+		Kind:       KindFixed,
+		Type:       name,
+		ByteLength: byteLength,
+		Order:      order,
 	}
 }
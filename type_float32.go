@@ -23,8 +23,8 @@ func ParseFloat32(data []byte, order binary.ByteOrder) (float32, error) {
 }
 
 func Float32(order binary.ByteOrder) Type[float32] {
-	return NewFixedType[float32](
-		4,
+	return newFixedType[float32](
+		fixedSchema("float32", 4, order),
 		func(data []byte) (float32, error) {
 			return ParseFloat32(data, order)
 		},
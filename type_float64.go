@@ -23,8 +23,8 @@ func ParseFloat64(data []byte, order binary.ByteOrder) (float64, error) {
 }
 
 func Float64(order binary.ByteOrder) Type[float64] {
-	return NewFixedType[float64](
-		8,
+	return newFixedType[float64](
+		fixedSchema("float64", 8, order),
 		func(data []byte) (float64, error) {
 			return ParseFloat64(data, order)
 		},
@@ -39,8 +39,8 @@ func ParseUInt(data []byte, order binary.ByteOrder) (uint, error) {
 }
 
 func UInt(order binary.ByteOrder) Type[uint] {
-	return NewFixedType[uint](
-		uintBitSize,
+	return newFixedType[uint](
+		fixedSchema("uint", uintBitSize, order),
 		func(data []byte) (uint, error) {
 			return ParseUInt(data, order)
 		},
@@ -59,8 +59,8 @@ func UIntBE() Type[uint] {
 }
 
 func UIntHeader(order binary.ByteOrder) Type[int] {
-	return NewFixedType[int](
-		uintBitSize,
+	return newFixedType[int](
+		fixedSchema("uint", uintBitSize, order),
 		func(data []byte) (int, error) {
 			n, err := ParseUInt(data, order)
 			m := int(n)
@@ -110,8 +110,8 @@ func ParseInt(data []byte, order binary.ByteOrder) (int, error) {
 }
 
 func Int(order binary.ByteOrder) Type[int] {
-	return NewFixedType[int](
-		uintBitSize,
+	return newFixedType[int](
+		fixedSchema("int", uintBitSize, order),
 		func(data []byte) (int, error) {
 			return ParseInt(data, order)
 		},
@@ -130,8 +130,8 @@ func IntBE() Type[int] {
 }
 
 func IntHeader(order binary.ByteOrder) Type[int] {
-	return NewFixedType[int](
-		uintBitSize,
+	return newFixedType[int](
+		fixedSchema("int", uintBitSize, order),
 		func(data []byte) (int, error) {
 			n, err := ParseInt(data, order)
 			m := int(n)
@@ -1,7 +1,9 @@
 package parco
 
 import (
+	"fmt"
 	"io"
+	"strconv"
 )
 
 const (
@@ -11,6 +13,13 @@ const (
 )
 
 type (
+	// mapSlot is the memory an entry of a map of K to V takes, short of the
+	// bookkeeping of the map itself.
+	mapSlot[K comparable, V any] struct {
+		key   K
+		value V
+	}
+
 	mapType[K comparable, V any] struct {
 		length    int
 		header    IntType
@@ -24,6 +33,35 @@ func (t mapType[K, V]) ByteLength() int {
 	return t.header.ByteLength() + t.length*(t.keyType.ByteLength()+t.valueType.ByteLength())
 }
 
+func (t mapType[K, V]) Size(x map[K]V) (int, error) {
+	total, err := sizeOf(t.header, len(x))
+	if err != nil {
+		return 0, err
+	}
+
+	for k, v := range x {
+		key, err := sizeOf(t.keyType, k)
+		if err != nil {
+			return 0, err
+		}
+		value, err := sizeOf(t.valueType, v)
+		if err != nil {
+			return 0, err
+		}
+		total += key + value
+	}
+	return total, nil
+}
+
+func (t mapType[K, V]) Schema() Schema {
+	return Schema{
+		Kind:   KindMap,
+		Header: describePtr(t.header),
+		Key:    describePtr(t.keyType),
+		Elem:   describePtr(t.valueType),
+	}
+}
+
 func (t mapType[K, V]) Parse(r io.Reader) (res map[K]V, err error) {
 	var (
 		length int
@@ -35,21 +73,29 @@ func (t mapType[K, V]) Parse(r io.Reader) (res map[K]V, err error) {
 	}
 
 	// Validate length to prevent excessive memory allocation
-	if length < 0 || length > MaxReasonableMapLength {
-		return nil, ErrOverflow
+	if err = checkLength(r, length, MaxReasonableMapLength); err != nil {
+		return nil, err
+	}
+	if err = allocateElems[mapSlot[K, V]](r, length); err != nil {
+		return nil, err
 	}
 
 	values := make(map[K]V, min(t.length, maxInitialCapacity))
 
-	for range t.length {
+	for i := range t.length {
 		var (
 			k K
 			v V
 		)
+		offset := readOffset(r)
 		if k, err = t.keyType.Parse(r); err != nil {
+			// The key is unknown: locate the entry by its position.
+			err = within("[#"+strconv.Itoa(i)+"]", offset, err)
 			return
 		}
+		offset = readOffset(r)
 		if v, err = t.valueType.Parse(r); err != nil {
+			err = within(fmt.Sprintf("[%v]", k), offset, err)
 			return
 		}
 
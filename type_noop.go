@@ -25,4 +25,28 @@ type (
 	}
 
 	IntType = Type[int]
+
+	// SizedType is implemented by types telling the exact encoded size of
+	// their values without compiling them, as all built-in types do.
+	SizedType[T any] interface {
+		Size(T) (int, error)
+	}
+
+	// ptrSizer is implemented by types sizing values through pointers,
+	// sparing copies of them.
+	ptrSizer[T any] interface {
+		sizePtr(*T) (int, error)
+	}
 )
+
+// sizeOf returns the encoded size of value, compiling it aside if tp is not
+// a SizedType.
+func sizeOf[T any](tp CompilerType[T], value T) (int, error) {
+	if s, ok := tp.(SizedType[T]); ok {
+		return s.Size(value)
+	}
+
+	d := discard{}
+	err := tp.Compile(value, &d)
+	return d.Size(), err
+}
@@ -10,6 +10,36 @@ type (
 	}
 )
 
+// ByteLength returns the byte length of the presence flag.
+func (i OptionalType[T]) ByteLength() int {
+	return i.header.ByteLength()
+}
+
+func (i OptionalType[T]) Size(item *T) (int, error) {
+	if item == nil {
+		return sizeOf(i.header, false)
+	}
+	return i.sizeSome(*item)
+}
+
+// sizeSome returns the encoded size of the option holding item.
+func (i OptionalType[T]) sizeSome(item T) (int, error) {
+	flag, err := sizeOf(i.header, true)
+	if err != nil {
+		return 0, err
+	}
+	size, err := sizeOf(i.inner, item)
+	return flag + size, err
+}
+
+func (i OptionalType[T]) Schema() Schema {
+	return Schema{
+		Kind:   KindOption,
+		Header: describePtr(i.header),
+		Elem:   describePtr(i.inner),
+	}
+}
+
 func (i OptionalType[T]) Parse(r io.Reader) (*T, error) {
 	some, err := i.header.Parse(r)
 	if err != nil {
@@ -14,6 +14,10 @@ func SkipType(pad int) Type[any] {
 			return nil
 		},
 		pool: SinglePool,
+		schema: Schema{
+			Kind:       KindSkip,
+			ByteLength: pad,
+		},
 	}
 }
 
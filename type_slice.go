@@ -28,6 +28,27 @@ func (t SliceType[T]) ByteLength() int {
 	return t.header.ByteLength() + t.length*t.inner.ByteLength()
 }
 
+func (t SliceType[T]) Size(x Iterable[T]) (int, error) {
+	return t.sizeValues(x.Unwrap())
+}
+
+func (t SliceType[T]) sizeValues(values []T) (int, error) {
+	header, err := sizeOf(t.header, len(values))
+	if err != nil {
+		return 0, err
+	}
+	size, err := sizeElems(t.inner, values)
+	return header + size, err
+}
+
+func (t SliceType[T]) Schema() Schema {
+	return Schema{
+		Kind:   KindSlice,
+		Header: describePtr(t.header),
+		Elem:   describePtr(t.inner),
+	}
+}
+
 func (t SliceType[T]) Parse(r io.Reader) (res Iterable[T], err error) {
 	var (
 		length int
@@ -38,8 +59,11 @@ func (t SliceType[T]) Parse(r io.Reader) (res Iterable[T], err error) {
 	}
 
 	// Validate length to prevent excessive memory allocation
-	if length < 0 || length > MaxReasonableSliceLength {
-		return nil, ErrOverflow
+	if err = checkLength(r, length, MaxReasonableSliceLength); err != nil {
+		return nil, err
+	}
+	if err = allocateElems[T](r, length); err != nil {
+		return nil, err
 	}
 
 	arrType := Array[T](length, t.inner)
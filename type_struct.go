@@ -11,6 +11,28 @@ func (s StructType[T]) ByteLength() int {
 	panic("not implemented")
 }
 
+func (s StructType[T]) Size(value T) (int, error) {
+	return sizeOf(s.CompilerType, value)
+}
+
+func (s StructType[T]) sizePtr(value *T) (int, error) {
+	if p, ok := s.CompilerType.(ptrSizer[T]); ok {
+		return p.sizePtr(value)
+	}
+	return s.Size(*value)
+}
+
+// Schema describes the nested model, preferring the parser's view of it.
+func (s StructType[T]) Schema() Schema {
+	if d, ok := s.ParserType.(Describer); ok {
+		return d.Schema()
+	}
+	if d, ok := s.CompilerType.(Describer); ok {
+		return d.Schema()
+	}
+	return Schema{Kind: KindStruct, Type: typeName[T]()}
+}
+
 func Struct[T any](b ModelBuilder[T]) StructType[T] {
 	parser, compiler := b.Parco()
 	return StructParco[T](parser, compiler)
@@ -22,6 +22,24 @@ func (t TimeType) ByteLength() int {
 	return timeByteLength
 }
 
+// Size counts the name of the location of tt along its instant.
+func (t TimeType) Size(tt time.Time) (int, error) {
+	if loc := tt.Location(); loc != nil {
+		location, err := t.locationType.sizeSome(loc.String())
+		return timeByteLength + location, err
+	}
+	return timeByteLength, nil
+}
+
+func (t TimeType) Schema() Schema {
+	return Schema{
+		Kind:     KindTime,
+		Type:     "time",
+		Order:    binary.LittleEndian,
+		Location: true,
+	}
+}
+
 func (t TimeType) Parse(r io.Reader) (time.Time, error) {
 	box := t.pooler.Get(timeByteLength)
 	defer t.pooler.Put(box)
@@ -29,9 +47,8 @@ func (t TimeType) Parse(r io.Reader) (time.Time, error) {
 	data := *box
 	data = data[:timeByteLength]
 
-	n, err := r.Read(data)
-	if n != timeByteLength || err != nil {
-		return noopTime, ErrCannotRead
+	if err := readFull(r, data); err != nil {
+		return noopTime, err
 	}
 
 	tim, err := ParseTime(data, binary.LittleEndian)
@@ -40,10 +57,12 @@ func (t TimeType) Parse(r io.Reader) (time.Time, error) {
 	}
 
 	locationRaw, err := t.locationType.Parse(r)
-
-	if err != nil || locationRaw == nil {
+	if err != nil {
 		return noopTime, err
 	}
+	if locationRaw == nil {
+		return tim, nil
+	}
 
 	loc, err := time.LoadLocation(*locationRaw)
 
@@ -98,8 +117,13 @@ func TimeLocation() Type[time.Time] {
 }
 
 func TimeUTC() Type[time.Time] {
-	return NewFixedType[time.Time](
-		timeByteLength,
+	return newFixedType[time.Time](
+		Schema{
+			Kind:       KindTime,
+			Type:       "time",
+			ByteLength: timeByteLength,
+			Order:      binary.LittleEndian,
+		},
 		func(data []byte) (time.Time, error) {
 			return ParseTime(data, binary.LittleEndian)
 		},
@@ -50,8 +50,8 @@ func CompileUInt16(u16 uint16, box []byte, order binary.ByteOrder) (err error) {
 }
 
 func UInt16(order binary.ByteOrder) Type[uint16] {
-	return NewFixedType[uint16](
-		2,
+	return newFixedType[uint16](
+		fixedSchema("uint16", 2, order),
 		ParseUInt16Factory(order),
 		CompileUInt16Factory(order),
 	)
@@ -66,8 +66,8 @@ func UInt16BE() Type[uint16] {
 }
 
 func UInt16Header(order binary.ByteOrder) Type[int] {
-	return NewFixedType[int](
-		2,
+	return newFixedType[int](
+		fixedSchema("uint16", 2, order),
 		ParseUInt16HeaderFactory(order),
 		CompileUInt16HeaderFactory(order),
 	)
@@ -95,8 +95,8 @@ func CompileInt16(i16 int16, box []byte, order binary.ByteOrder) (err error) {
 }
 
 func Int16(order binary.ByteOrder) Type[int16] {
-	return NewFixedType[int16](
-		2,
+	return newFixedType[int16](
+		fixedSchema("int16", 2, order),
 		func(data []byte) (int16, error) {
 			return ParseInt16(data, order)
 		},
@@ -115,8 +115,8 @@ func Int16BE() Type[int16] {
 }
 
 func Int16Header(order binary.ByteOrder) Type[int] {
-	return NewFixedType[int](
-		2,
+	return newFixedType[int](
+		fixedSchema("int16", 2, order),
 		func(data []byte) (int, error) {
 			n, err := ParseInt16(data, order)
 			return int(n), err
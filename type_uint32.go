@@ -18,8 +18,8 @@ func ParseUInt32(data []byte, order binary.ByteOrder) (uint32, error) {
 }
 
 func UInt32(order binary.ByteOrder) Type[uint32] {
-	return NewFixedType[uint32](
-		4,
+	return newFixedType[uint32](
+		fixedSchema("uint32", 4, order),
 		func(data []byte) (uint32, error) {
 			return ParseUInt32(data, order)
 		},
@@ -38,8 +38,8 @@ func UInt32BE() Type[uint32] {
 }
 
 func UInt32Header(order binary.ByteOrder) Type[int] {
-	return NewFixedType[int](
-		4,
+	return newFixedType[int](
+		fixedSchema("uint32", 4, order),
 		func(data []byte) (int, error) {
 			n, err := ParseUInt32(data, order)
 			m := int(n)
@@ -77,8 +77,8 @@ func ParseInt32(data []byte, order binary.ByteOrder) (int32, error) {
 }
 
 func Int32(order binary.ByteOrder) Type[int32] {
-	return NewFixedType[int32](
-		4,
+	return newFixedType[int32](
+		fixedSchema("int32", 4, order),
 		func(data []byte) (int32, error) {
 			return ParseInt32(data, order)
 		},
@@ -97,8 +97,8 @@ func Int32BE() Type[int32] {
 }
 
 func Int32Header(order binary.ByteOrder) Type[int] {
-	return NewFixedType[int](
-		4,
+	return newFixedType[int](
+		fixedSchema("int32", 4, order),
 		func(data []byte) (int, error) {
 			n, err := ParseInt32(data, order)
 			m := int(n)
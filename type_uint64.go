@@ -18,8 +18,8 @@ func ParseUInt64(data []byte, order binary.ByteOrder) (uint64, error) {
 }
 
 func UInt64(order binary.ByteOrder) Type[uint64] {
-	return NewFixedType[uint64](
-		8,
+	return newFixedType[uint64](
+		fixedSchema("uint64", 8, order),
 		func(data []byte) (uint64, error) {
 			return ParseUInt64(data, order)
 		},
@@ -38,8 +38,8 @@ func UInt64BE() Type[uint64] {
 }
 
 func UInt64Header(order binary.ByteOrder) Type[int] {
-	return NewFixedType[int](
-		8,
+	return newFixedType[int](
+		fixedSchema("uint64", 8, order),
 		func(data []byte) (int, error) {
 			n, err := ParseUInt64(data, order)
 			m := int(n)
@@ -77,8 +77,8 @@ func ParseInt64(data []byte, order binary.ByteOrder) (int64, error) {
 }
 
 func Int64(order binary.ByteOrder) Type[int64] {
-	return NewFixedType[int64](
-		8,
+	return newFixedType[int64](
+		fixedSchema("int64", 8, order),
 		func(data []byte) (int64, error) {
 			return ParseInt64(data, order)
 		},
@@ -97,8 +97,8 @@ func Int64BE() Type[int64] {
 }
 
 func Int64Header(order binary.ByteOrder) Type[int] {
-	return NewFixedType[int](
-		8,
+	return newFixedType[int](
+		fixedSchema("int64", 8, order),
 		func(data []byte) (int, error) {
 			n, err := ParseInt64(data, order)
 			m := int(n)
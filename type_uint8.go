@@ -31,8 +31,8 @@ func ParseUInt8Header(data []byte) (int, error) {
 }
 
 func UInt8() Type[uint8] {
-	return NewFixedType[uint8](
-		1,
+	return newFixedType[uint8](
+		fixedSchema("uint8", 1, nil),
 		ParseUInt8,
 		CompileUInt8,
 	)
@@ -43,8 +43,8 @@ func Byte() Type[byte] {
 }
 
 func UInt8Header() Type[int] {
-	return NewFixedType[int](
-		1,
+	return newFixedType[int](
+		fixedSchema("uint8", 1, nil),
 		ParseUInt8Header,
 		CompileUInt8Header,
 	)
@@ -80,24 +80,24 @@ func ParseInt8Header(box []byte) (int, error) {
 }
 
 func Int8() Type[int8] {
-	return NewFixedType[int8](
-		1,
+	return newFixedType[int8](
+		fixedSchema("int8", 1, nil),
 		ParseInt8,
 		CompileInt8,
 	)
 }
 
 func Int8Header() Type[int] {
-	return NewFixedType[int](
-		1,
+	return newFixedType[int](
+		fixedSchema("int8", 1, nil),
 		ParseInt8Header,
 		CompileInt8Header,
 	)
 }
 
 func Bool() Type[bool] {
-	return NewFixedType[bool](
-		1,
+	return newFixedType[bool](
+		fixedSchema("bool", 1, nil),
 		func(data []byte) (bool, error) {
 			n, err := ParseUInt8(data)
 			if err != nil {
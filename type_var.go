@@ -12,7 +12,15 @@ const (
 )
 
 type (
+	// capacityHeader is implemented by header types whose range cannot be
+	// derived from their byte length, such as varints.
+	capacityHeader interface {
+		capacity() int
+	}
+
 	varType[T any] struct {
+		name string
+
 		header IntType
 
 		pool Pooler
@@ -21,6 +29,10 @@ type (
 
 		parser ParserFunc[T]
 
+		// alias, if set, parses values aliasing the input when reading a
+		// buffer in zero-copy mode. See NewZeroCopyBufferCursor.
+		alias ParserFunc[T]
+
 		compiler func(T, io.Writer) error
 	}
 )
@@ -33,6 +45,24 @@ func (v varType[T]) Header() IntType {
 	return v.header
 }
 
+func (v varType[T]) Size(value T) (int, error) {
+	size := v.sizer.Len(value)
+	if headerCapacity(v.header) < size {
+		return 0, ErrOverflow
+	}
+
+	header, err := sizeOf(v.header, size)
+	return header + size, err
+}
+
+func (v varType[T]) Schema() Schema {
+	return Schema{
+		Kind:   KindVarchar,
+		Type:   v.name,
+		Header: describePtr(v.header),
+	}
+}
+
 func (v varType[T]) ParseBytes(box []byte) (res T, err error) {
 	return v.parser(box)
 }
@@ -45,8 +75,20 @@ func (v varType[T]) Parse(r io.Reader) (res T, err error) {
 	}
 
 	// Validate size to prevent excessive memory allocation
-	if size < 0 || size > MaxReasonableVarSize {
-		err = ErrOverflow
+	if err = checkVarSize(r, size); err != nil {
+		return
+	}
+
+	if v.alias != nil {
+		if data, ok, err := nextZeroCopy(r, size); ok {
+			if err != nil {
+				return res, err
+			}
+			return v.alias(data)
+		}
+	}
+
+	if err = allocate(r, size); err != nil {
 		return
 	}
 
@@ -73,7 +115,7 @@ func (v varType[T]) Parse(r io.Reader) (res T, err error) {
 
 func (v varType[T]) Compile(value T, w io.Writer) (err error) {
 	size := v.sizer.Len(value)
-	if MaxSize(v.header.ByteLength()) < size {
+	if headerCapacity(v.header) < size {
 		err = ErrOverflow
 		return
 	}
@@ -85,3 +127,11 @@ func (v varType[T]) Compile(value T, w io.Writer) (err error) {
 	err = v.compiler(value, w)
 	return
 }
+
+// headerCapacity returns the largest value the given header can represent.
+func headerCapacity(header IntType) int {
+	if h, ok := header.(capacityHeader); ok {
+		return h.capacity()
+	}
+	return MaxSize(header.ByteLength())
+}
@@ -8,30 +8,36 @@ import (
 
 func Blob(header IntType) Type[[]byte] {
 	return varType[[]byte]{
+		name:     "bytes",
 		header:   header,
 		sizer:    SizerFunc[[]byte](func(x []byte) int { return len(x) }),
 		pool:     SinglePool,
 		parser:   ParseBlob,
+		alias:    aliasBlob,
 		compiler: CompileBlob,
 	}
 }
 
 func NewVarcharType(header IntType) Type[string] {
 	return varType[string]{
+		name:     "string",
 		header:   header,
 		sizer:    SizerFunc[string](func(x string) int { return len(x) }),
 		pool:     SinglePool,
 		parser:   ParseStringFactory(),
+		alias:    aliasString,
 		compiler: CompileStringWriter,
 	}
 }
 
 func String(header IntType) Type[string] {
 	return varType[string]{
+		name:     "string",
 		header:   header,
 		sizer:    SizerFunc[string](func(x string) int { return len(x) }),
 		pool:     SinglePool,
 		parser:   ParseStringFactory(),
+		alias:    aliasString,
 		compiler: CompileStringWriter,
 	}
 }
@@ -98,6 +104,17 @@ func ParseBlob(data []byte) ([]byte, error) {
 	return bytes.Clone(data), nil
 }
 
+// aliasBlob returns data as is, for zero-copy parsing.
+func aliasBlob(data []byte) ([]byte, error) {
+	return data, nil
+}
+
+// aliasString returns a string sharing the bytes of data, for zero-copy
+// parsing.
+func aliasString(data []byte) (string, error) {
+	return Bytes2String(data), nil
+}
+
 func CompileBlob(x []byte, w io.Writer) (err error) {
 	var written int
 	written, err = w.Write(x)
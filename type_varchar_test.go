@@ -7,6 +7,7 @@ import (
 	"strings"
 	"testing"
 	"testing/iotest"
+	"unsafe"
 
 	"github.com/stretchr/testify/require"
 )
@@ -145,3 +146,28 @@ func parseTestBlob(t *testing.T, typ Type[[]byte], payload []byte) []byte {
 
 	return res
 }
+
+func TestVarcharType_ZeroCopy(t *testing.T) {
+	data := []byte{2, 'h', 'i', 3, 'a', 'b', 'c', 0xff}
+
+	cursor := NewZeroCopyBufferCursor(data, 0)
+	s, err := SmallVarchar().Parse(&cursor)
+	require.NoError(t, err)
+	require.Equal(t, "hi", s)
+	require.Same(t, &data[1], unsafe.StringData(s))
+
+	blob, err := Blob(UInt8Header()).Parse(&cursor)
+	require.NoError(t, err)
+	require.Equal(t, []byte("abc"), blob)
+	require.Same(t, &data[4], &blob[0])
+	require.Equal(t, 3, cap(blob), "appending must not overwrite the input")
+
+	_, err = SmallVarchar().Parse(&cursor)
+	require.ErrorIs(t, err, ErrCannotRead)
+
+	// Plain cursors copy.
+	cursor = NewBufferCursor(data, 0)
+	s, err = SmallVarchar().Parse(&cursor)
+	require.NoError(t, err)
+	require.NotSame(t, &data[1], unsafe.StringData(s))
+}
@@ -0,0 +1,236 @@
+package parco
+
+import (
+	"encoding/binary"
+	"io"
+	"math"
+	"math/bits"
+)
+
+type (
+	integer interface {
+		~int | ~int8 | ~int16 | ~int32 | ~int64 |
+			~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64
+	}
+
+	// varintType encodes integers as LEB128 varints: 7 bits per byte, the high
+	// bit flagging that more bytes follow. Signed types are zigzag mapped first
+	// so that small negative numbers stay short on the wire.
+	varintType[T integer] struct {
+		name   string
+		maxLen int
+		zigzag bool
+		pool   Pooler
+	}
+)
+
+// ByteLength returns the maximum number of bytes a value of this type may take
+// on the wire. Actual encodings are usually shorter.
+func (t varintType[T]) ByteLength() int {
+	return t.maxLen
+}
+
+// capacity reports the largest length this type can carry when used as a
+// header. Varint headers are not bounded by their byte width, only by int.
+func (t varintType[T]) capacity() int {
+	return math.MaxInt
+}
+
+func (t varintType[T]) Size(value T) (int, error) {
+	u, err := t.toWire(value)
+	if err != nil {
+		return 0, err
+	}
+	return uvarintLen(u), nil
+}
+
+// uvarintLen returns the byte length of u encoded as an uvarint.
+func uvarintLen(u uint64) int {
+	return max(1, (bits.Len64(u)+6)/7)
+}
+
+func (t varintType[T]) Schema() Schema {
+	return Schema{Kind: KindVarint, Type: t.name}
+}
+
+func (t varintType[T]) Parse(r io.Reader) (res T, err error) {
+	var u uint64
+	if u, err = t.readUvarint(r); err != nil {
+		return
+	}
+	return t.fromWire(u)
+}
+
+func (t varintType[T]) Compile(value T, w io.Writer) (err error) {
+	var u uint64
+	if u, err = t.toWire(value); err != nil {
+		return
+	}
+
+	if cw, ok := w.(*compileWriter); ok {
+		cw.buf = binary.AppendUvarint(cw.buf, u)
+		return nil
+	}
+
+	box := t.pool.Get(binary.MaxVarintLen64)
+	defer t.pool.Put(box)
+
+	n := binary.PutUvarint(*box, u)
+	written, err := w.Write((*box)[:n])
+	if err != nil {
+		return
+	}
+	if written != n {
+		err = ErrCannotWrite
+	}
+	return
+}
+
+func (t varintType[T]) toWire(value T) (uint64, error) {
+	if t.zigzag {
+		i := int64(value)
+		return uint64(i<<1) ^ uint64(i>>63), nil
+	}
+	if value < 0 {
+		// Only reachable for int headers, which are unsigned on the wire.
+		return 0, ErrOverflow
+	}
+	return uint64(value), nil
+}
+
+func (t varintType[T]) fromWire(u uint64) (T, error) {
+	if t.zigzag {
+		i := int64(u>>1) ^ -int64(u&1)
+		if int64(T(i)) != i {
+			return 0, ErrOverflow
+		}
+		return T(i), nil
+	}
+	if T(u) < 0 || uint64(T(u)) != u {
+		return 0, ErrOverflow
+	}
+	return T(u), nil
+}
+
+// readUvarint reads the shortest encoding of a value only, so that every
+// value has a single wire form: a zero byte may not end a longer varint.
+func (t varintType[T]) readUvarint(r io.Reader) (uint64, error) {
+	var (
+		x     uint64
+		shift uint
+	)
+
+	for i := 0; i < t.maxLen; i++ {
+		b, err := readByte(r, t.pool)
+		if err != nil {
+			return 0, err
+		}
+		if b < 0x80 {
+			if i == binary.MaxVarintLen64-1 && b > 1 {
+				return 0, ErrOverflow
+			}
+			if i > 0 && b == 0 {
+				return 0, ErrNonCanonical
+			}
+			return x | uint64(b)<<shift, nil
+		}
+		x |= uint64(b&0x7f) << shift
+		shift += 7
+	}
+
+	return 0, ErrOverflow
+}
+
+// readByte reads a single byte, using io.ByteReader when the reader offers it.
+func readByte(r io.Reader, pool Pooler) (byte, error) {
+	if br, ok := r.(io.ByteReader); ok {
+		b, err := br.ReadByte()
+		if err == io.EOF {
+			return 0, ErrCannotRead
+		}
+		return b, err
+	}
+
+	box := pool.Get(1)
+	defer pool.Put(box)
+
+	data := (*box)[:1]
+	if err := readFull(r, data); err != nil {
+		return 0, err
+	}
+	return data[0], nil
+}
+
+func varintMaxLen(bits int) int {
+	return (bits + 6) / 7
+}
+
+func newVarintType[T integer](name string, bits int, zigzag bool) Type[T] {
+	return varintType[T]{
+		name:   name,
+		maxLen: varintMaxLen(bits),
+		zigzag: zigzag,
+		pool:   SinglePool,
+	}
+}
+
+// VarUInt8 encodes an uint8 as an unsigned LEB128 varint (1-2 bytes).
+func VarUInt8() Type[uint8] {
+	return newVarintType[uint8]("uint8", 8, false)
+}
+
+// VarUInt16 encodes an uint16 as an unsigned LEB128 varint (1-3 bytes).
+func VarUInt16() Type[uint16] {
+	return newVarintType[uint16]("uint16", 16, false)
+}
+
+// VarUInt32 encodes an uint32 as an unsigned LEB128 varint (1-5 bytes).
+func VarUInt32() Type[uint32] {
+	return newVarintType[uint32]("uint32", 32, false)
+}
+
+// VarUInt64 encodes an uint64 as an unsigned LEB128 varint (1-10 bytes).
+func VarUInt64() Type[uint64] {
+	return newVarintType[uint64]("uint64", 64, false)
+}
+
+// VarUInt encodes an uint as an unsigned LEB128 varint.
+func VarUInt() Type[uint] {
+	return newVarintType[uint]("uint", 8*uintBitSize, false)
+}
+
+// VarInt8 encodes an int8 as a zigzag signed varint (1-2 bytes).
+func VarInt8() Type[int8] {
+	return newVarintType[int8]("int8", 8, true)
+}
+
+// VarInt16 encodes an int16 as a zigzag signed varint (1-3 bytes).
+func VarInt16() Type[int16] {
+	return newVarintType[int16]("int16", 16, true)
+}
+
+// VarInt32 encodes an int32 as a zigzag signed varint (1-5 bytes).
+func VarInt32() Type[int32] {
+	return newVarintType[int32]("int32", 32, true)
+}
+
+// VarInt64 encodes an int64 as a zigzag signed varint (1-10 bytes).
+func VarInt64() Type[int64] {
+	return newVarintType[int64]("int64", 64, true)
+}
+
+// VarInt encodes an int as a zigzag signed varint.
+func VarInt() Type[int] {
+	return newVarintType[int]("int", 8*uintBitSize, true)
+}
+
+// VarUIntHeader is a length header encoded as an unsigned varint: lengths
+// below 128 take a single byte, yet any int length can be represented.
+func VarUIntHeader() IntType {
+	return newVarintType[int]("uint", 8*uintBitSize, false)
+}
+
+// VarIntHeader is a header encoded as a zigzag signed varint.
+func VarIntHeader() IntType {
+	return newVarintType[int]("int", 8*uintBitSize, true)
+}
@@ -0,0 +1,254 @@
+package parco
+
+import (
+	"bytes"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVarUInt64(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    uint64
+		expected []byte
+	}{
+		{"zero", 0, []byte{0}},
+		{"one byte max", 127, []byte{0x7f}},
+		{"two bytes min", 128, []byte{0x80, 0x01}},
+		{"300", 300, []byte{0xac, 0x02}},
+		{"max", math.MaxUint64, []byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x01}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tp := VarUInt64()
+			var buf bytes.Buffer
+
+			require.NoError(t, tp.Compile(tt.value, &buf))
+			assert.Equal(t, tt.expected, buf.Bytes())
+
+			parsed, err := tp.Parse(&buf)
+			require.NoError(t, err)
+			assert.Equal(t, tt.value, parsed)
+		})
+	}
+}
+
+func TestVarInt64_ZigZag(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    int64
+		expected []byte
+	}{
+		{"zero", 0, []byte{0}},
+		{"minus one", -1, []byte{1}},
+		{"one", 1, []byte{2}},
+		{"minus 64", -64, []byte{0x7f}},
+		{"64", 64, []byte{0x80, 0x01}},
+		{"min", math.MinInt64, []byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x01}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tp := VarInt64()
+			var buf bytes.Buffer
+
+			require.NoError(t, tp.Compile(tt.value, &buf))
+			assert.Equal(t, tt.expected, buf.Bytes())
+
+			parsed, err := tp.Parse(&buf)
+			require.NoError(t, err)
+			assert.Equal(t, tt.value, parsed)
+		})
+	}
+}
+
+func TestVarint_NarrowWidthsRoundTrip(t *testing.T) {
+	roundTrip := func(t *testing.T, compile func(*bytes.Buffer) error, parse func(*bytes.Buffer) error) {
+		t.Helper()
+		var buf bytes.Buffer
+		require.NoError(t, compile(&buf))
+		require.NoError(t, parse(&buf))
+		assert.Equal(t, 0, buf.Len())
+	}
+
+	for _, v := range []int8{math.MinInt8, -1, 0, 1, math.MaxInt8} {
+		roundTrip(t,
+			func(b *bytes.Buffer) error { return VarInt8().Compile(v, b) },
+			func(b *bytes.Buffer) error {
+				got, err := VarInt8().Parse(b)
+				assert.Equal(t, v, got)
+				return err
+			},
+		)
+	}
+
+	for _, v := range []uint16{0, 127, 128, math.MaxUint16} {
+		roundTrip(t,
+			func(b *bytes.Buffer) error { return VarUInt16().Compile(v, b) },
+			func(b *bytes.Buffer) error {
+				got, err := VarUInt16().Parse(b)
+				assert.Equal(t, v, got)
+				return err
+			},
+		)
+	}
+
+	for _, v := range []int32{math.MinInt32, -300, 0, 300, math.MaxInt32} {
+		roundTrip(t,
+			func(b *bytes.Buffer) error { return VarInt32().Compile(v, b) },
+			func(b *bytes.Buffer) error {
+				got, err := VarInt32().Parse(b)
+				assert.Equal(t, v, got)
+				return err
+			},
+		)
+	}
+}
+
+func TestVarint_ParseOverflow(t *testing.T) {
+	// 65536 does not fit an uint16 even though it fits in 3 varint bytes.
+	_, err := VarUInt16().Parse(bytes.NewReader([]byte{0x80, 0x80, 0x04}))
+	assert.ErrorIs(t, err, ErrOverflow)
+
+	// Continuation bit set on every byte allowed for the width.
+	_, err = VarUInt32().Parse(bytes.NewReader([]byte{0xff, 0xff, 0xff, 0xff, 0xff, 0x01}))
+	assert.ErrorIs(t, err, ErrOverflow)
+
+	// Eleven bytes can never be a valid 64 bits varint.
+	_, err = VarUInt64().Parse(bytes.NewReader(bytes.Repeat([]byte{0xff}, 11)))
+	assert.ErrorIs(t, err, ErrOverflow)
+}
+
+func TestVarint_ParseNonCanonical(t *testing.T) {
+	// 1 padded with continuation bytes.
+	_, err := VarUInt32().Parse(bytes.NewReader([]byte{0x81, 0x80, 0x00}))
+	assert.ErrorIs(t, err, ErrNonCanonical)
+
+	cursor := NewBufferCursor([]byte{0x80, 0x00}, 0)
+	_, err = VarInt64().Parse(&cursor)
+	assert.ErrorIs(t, err, ErrNonCanonical)
+
+	// Zero is a single zero byte.
+	v, err := VarUInt32().Parse(bytes.NewReader([]byte{0x00}))
+	require.NoError(t, err)
+	assert.Equal(t, uint32(0), v)
+}
+
+func TestVarint_ParseTruncated(t *testing.T) {
+	_, err := VarUInt64().Parse(bytes.NewReader([]byte{0x80}))
+	assert.ErrorIs(t, err, ErrCannotRead)
+
+	cursor := NewBufferCursor([]byte{0x80, 0x80}, 0)
+	_, err = VarUInt64().Parse(&cursor)
+	assert.ErrorIs(t, err, ErrCannotRead)
+}
+
+func TestVarUIntHeader(t *testing.T) {
+	header := VarUIntHeader()
+
+	var buf bytes.Buffer
+	assert.ErrorIs(t, header.Compile(-1, &buf), ErrOverflow)
+
+	require.NoError(t, header.Compile(200, &buf))
+	assert.Equal(t, 2, buf.Len())
+
+	n, err := header.Parse(&buf)
+	require.NoError(t, err)
+	assert.Equal(t, 200, n)
+}
+
+func TestVarUIntHeader_AsCollectionHeader(t *testing.T) {
+	t.Run("varchar", func(t *testing.T) {
+		tp := NewVarcharType(VarUIntHeader())
+		value := string(bytes.Repeat([]byte{'a'}, 300))
+
+		var buf bytes.Buffer
+		require.NoError(t, tp.Compile(value, &buf))
+		assert.Equal(t, 302, buf.Len())
+
+		parsed, err := tp.Parse(&buf)
+		require.NoError(t, err)
+		assert.Equal(t, value, parsed)
+	})
+
+	t.Run("slice", func(t *testing.T) {
+		tp := Slice[uint32](VarUIntHeader(), VarUInt32())
+		value := SliceView[uint32]{1, 200, 70000}
+
+		var buf bytes.Buffer
+		require.NoError(t, tp.Compile(value, &buf))
+		assert.Equal(t, []byte{3, 1, 0xc8, 0x01, 0xf0, 0xa2, 0x04}, buf.Bytes())
+
+		parsed, err := tp.Parse(&buf)
+		require.NoError(t, err)
+		assert.Equal(t, value, parsed.Unwrap())
+	})
+
+	t.Run("map", func(t *testing.T) {
+		tp := MapType[string, int64](VarUIntHeader(), SmallVarchar(), VarInt64())
+		value := map[string]int64{"a": -1, "b": 1 << 40}
+
+		var buf bytes.Buffer
+		require.NoError(t, tp.Compile(value, &buf))
+
+		parsed, err := tp.Parse(&buf)
+		require.NoError(t, err)
+		assert.Equal(t, value, parsed)
+	})
+}
+
+func TestBuilder_Varint(t *testing.T) {
+	type reading struct {
+		Sensor  uint16
+		Counter uint64
+		Delta   int32
+		Offset  int
+		Level   uint8
+		Trend   int8
+		Total   uint
+	}
+
+	builder := Builder[reading](ObjectFactory[reading]()).
+		VarUInt16(
+			func(r *reading) uint16 { return r.Sensor },
+			func(r *reading, v uint16) { r.Sensor = v },
+		).
+		VarUInt64(
+			func(r *reading) uint64 { return r.Counter },
+			func(r *reading, v uint64) { r.Counter = v },
+		).
+		VarInt32(
+			func(r *reading) int32 { return r.Delta },
+			func(r *reading, v int32) { r.Delta = v },
+		).
+		VarInt(
+			func(r *reading) int { return r.Offset },
+			func(r *reading, v int) { r.Offset = v },
+		).
+		VarUInt8(
+			func(r *reading) uint8 { return r.Level },
+			func(r *reading, v uint8) { r.Level = v },
+		).
+		VarInt8(
+			func(r *reading) int8 { return r.Trend },
+			func(r *reading, v int8) { r.Trend = v },
+		).
+		VarUInt(
+			func(r *reading) uint { return r.Total },
+			func(r *reading, v uint) { r.Total = v },
+		)
+
+	value := reading{Sensor: 12, Counter: 1000, Delta: -3, Offset: 7, Level: 200, Trend: -1, Total: 300}
+
+	var buf bytes.Buffer
+	require.NoError(t, builder.Compile(value, &buf))
+	assert.Equal(t, []byte{12, 0xe8, 0x07, 5, 14, 0xc8, 0x01, 1, 0xac, 0x02}, buf.Bytes())
+
+	parsed, err := builder.Parse(&buf)
+	require.NoError(t, err)
+	assert.Equal(t, value, parsed)
+}
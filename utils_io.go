@@ -1,6 +1,7 @@
 package parco
 
 import (
+	"encoding/binary"
 	"errors"
 	"io"
 	"slices"
@@ -38,9 +39,34 @@ func readChunked(r io.Reader, size, chunk int) ([]byte, error) {
 // types can write into scratch space directly instead of paying a pooled
 // buffer round-trip plus a small Write per field. It is fetched from a pool
 // once per Compile call and flushed to the underlying writer at the end.
+// Streamed sequences flush it earlier, see stream.
 type compileWriter struct {
 	w   io.Writer
 	buf []byte
+	// pinned counts the spans of buf still to be rewritten in place, such as
+	// bodies waiting for their length prefix.
+	pinned int
+}
+
+// pin keeps what is written from now on in the buffer, until unpin, for it
+// to be rewritten in place. It returns where it starts.
+func (cw *compileWriter) pin() int {
+	cw.pinned++
+	return len(cw.buf)
+}
+
+func (cw *compileWriter) unpin() {
+	cw.pinned--
+}
+
+// stream writes what was buffered so far to the underlying writer, unless
+// there is none or part of it is pinned, so that streamed sequences go out as
+// they are compiled.
+func (cw *compileWriter) stream() error {
+	if cw.w == nil || cw.pinned > 0 {
+		return nil
+	}
+	return cw.flush()
 }
 
 // scratch grows the buffer by n bytes and returns that space for the caller
@@ -71,6 +97,29 @@ func (cw *compileWriter) flush() error {
 	return nil
 }
 
+// prefixLength writes the byte length of what was written from start on in
+// front of it, with header.
+func (cw *compileWriter) prefixLength(start int, header IntType) error {
+	size := len(cw.buf) - start
+	if headerCapacity(header) < size {
+		return ErrOverflow
+	}
+	if err := header.Compile(size, cw); err != nil {
+		return err
+	}
+	cw.moveFront(start, start+size)
+	return nil
+}
+
+// moveFront moves what was written from mid on in front of what was written
+// from start to mid, e.g. a header compiled after the body it describes.
+func (cw *compileWriter) moveFront(start, mid int) {
+	var scratch [2 * binary.MaxVarintLen64]byte
+	head := append(scratch[:0], cw.buf[mid:]...)
+	copy(cw.buf[start+len(head):], cw.buf[start:mid])
+	copy(cw.buf[start:], head)
+}
+
 var compileWriterPool = sync.Pool{New: func() any { return &compileWriter{} }}
 
 func getCompileWriter(w io.Writer) *compileWriter {
@@ -83,6 +132,7 @@ func getCompileWriter(w io.Writer) *compileWriter {
 func putCompileWriter(cw *compileWriter) {
 	cw.w = nil
 	cw.buf = cw.buf[:0]
+	cw.pinned = 0
 	compileWriterPool.Put(cw)
 }
 
@@ -102,3 +152,152 @@ func (d *discard) Reset() {
 func (d *discard) Size() int {
 	return d.counter
 }
+
+// messageReader counts the bytes read from a message so that parse errors
+// can tell where they happened. Parsers share the one created by the
+// outermost Parse call.
+type messageReader struct {
+	r io.Reader
+	n int
+	// start is the stream offset of the message, -1 if unknown.
+	start int
+	box   [1]byte
+	// budget tracks the DecodeLimits of the message, nil if unlimited. The
+	// outermost reader keeps it in own.
+	budget *decodeBudget
+	own    decodeBudget
+}
+
+// limit subjects the message to limits.
+func (m *messageReader) limit(limits DecodeLimits) {
+	m.own = decodeBudget{limits: limits}
+	m.budget = &m.own
+}
+
+func (m *messageReader) Read(p []byte) (int, error) {
+	if m.budget != nil {
+		if err := m.budget.readable(m.n, len(p)); err != nil {
+			return 0, err
+		}
+	}
+	n, err := m.r.Read(p)
+	m.n += n
+	return n, err
+}
+
+func (m *messageReader) ReadByte() (byte, error) {
+	if m.budget != nil {
+		if err := m.budget.readable(m.n, 1); err != nil {
+			return 0, err
+		}
+	}
+	if br, ok := m.r.(io.ByteReader); ok {
+		b, err := br.ReadByte()
+		if err == nil {
+			m.n++
+		}
+		return b, err
+	}
+	if err := readFull(m.r, m.box[:]); err != nil {
+		return 0, err
+	}
+	m.n++
+	return m.box[0], nil
+}
+
+var messageReaderPool = sync.Pool{New: func() any { return &messageReader{} }}
+
+// getMessageReader wraps r, whose first byte lies offset bytes into the
+// message.
+func getMessageReader(r io.Reader, offset int) *messageReader {
+	//nolint:errcheck // Type assertion is safe - we control pool contents
+	m := messageReaderPool.Get().(*messageReader)
+	m.r = r
+	m.n = offset
+	m.start = -1
+	m.budget = nil
+	if or, ok := r.(OffsetReader); ok {
+		m.start = or.Offset() - offset
+	}
+	return m
+}
+
+// nested wraps r, holding the part of the message read by m from offset on.
+// Used for parts buffered aside, such as extensible bodies.
+func (m *messageReader) nested(r io.Reader, offset int) *messageReader {
+	n := getMessageReader(r, offset)
+	n.start = m.start
+	n.budget = m.budget
+	return n
+}
+
+// locate completes err, raised by the message, with stream offsets.
+func (m *messageReader) locate(err *ParseError) *ParseError {
+	err.MessageStart = m.start
+	err.StreamOffset = -1
+	if m.start >= 0 {
+		err.StreamOffset = m.start + err.Offset
+	}
+	return err
+}
+
+func putMessageReader(m *messageReader) {
+	m.r = nil
+	m.budget = nil
+	messageReaderPool.Put(m)
+}
+
+// readOffset returns how many bytes of the message r has read, or -1 when r
+// does not count them.
+func readOffset(r io.Reader) int {
+	if m, ok := r.(*messageReader); ok {
+		return m.n
+	}
+	return -1
+}
+
+// nextZeroCopy returns the next n bytes of r without copying them, if r
+// reads a buffer in zero-copy mode, possibly through a messageReader. ok
+// reports whether it does; otherwise nothing is read.
+func nextZeroCopy(r io.Reader, n int) (data []byte, ok bool, err error) {
+	switch r := r.(type) {
+	case *messageReader:
+		if r.budget != nil {
+			if err = r.budget.readable(r.n, n); err != nil {
+				return nil, true, err
+			}
+		}
+		if data, ok, err = nextZeroCopy(r.r, n); ok && err == nil {
+			r.n += n
+		}
+		return
+	case *BufferCursor:
+		if !r.zeroCopy {
+			return nil, false, nil
+		}
+		data, err = r.Next(n)
+		return data, true, err
+	}
+	return nil, false, nil
+}
+
+// body reads the next size bytes of the message, such as an extensible
+// body, and returns a cursor over them. They are read into box, a pooled
+// buffer the caller gives back with SinglePool.Put, unless m reads a buffer
+// in zero-copy mode, in which case box is nil and the cursor reads that
+// buffer in zero-copy mode too.
+func (m *messageReader) body(size int) (cursor BufferCursor, box *[]byte, err error) {
+	data, ok, err := nextZeroCopy(m, size)
+	if ok {
+		return NewZeroCopyBufferCursor(data, 0), nil, err
+	}
+
+	box = SinglePool.Get(size)
+	if size <= cap(*box) {
+		data = (*box)[:size]
+		err = readFull(m, data)
+	} else {
+		data, err = readChunked(m, size, cap(*box))
+	}
+	return NewBufferCursor(data, 0), box, err
+}
@@ -0,0 +1,451 @@
+package parco
+
+import (
+	"fmt"
+	"strings"
+)
+
+type (
+	// View reads single fields of messages in place, without parsing the
+	// whole message: the fields before the one asked for are skipped, reading
+	// only the headers of variable length ones. Fields at a fixed offset, those
+	// preceded by fixed width fields only, are reached without reading the
+	// message at all.
+	View struct {
+		root *viewStruct
+	}
+
+	// viewStruct is the layout of a struct of the model.
+	viewStruct struct {
+		schema Schema
+		// offsets are the offsets of the fields from the start of the struct
+		// body, or -1 past the first variable length field.
+		offsets []int
+		// nested are the layouts of the struct fields, and of the struct
+		// options, by field index.
+		nested map[int]*viewStruct
+	}
+)
+
+// NewView returns a view over the messages of the model described by d,
+// which must be made of built-in types. Views read fields in place and do not
+// verify checksums.
+func NewView(d Describer) (*View, error) {
+	s := d.Schema()
+	if s.Kind != KindStruct {
+		return nil, fmt.Errorf("%w: %s is not a struct", ErrInvalidSchema, s.TypeString())
+	}
+	return &View{root: newViewStruct(s)}, nil
+}
+
+func newViewStruct(s Schema) *viewStruct {
+	v := &viewStruct{schema: s, offsets: make([]int, len(s.Fields))}
+
+	offset := 0
+	for i, f := range s.Fields {
+		v.offsets[i] = offset
+		if width := fixedWidth(f); offset >= 0 && width >= 0 && !s.Tagged {
+			offset += width
+		} else {
+			offset = -1
+		}
+
+		inner := f
+		if inner.Kind == KindOption && inner.Elem != nil {
+			inner = *inner.Elem
+		}
+		if inner.Kind == KindStruct {
+			if v.nested == nil {
+				v.nested = make(map[int]*viewStruct)
+			}
+			v.nested[i] = newViewStruct(inner)
+		}
+	}
+
+	return v
+}
+
+// fixedWidth returns the encoded width of values of s, or -1 if it varies.
+func fixedWidth(s Schema) int {
+	switch s.Kind {
+	case KindFixed, KindSkip:
+		return s.ByteLength
+	case KindTime:
+		if !s.Location {
+			return timeByteLength
+		}
+	case KindArray:
+		if s.Elem != nil {
+			if width := fixedWidth(*s.Elem); width >= 0 {
+				return s.Length * width
+			}
+		}
+	case KindStruct:
+		if s.Header != nil || s.Tagged {
+			return -1
+		}
+		width := 0
+		for _, f := range s.Fields {
+			w := fixedWidth(f)
+			if w < 0 {
+				return -1
+			}
+			width += w
+		}
+		if s.Checksum != "" {
+			width += checksumByteLength
+		}
+		return width
+	}
+	return -1
+}
+
+// Bytes returns the encoded bytes of the field at path within data, e.g.
+// Bytes(data, "Note", "SKU"), which are a slice of data. Fields of the model
+// itself are named by their names, and those of nested structs after them.
+// Optional structs are descended into when present.
+//
+// Fields absent from the message, like those missing from messages of older
+// extensible peers or from tagged messages, are reported as ErrFieldNotFound.
+// For fields of tagged models carrying their length, the bytes exclude it.
+func (v *View) Bytes(data []byte, path ...string) ([]byte, error) {
+	start, end, _, err := v.locate(data, path)
+	if err != nil {
+		return nil, err
+	}
+	return data[start:end], nil
+}
+
+// Get decodes the field at path within data with the types Dynamic builds,
+// in the form Explain and ToJSON decode values: structs come out as
+// map[string]any.
+func (v *View) Get(data []byte, path ...string) (Value, error) {
+	start, end, s, err := v.locate(data, path)
+	if err != nil {
+		return nil, err
+	}
+
+	tp, err := dynamicTypes{}.typ(s)
+	if err != nil {
+		return nil, err
+	}
+	cursor := NewBufferCursor(data[:end], start)
+	value, err := tp.Parse(&cursor)
+	if _, ok := err.(*ParseError); err != nil && !ok {
+		return nil, &ParseError{Path: joinPath(v.root.schema.Type, strings.Join(path, ".")), Index: -1, Offset: start, MessageStart: -1, StreamOffset: -1, Err: err}
+	}
+	return value, err
+}
+
+// ViewValue decodes the field at path within data with tp, the type of the
+// field, e.g.
+//
+//	id, err := ViewValue(view, data, UInt32LE(), "UserID")
+func ViewValue[T any](v *View, data []byte, tp Type[T], path ...string) (T, error) {
+	start, end, _, err := v.locate(data, path)
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+
+	cursor := NewBufferCursor(data[:end], start)
+	return tp.Parse(&cursor)
+}
+
+// locate returns the span and schema of the field at path.
+func (v *View) locate(data []byte, path []string) (start, end int, s Schema, err error) {
+	if len(path) == 0 {
+		return 0, 0, Schema{}, fmt.Errorf("%w: empty path", ErrInvalidSchema)
+	}
+
+	cursor := NewBufferCursor(data, 0)
+	d := schemaDecoder{cursor: &cursor}
+	model := v.root
+	i := -1
+
+	for depth, name := range path {
+		// Paths are joined on failure only, keeping the happy path free of
+		// allocations.
+		fpath := func() string {
+			return joinPath(v.root.schema.Type, strings.Join(path[:depth+1], "."))
+		}
+		fail := func(err error) (int, int, Schema, error) {
+			return 0, 0, Schema{}, &ParseError{Path: fpath(), Index: i, Offset: d.cursor.cursor, MessageStart: -1, StreamOffset: -1, Err: err}
+		}
+
+		i = viewField(model.schema, name)
+		if i < 0 {
+			return 0, 0, Schema{}, NewErrFieldNotFoundError(fpath())
+		}
+		s = model.schema.Fields[i]
+
+		found, err := d.seek(model, i)
+		if err != nil {
+			return fail(err)
+		}
+		if !found {
+			return 0, 0, Schema{}, NewErrFieldNotFoundError(fpath())
+		}
+
+		if depth == len(path)-1 {
+			start = d.cursor.cursor
+			if err = d.skip(s); err != nil {
+				return fail(err)
+			}
+			return start, d.cursor.cursor, s, nil
+		}
+
+		if s.Kind == KindOption {
+			some, err := d.flag()
+			if err != nil {
+				return fail(err)
+			}
+			if !some {
+				return 0, 0, Schema{}, NewErrFieldNotFoundError(fpath())
+			}
+		}
+		if model = model.nested[i]; model == nil {
+			return 0, 0, Schema{}, NewErrFieldNotFoundError(joinPath(fpath(), path[depth+1]))
+		}
+	}
+
+	panic("unreachable")
+}
+
+// viewField returns the index of the field name of s, named #index if
+// unnamed.
+func viewField(s Schema, name string) int {
+	for i, f := range s.Fields {
+		if schemaFieldName(f.Name, i) == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// seek moves the cursor, at the start of the struct laid out by v, to the
+// start of its field i, and reports whether the message holds the field. The
+// cursor is bounded to the body of extensible structs, and to the bytes of
+// tagged fields carrying their length.
+func (d *schemaDecoder) seek(v *viewStruct, i int) (bool, error) {
+	s := v.schema
+
+	if s.Header != nil {
+		size, err := d.length(s.Header)
+		if err != nil {
+			return false, err
+		}
+		end := d.cursor.cursor + size
+		if end > len(d.cursor.data) {
+			return false, ErrCannotRead
+		}
+		d.cursor.data = d.cursor.data[:end]
+	}
+
+	if s.Tagged {
+		return d.seekTag(s, i)
+	}
+
+	if offset := v.offsets[i]; offset >= 0 {
+		d.cursor.cursor += offset
+	} else {
+		// Jump over the fixed width prefix, then skip the rest.
+		from := i
+		for from > 0 && v.offsets[from] < 0 {
+			from--
+		}
+		d.cursor.cursor += v.offsets[from]
+		for _, f := range s.Fields[from:i] {
+			if s.Header != nil && d.cursor.cursor >= len(d.cursor.data) {
+				return false, nil
+			}
+			if err := d.skip(f); err != nil {
+				return false, err
+			}
+		}
+	}
+
+	switch {
+	case d.cursor.cursor >= len(d.cursor.data) && s.Header != nil:
+		// Older extensible peers leave trailing fields out.
+		return false, nil
+	case d.cursor.cursor > len(d.cursor.data):
+		return false, ErrCannotRead
+	}
+	return true, nil
+}
+
+// seekTag moves the cursor past the key of field i of the tagged struct s, or
+// to the end of the struct if the message lacks it.
+func (d *schemaDecoder) seekTag(s Schema, i int) (bool, error) {
+	want := s.Fields[i].Tag
+
+	for {
+		key, err := d.uvarint()
+		if err != nil {
+			return false, err
+		}
+		if key == 0 {
+			return false, nil
+		}
+
+		num, wire := int(key>>wireTypeBits), WireType(key&(1<<wireTypeBits-1))
+		if num != want {
+			if err = d.skipWire(wire); err != nil {
+				return false, err
+			}
+			continue
+		}
+
+		if expected := wireTypeOf(s.Fields[i]); wire != expected {
+			return false, fmt.Errorf("%w: want %s, have %s", ErrWireType, expected, wire)
+		}
+		if wire == WireBytes {
+			size, err := decodeWith(d, readWireLength)
+			if err != nil {
+				return false, err
+			}
+			end := d.cursor.cursor + size
+			if end > len(d.cursor.data) {
+				return false, ErrCannotRead
+			}
+			d.cursor.data = d.cursor.data[:end]
+		}
+		return true, nil
+	}
+}
+
+// skip moves the cursor past a value of s, reading only what tells its
+// length.
+func (d *schemaDecoder) skip(s Schema) error {
+	if width := fixedWidth(s); width >= 0 {
+		_, err := d.read(width)
+		return err
+	}
+
+	switch s.Kind {
+	case KindVarint:
+		_, err := d.varint(s)
+		return err
+	case KindVarchar:
+		size, err := d.length(s.Header)
+		if err != nil {
+			return err
+		}
+		_, err = d.read(size)
+		return err
+	case KindSlice, KindArray:
+		size := s.Length
+		if s.Kind == KindSlice {
+			var err error
+			if size, err = d.length(s.Header); err != nil {
+				return err
+			}
+		}
+		if s.Elem == nil {
+			return ErrUnknownType
+		}
+		return d.skipN(size, *s.Elem)
+	case KindChunked:
+		if s.Elem == nil {
+			return ErrUnknownType
+		}
+		for {
+			size, err := d.length(s.Header)
+			if err != nil || size == 0 {
+				return err
+			}
+			if err = d.skipN(size, *s.Elem); err != nil {
+				return err
+			}
+		}
+	case KindMap:
+		size, err := d.length(s.Header)
+		if err != nil {
+			return err
+		}
+		if s.Key == nil || s.Elem == nil {
+			return ErrUnknownType
+		}
+		for range size {
+			if err = d.skip(*s.Key); err != nil {
+				return err
+			}
+			if err = d.skip(*s.Elem); err != nil {
+				return err
+			}
+		}
+		return nil
+	case KindOption:
+		some, err := d.flag()
+		if err != nil || !some || s.Elem == nil {
+			return err
+		}
+		return d.skip(*s.Elem)
+	case KindTime:
+		if _, err := d.read(timeByteLength); err != nil {
+			return err
+		}
+		return d.skip(Describe(Option(SmallVarchar())))
+	case KindStruct:
+		return d.skipStruct(s)
+	}
+
+	return fmt.Errorf("%s: %w", s.Kind, ErrUnknownType)
+}
+
+// skipN skips n values of s, at once if they have a fixed width.
+func (d *schemaDecoder) skipN(n int, s Schema) error {
+	if width := fixedWidth(s); width >= 0 {
+		if n > MaxReasonableVarSize {
+			return ErrOverflow
+		}
+		_, err := d.read(n * width)
+		return err
+	}
+	for range n {
+		if err := d.skip(s); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// skipStruct skips a struct of s, and its checksum if it has one.
+func (d *schemaDecoder) skipStruct(s Schema) error {
+	if err := d.skipStructBody(s); err != nil || s.Checksum == "" {
+		return err
+	}
+	_, err := d.read(checksumByteLength)
+	return err
+}
+
+func (d *schemaDecoder) skipStructBody(s Schema) error {
+	if s.Header != nil {
+		size, err := d.length(s.Header)
+		if err != nil {
+			return err
+		}
+		_, err = d.read(size)
+		return err
+	}
+
+	if !s.Tagged {
+		for _, f := range s.Fields {
+			if err := d.skip(f); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	for {
+		key, err := d.uvarint()
+		if err != nil || key == 0 {
+			return err
+		}
+		if err = d.skipWire(WireType(key & (1<<wireTypeBits - 1))); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,208 @@
+package parco
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestView_Get(t *testing.T) {
+	view, err := NewView(parseOrderBuilder())
+	require.NoError(t, err)
+
+	data := compileParseOrder(t, parseOrder{
+		ID:     42,
+		Items:  []parseItem{{"ab", 7}, {"cd", 8}},
+		Labels: map[string]uint16{"x": 1},
+		Note:   &parseItem{"n", 5},
+	})
+
+	// ID sits at a fixed offset.
+	id, err := ViewValue(view, data, UInt16LE(), "ID")
+	require.NoError(t, err)
+	assert.Equal(t, uint16(42), id)
+
+	labels, err := view.Get(data, "Labels")
+	require.NoError(t, err)
+	assert.Equal(t, map[any]any{"x": uint16(1)}, labels)
+
+	sku, err := view.Get(data, "Note", "SKU")
+	require.NoError(t, err)
+	assert.Equal(t, "n", sku)
+
+	price, err := ViewValue(view, data, UInt32LE(), "Note", "Price")
+	require.NoError(t, err)
+	assert.Equal(t, uint32(5), price)
+
+	raw, err := view.Bytes(data, "Note", "SKU")
+	require.NoError(t, err)
+	assert.Equal(t, []byte{1, 'n'}, raw)
+
+	// Every field spans exactly its bytes.
+	var spans []byte
+	for _, name := range []string{"ID", "Items", "Labels", "Note"} {
+		raw, err := view.Bytes(data, name)
+		require.NoError(t, err)
+		spans = append(spans, raw...)
+	}
+	assert.Equal(t, data, spans)
+}
+
+func TestView_Absent(t *testing.T) {
+	view, err := NewView(parseOrderBuilder())
+	require.NoError(t, err)
+	data := compileParseOrder(t, parseOrder{ID: 1})
+
+	var notFound ErrFieldNotFound
+	_, err = view.Get(data, "Note", "SKU")
+	assert.ErrorAs(t, err, &notFound)
+	_, err = view.Get(data, "Missing")
+	assert.ErrorAs(t, err, &notFound)
+	_, err = view.Get(data, "ID", "SKU")
+	assert.ErrorAs(t, err, &notFound)
+
+	_, err = view.Get(data[:len(data)-1], "Note")
+	assert.ErrorIs(t, err, ErrCannotRead)
+	var parseErr *ParseError
+	require.ErrorAs(t, err, &parseErr)
+	assert.Equal(t, "parseOrder.Note", parseErr.Path)
+}
+
+func TestView_Extensible(t *testing.T) {
+	view, err := NewView(deviceV2Builder(ObjectFactory[deviceV2]()))
+	require.NoError(t, err)
+
+	buf := bytes.NewBuffer(nil)
+	require.NoError(t, deviceV2Builder(ObjectFactory[deviceV2]()).Compile(
+		deviceV2{ID: 1, Name: "a", Firmware: 9, Tags: []string{"x"}}, buf))
+
+	tags, err := view.Get(buf.Bytes(), "Tags")
+	require.NoError(t, err)
+	assert.Equal(t, []any{"x"}, tags)
+
+	// Messages of older peers lack the trailing fields.
+	buf.Reset()
+	require.NoError(t, deviceV1Builder().Compile(deviceV1{ID: 2, Name: "b"}, buf))
+
+	name, err := view.Get(buf.Bytes(), "Name")
+	require.NoError(t, err)
+	assert.Equal(t, "b", name)
+
+	var notFound ErrFieldNotFound
+	_, err = view.Get(buf.Bytes(), "Firmware")
+	assert.ErrorAs(t, err, &notFound)
+}
+
+func TestView_Tagged(t *testing.T) {
+	view, err := NewView(settingsBuilder())
+	require.NoError(t, err)
+
+	buf := bytes.NewBuffer(nil)
+	require.NoError(t, settingsBuilder().Compile(settings{Name: "db", Hosts: []string{"a", "b"}, Seq: -9}, buf))
+
+	seq, err := view.Get(buf.Bytes(), "Seq")
+	require.NoError(t, err)
+	assert.Equal(t, int64(-9), seq)
+
+	hosts, err := view.Get(buf.Bytes(), "Hosts")
+	require.NoError(t, err)
+	assert.Equal(t, []any{"a", "b"}, hosts)
+
+	var notFound ErrFieldNotFound
+	_, err = view.Get(buf.Bytes(), "Limit")
+	assert.ErrorAs(t, err, &notFound)
+}
+
+func TestNewView_NotStruct(t *testing.T) {
+	_, err := NewView(Describe(UInt8()))
+	assert.ErrorIs(t, err, ErrInvalidSchema)
+}
+
+func BenchmarkView_FixedPrefix(b *testing.B) {
+	view, _ := NewView(parseOrderBuilder())
+	buf := bytes.NewBuffer(nil)
+	items := make([]parseItem, 100)
+	_ = parseOrderBuilder().Compile(parseOrder{ID: 42, Items: items}, buf)
+	data := buf.Bytes()
+	tp := UInt16LE()
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_, _ = ViewValue(view, data, tp, "ID")
+	}
+}
+
+func TestView_NestedChecksums(t *testing.T) {
+	type pin struct{ X uint16 }
+	type pinned struct {
+		P    pin
+		Item parseItem
+		Dev  deviceV2
+		B    uint8
+		S    string
+	}
+
+	pins := Builder[pin](ObjectFactory[pin]()).
+		UInt16LE(
+			func(p *pin) uint16 { return p.X },
+			func(p *pin, v uint16) { p.X = v },
+		).Named("X").
+		Checksum(CRC32C())
+	builder := Builder[pinned](ObjectFactory[pinned]()).
+		Struct(StructField[pinned, pin](
+			func(p *pinned) pin { return p.P },
+			func(p *pinned, v pin) { p.P = v },
+			Struct[pin](pins),
+		)).Named("P").
+		Struct(StructField[pinned, parseItem](
+			func(p *pinned) parseItem { return p.Item },
+			func(p *pinned, v parseItem) { p.Item = v },
+			Struct[parseItem](parseItemBuilder().Checksum(CRC32C())),
+		)).Named("Item").
+		Struct(StructField[pinned, deviceV2](
+			func(p *pinned) deviceV2 { return p.Dev },
+			func(p *pinned, v deviceV2) { p.Dev = v },
+			Struct[deviceV2](deviceV2Builder(ObjectFactory[deviceV2]()).Checksum(CRC32C())),
+		)).Named("Dev").
+		UInt8(
+			func(p *pinned) uint8 { return p.B },
+			func(p *pinned, v uint8) { p.B = v },
+		).Named("B").
+		SmallVarchar(
+			func(p *pinned) string { return p.S },
+			func(p *pinned, v string) { p.S = v },
+		).Named("S")
+
+	data, err := builder.Append(nil, pinned{
+		P:    pin{7},
+		Item: parseItem{"ab", 1},
+		Dev:  deviceV2{ID: 1, Name: "a", Firmware: 9, Tags: []string{"x"}},
+		B:    42,
+		S:    "hi",
+	})
+	require.NoError(t, err)
+
+	view, err := NewView(builder)
+	require.NoError(t, err)
+
+	b, err := view.Get(data, "B")
+	require.NoError(t, err)
+	assert.Equal(t, uint8(42), b)
+	s, err := view.Get(data, "S")
+	require.NoError(t, err)
+	assert.Equal(t, "hi", s)
+
+	// Nested structs span their checksums.
+	var spans []byte
+	for _, name := range []string{"P", "Item", "Dev", "B", "S"} {
+		raw, err := view.Bytes(data, name)
+		require.NoError(t, err)
+		spans = append(spans, raw...)
+	}
+	assert.Equal(t, data, spans)
+	raw, err := view.Bytes(data, "P")
+	require.NoError(t, err)
+	assert.Len(t, raw, 2+checksumByteLength)
+}